@@ -0,0 +1,21 @@
+-- Migration 009: Duration-based alert conditions
+
+-- A rule only fires once its condition has held for for_duration seconds
+-- and/or for_checks consecutive checks (0 means a single check is enough)
+ALTER TABLE alert_rules ADD COLUMN for_duration INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE alert_rules ADD COLUMN for_checks INTEGER NOT NULL DEFAULT 0;
+
+-- Node metric history sampled by the monitor service
+CREATE TABLE IF NOT EXISTS node_metrics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    node TEXT NOT NULL,
+    status TEXT NOT NULL,
+    cpu REAL DEFAULT 0,
+    mem INTEGER DEFAULT 0,
+    maxmem INTEGER DEFAULT 0,
+    sampled_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_metrics_server_node ON node_metrics(server_id, node, sampled_at);
@@ -0,0 +1,113 @@
+-- Migration 010: Notification channels, on-call schedules and escalation policies
+
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('email', 'webhook', 'slack')),
+    target TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    rotation_type TEXT NOT NULL DEFAULT 'weekly' CHECK (rotation_type IN ('daily', 'weekly')),
+    handoff_time TEXT NOT NULL DEFAULT '09:00',
+    rotation_start TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS oncall_schedule_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    schedule_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    FOREIGN KEY (schedule_id) REFERENCES oncall_schedules(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(schedule_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS oncall_overrides (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    schedule_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    reason TEXT,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (schedule_id) REFERENCES oncall_schedules(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS escalation_steps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    policy_id INTEGER NOT NULL,
+    step_order INTEGER NOT NULL,
+    delay_minutes INTEGER NOT NULL DEFAULT 0,
+    target_type TEXT NOT NULL CHECK (target_type IN ('user', 'channel', 'schedule')),
+    target_id INTEGER NOT NULL,
+    FOREIGN KEY (policy_id) REFERENCES escalation_policies(id) ON DELETE CASCADE,
+    UNIQUE(policy_id, step_order)
+);
+
+-- Escalation history recorded against each alert
+CREATE TABLE IF NOT EXISTS alert_escalations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_id INTEGER NOT NULL,
+    step_order INTEGER NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id INTEGER NOT NULL,
+    recipient TEXT,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed')),
+    error TEXT,
+    notified_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE
+);
+
+ALTER TABLE alert_rules ADD COLUMN escalation_policy_id INTEGER REFERENCES escalation_policies(id) ON DELETE SET NULL;
+
+-- escalation_step is the last step notified; next_escalation_at is cleared
+-- once the alert is acknowledged, resolved or the policy runs out of steps
+ALTER TABLE alerts ADD COLUMN escalation_policy_id INTEGER REFERENCES escalation_policies(id) ON DELETE SET NULL;
+ALTER TABLE alerts ADD COLUMN escalation_step INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN next_escalation_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_oncall_schedule_members_schedule_id ON oncall_schedule_members(schedule_id);
+CREATE INDEX IF NOT EXISTS idx_oncall_overrides_schedule_id ON oncall_overrides(schedule_id);
+CREATE INDEX IF NOT EXISTS idx_escalation_steps_policy_id ON escalation_steps(policy_id);
+CREATE INDEX IF NOT EXISTS idx_alert_escalations_alert_id ON alert_escalations(alert_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_next_escalation_at ON alerts(next_escalation_at);
+
+CREATE TRIGGER IF NOT EXISTS update_notification_channels_updated_at
+AFTER UPDATE ON notification_channels
+FOR EACH ROW
+BEGIN
+    UPDATE notification_channels SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_oncall_schedules_updated_at
+AFTER UPDATE ON oncall_schedules
+FOR EACH ROW
+BEGIN
+    UPDATE oncall_schedules SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_escalation_policies_updated_at
+AFTER UPDATE ON escalation_policies
+FOR EACH ROW
+BEGIN
+    UPDATE escalation_policies SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,31 @@
+-- Migration 011: Alert silences
+
+-- Matcher columns left NULL match any value. suppress_creation drops matching
+-- alerts entirely instead of only muting their notifications.
+CREATE TABLE IF NOT EXISTS alert_silences (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_rule_id INTEGER,
+    target_id INTEGER,
+    target_name TEXT,
+    type TEXT,
+    severity TEXT CHECK (severity IS NULL OR severity IN ('critical', 'high', 'medium', 'low', 'info')),
+    suppress_creation INTEGER NOT NULL DEFAULT 0,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    created_by TEXT NOT NULL,
+    comment TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (alert_rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+);
+
+ALTER TABLE alerts ADD COLUMN silence_id INTEGER REFERENCES alert_silences(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_alert_silences_ends_at ON alert_silences(ends_at);
+
+CREATE TRIGGER IF NOT EXISTS update_alert_silences_updated_at
+AFTER UPDATE ON alert_silences
+FOR EACH ROW
+BEGIN
+    UPDATE alert_silences SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,9 @@
+-- Migration 012: License alert evaluation
+
+-- due_date is the expiry, renewal or audit date the alert refers to, so a
+-- renewed license alerts again on its new dates.
+ALTER TABLE license_alerts ADD COLUMN due_date DATETIME;
+ALTER TABLE license_alerts ADD COLUMN message TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_license_alerts_unique
+ON license_alerts(license_id, alert_type, threshold_days, due_date);
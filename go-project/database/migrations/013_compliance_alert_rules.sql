@@ -0,0 +1,46 @@
+-- Migration 013: Compliance alert rules
+
+-- SQLite cannot alter CHECK constraints, so alert_rules is rebuilt to accept
+-- the compliance type and its conditions. Foreign keys are disabled while the
+-- old table is dropped so alerts and silences referencing it survive.
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE alert_rules_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('monitor', 'infrastructure', 'compliance')),
+    target_id INTEGER NOT NULL,
+    condition_type TEXT NOT NULL CHECK (condition_type IN (
+        'status_down', 'cpu_high', 'memory_high', 'latency_high', 'uptime_low',
+        'compliance_overdue', 'compliance_non_compliant', 'compliance_audit_overdue',
+        'license_requirement_overdue', 'license_requirement_non_compliant'
+    )),
+    threshold REAL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    for_duration INTEGER NOT NULL DEFAULT 0,
+    for_checks INTEGER NOT NULL DEFAULT 0,
+    escalation_policy_id INTEGER REFERENCES escalation_policies(id) ON DELETE SET NULL
+);
+
+INSERT INTO alert_rules_new (id, name, type, target_id, condition_type, threshold, enabled, created_at, updated_at,
+                             for_duration, for_checks, escalation_policy_id)
+SELECT id, name, type, target_id, condition_type, threshold, enabled, created_at, updated_at,
+       for_duration, for_checks, escalation_policy_id
+FROM alert_rules;
+
+DROP TABLE alert_rules;
+ALTER TABLE alert_rules_new RENAME TO alert_rules;
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_type ON alert_rules(type);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_target_id ON alert_rules(target_id);
+
+CREATE TRIGGER IF NOT EXISTS update_alert_rules_updated_at
+AFTER UPDATE ON alert_rules
+FOR EACH ROW
+BEGIN
+    UPDATE alert_rules SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
+
+PRAGMA foreign_keys = ON;
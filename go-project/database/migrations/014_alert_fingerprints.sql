@@ -0,0 +1,13 @@
+-- Migration 014: Alert fingerprints
+
+-- fingerprint identifies the rule and target an alert is for, so repeated
+-- firings update one open alert instead of creating new ones.
+ALTER TABLE alerts ADD COLUMN fingerprint TEXT;
+ALTER TABLE alerts ADD COLUMN occurrence_count INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE alerts ADD COLUMN last_seen_at DATETIME;
+
+UPDATE alerts
+SET fingerprint = alert_rule_id || ':' || target_id || ':' || COALESCE(target_name, ''),
+    last_seen_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_fingerprint ON alerts(fingerprint, status);
@@ -0,0 +1,9 @@
+-- Migration 016: Per-rule severity and message templates
+
+-- An empty severity keeps the condition's default. severity_tiers is a JSON
+-- array of {"threshold", "severity"} objects; message_template is a Go
+-- text/template replacing the built-in message when set.
+ALTER TABLE alert_rules ADD COLUMN severity TEXT NOT NULL DEFAULT ''
+    CHECK (severity IN ('', 'critical', 'high', 'medium', 'low', 'info'));
+ALTER TABLE alert_rules ADD COLUMN severity_tiers TEXT NOT NULL DEFAULT '';
+ALTER TABLE alert_rules ADD COLUMN message_template TEXT NOT NULL DEFAULT '';
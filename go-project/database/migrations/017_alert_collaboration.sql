@@ -0,0 +1,49 @@
+-- Migration 017: Alert assignment, comments and activity
+
+ALTER TABLE alerts ADD COLUMN assigned_to INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- acknowledged_by keeps the username for display; acknowledged_by_id links
+-- the user who acknowledged the alert.
+ALTER TABLE alerts ADD COLUMN acknowledged_by_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE alerts
+SET acknowledged_by_id = (SELECT id FROM users WHERE users.username = alerts.acknowledged_by)
+WHERE acknowledged_by IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS alert_comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_id INTEGER NOT NULL,
+    user_id INTEGER,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Changes made to an alert. Notifications and comments are read from
+-- alert_escalations and alert_comments rather than copied here.
+CREATE TABLE IF NOT EXISTS alert_activity (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('created', 'acknowledged', 'assigned', 'unassigned', 'resolved')),
+    user_id INTEGER,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (alert_id) REFERENCES alerts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_comments_alert_id ON alert_comments(alert_id);
+CREATE INDEX IF NOT EXISTS idx_alert_activity_alert_id ON alert_activity(alert_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_assigned_to ON alerts(assigned_to);
+
+-- Backfill the history of existing alerts from their timestamps.
+INSERT INTO alert_activity (alert_id, action, created_at)
+SELECT id, 'created', created_at FROM alerts;
+
+INSERT INTO alert_activity (alert_id, action, user_id, detail, created_at)
+SELECT id, 'acknowledged', acknowledged_by_id, COALESCE(acknowledged_by, ''), acknowledged_at
+FROM alerts WHERE acknowledged_at IS NOT NULL;
+
+INSERT INTO alert_activity (alert_id, action, created_at)
+SELECT id, 'resolved', resolved_at FROM alerts WHERE resolved_at IS NOT NULL;
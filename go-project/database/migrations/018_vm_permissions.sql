@@ -0,0 +1,10 @@
+INSERT OR IGNORE INTO permissions (resource, action, role) VALUES
+('vms', 'read', 'admin'),
+('vms', 'manage', 'admin'),
+('vms', 'update', 'admin'),
+('vms', 'delete', 'admin'),
+('vms', 'read', 'manager'),
+('vms', 'manage', 'manager'),
+('vms', 'update', 'manager'),
+('vms', 'read', 'user'),
+('vms', 'read', 'viewer');
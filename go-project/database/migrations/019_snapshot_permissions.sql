@@ -0,0 +1,13 @@
+INSERT OR IGNORE INTO permissions (resource, action, role) VALUES
+('snapshots', 'read', 'admin'),
+('snapshots', 'create', 'admin'),
+('snapshots', 'update', 'admin'),
+('snapshots', 'rollback', 'admin'),
+('snapshots', 'delete', 'admin'),
+('snapshots', 'read', 'manager'),
+('snapshots', 'create', 'manager'),
+('snapshots', 'update', 'manager'),
+('snapshots', 'rollback', 'manager'),
+('snapshots', 'delete', 'manager'),
+('snapshots', 'read', 'user'),
+('snapshots', 'read', 'viewer');
@@ -0,0 +1,9 @@
+INSERT OR IGNORE INTO permissions (resource, action, role) VALUES
+('backups', 'read', 'admin'),
+('backups', 'create', 'admin'),
+('backups', 'restore', 'admin'),
+('backups', 'read', 'manager'),
+('backups', 'create', 'manager'),
+('backups', 'restore', 'manager'),
+('backups', 'read', 'user'),
+('backups', 'read', 'viewer');
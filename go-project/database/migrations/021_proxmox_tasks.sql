@@ -0,0 +1,26 @@
+-- Migration 021: Proxmox task tracking
+
+-- Every Proxmox task started through the API is recorded with the user who
+-- started it, and polled until it finishes.
+CREATE TABLE IF NOT EXISTS proxmox_tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    upid TEXT NOT NULL,
+    node TEXT NOT NULL,
+    type TEXT NOT NULL,
+    vmid INTEGER,
+    description TEXT NOT NULL DEFAULT '',
+    user_id INTEGER,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'ok', 'failed', 'unknown')),
+    exit_status TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE(server_id, upid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_proxmox_tasks_status ON proxmox_tasks(status);
+CREATE INDEX IF NOT EXISTS idx_proxmox_tasks_server_id ON proxmox_tasks(server_id);
+CREATE INDEX IF NOT EXISTS idx_proxmox_tasks_user_id ON proxmox_tasks(user_id);
+CREATE INDEX IF NOT EXISTS idx_proxmox_tasks_started_at ON proxmox_tasks(started_at);
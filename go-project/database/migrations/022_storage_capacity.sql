@@ -0,0 +1,69 @@
+-- Migration 022: Storage capacity tracking
+
+-- Usage of every Proxmox storage, sampled periodically for capacity alerts
+-- and fill forecasts. Shared storages are sampled once with an empty node.
+CREATE TABLE IF NOT EXISTS storage_samples (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    node TEXT NOT NULL DEFAULT '',
+    storage TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    shared INTEGER NOT NULL DEFAULT 0,
+    used INTEGER NOT NULL,
+    total INTEGER NOT NULL,
+    sampled_at DATETIME NOT NULL,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_samples_storage ON storage_samples(server_id, storage, node, sampled_at);
+CREATE INDEX IF NOT EXISTS idx_storage_samples_sampled_at ON storage_samples(sampled_at);
+
+-- alert_rules is rebuilt to accept the storage_high condition.
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE alert_rules_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('monitor', 'infrastructure', 'compliance', 'external')),
+    target_id INTEGER NOT NULL,
+    condition_type TEXT NOT NULL CHECK (condition_type IN (
+        'status_down', 'cpu_high', 'memory_high', 'latency_high', 'uptime_low', 'storage_high',
+        'compliance_overdue', 'compliance_non_compliant', 'compliance_audit_overdue',
+        'license_requirement_overdue', 'license_requirement_non_compliant',
+        'external'
+    )),
+    threshold REAL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    for_duration INTEGER NOT NULL DEFAULT 0,
+    for_checks INTEGER NOT NULL DEFAULT 0,
+    escalation_policy_id INTEGER REFERENCES escalation_policies(id) ON DELETE SET NULL,
+    severity TEXT NOT NULL DEFAULT ''
+        CHECK (severity IN ('', 'critical', 'high', 'medium', 'low', 'info')),
+    severity_tiers TEXT NOT NULL DEFAULT '',
+    message_template TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO alert_rules_new (id, name, type, target_id, condition_type, threshold, enabled, created_at, updated_at,
+                             for_duration, for_checks, escalation_policy_id, severity, severity_tiers, message_template)
+SELECT id, name, type, target_id, condition_type, threshold, enabled, created_at, updated_at,
+       for_duration, for_checks, escalation_policy_id, severity, severity_tiers, message_template
+FROM alert_rules;
+
+DROP TABLE alert_rules;
+ALTER TABLE alert_rules_new RENAME TO alert_rules;
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_type ON alert_rules(type);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_target_id ON alert_rules(target_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_rules_external_name ON alert_rules(name) WHERE type = 'external';
+
+CREATE TRIGGER IF NOT EXISTS update_alert_rules_updated_at
+AFTER UPDATE ON alert_rules
+FOR EACH ROW
+BEGIN
+    UPDATE alert_rules SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,3 @@
+-- A shell on a Proxmox node is root on the hypervisor, so only admins get one.
+INSERT OR IGNORE INTO permissions (resource, action, role) VALUES
+('servers', 'shell', 'admin');
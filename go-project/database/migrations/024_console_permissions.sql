@@ -0,0 +1,4 @@
+-- Guest consoles give no access to the node, so managers get them too.
+INSERT OR IGNORE INTO permissions (resource, action, role) VALUES
+('guests', 'console', 'admin'),
+('guests', 'console', 'manager');
@@ -0,0 +1,28 @@
+-- Migration 025: Terminal session recordings
+
+-- Every node shell and guest console session is recorded to an asciicast v2
+-- file. Sessions keep the names of their server and user so the audit trail
+-- stays complete after either is deleted.
+CREATE TABLE IF NOT EXISTS terminal_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER REFERENCES servers(id) ON DELETE SET NULL,
+    server_name TEXT NOT NULL DEFAULT '',
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    username TEXT NOT NULL DEFAULT '',
+    node TEXT NOT NULL DEFAULT '',
+    guest_type TEXT NOT NULL DEFAULT '',
+    vmid INTEGER NOT NULL DEFAULT 0,
+    client_ip TEXT NOT NULL DEFAULT '',
+    recording TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    duration REAL NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_sessions_server_id ON terminal_sessions(server_id);
+CREATE INDEX IF NOT EXISTS idx_terminal_sessions_user_id ON terminal_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_terminal_sessions_started_at ON terminal_sessions(started_at);
+
+INSERT OR IGNORE INTO permissions (resource, action, role) VALUES
+('terminal_sessions', 'read', 'admin');
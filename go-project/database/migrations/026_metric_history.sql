@@ -0,0 +1,31 @@
+-- Migration 026: Node and guest metric history
+
+-- Points of the Proxmox RRD data of every node and guest, copied locally
+-- because Proxmox keeps only about 70 points per timeframe. A node's rows
+-- have its name and an empty guest_type; a guest's rows have an empty node,
+-- so its history stays in one series when it migrates. Points are averages
+-- over the timeframe's step: a minute for hour, 30 minutes for day and 3
+-- hours for week.
+CREATE TABLE IF NOT EXISTS metric_samples (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    node TEXT NOT NULL DEFAULT '',
+    guest_type TEXT NOT NULL DEFAULT '',
+    vmid INTEGER NOT NULL DEFAULT 0,
+    timeframe TEXT NOT NULL CHECK (timeframe IN ('hour', 'day', 'week')),
+    cpu REAL,
+    maxcpu REAL,
+    mem REAL,
+    maxmem REAL,
+    netin REAL,
+    netout REAL,
+    diskread REAL,
+    diskwrite REAL,
+    iowait REAL,
+    loadavg REAL,
+    sampled_at DATETIME NOT NULL,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metric_samples_point ON metric_samples(server_id, guest_type, vmid, node, timeframe, sampled_at);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_retention ON metric_samples(timeframe, sampled_at);
@@ -0,0 +1,69 @@
+-- Migration 027: Infrastructure inventory
+
+-- The nodes and guests of every Proxmox server, synced periodically. Rows
+-- that disappear from Proxmox are kept with removed_at or deleted_at set, so
+-- a node name or VMID that comes back later gets a new row.
+CREATE TABLE IF NOT EXISTS inventory_nodes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    node TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    cpu REAL NOT NULL DEFAULT 0,
+    maxcpu INTEGER NOT NULL DEFAULT 0,
+    mem INTEGER NOT NULL DEFAULT 0,
+    maxmem INTEGER NOT NULL DEFAULT 0,
+    disk INTEGER NOT NULL DEFAULT 0,
+    maxdisk INTEGER NOT NULL DEFAULT 0,
+    uptime INTEGER NOT NULL DEFAULT 0,
+    config_hash TEXT NOT NULL DEFAULT '',
+    first_seen DATETIME NOT NULL,
+    last_seen DATETIME NOT NULL,
+    removed_at DATETIME,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inventory_nodes_current ON inventory_nodes(server_id, node) WHERE removed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS inventory_guests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    vmid INTEGER NOT NULL,
+    guest_type TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    node TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    template INTEGER NOT NULL DEFAULT 0,
+    maxcpu INTEGER NOT NULL DEFAULT 0,
+    maxmem INTEGER NOT NULL DEFAULT 0,
+    maxdisk INTEGER NOT NULL DEFAULT 0,
+    pool TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '',
+    config_hash TEXT NOT NULL DEFAULT '',
+    first_seen DATETIME NOT NULL,
+    last_seen DATETIME NOT NULL,
+    deleted_at DATETIME,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inventory_guests_current ON inventory_guests(server_id, vmid) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_inventory_guests_node ON inventory_guests(server_id, node);
+
+-- Changes noticed between syncs. old_value and new_value describe what
+-- changed, e.g. the previous and current node of a migrated guest.
+CREATE TABLE IF NOT EXISTS inventory_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id INTEGER NOT NULL,
+    event TEXT NOT NULL,
+    node TEXT NOT NULL DEFAULT '',
+    guest_type TEXT NOT NULL DEFAULT '',
+    vmid INTEGER NOT NULL DEFAULT 0,
+    name TEXT NOT NULL DEFAULT '',
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    occurred_at DATETIME NOT NULL,
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_events_server ON inventory_events(server_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_inventory_events_vmid ON inventory_events(vmid);
+CREATE INDEX IF NOT EXISTS idx_inventory_events_occurred_at ON inventory_events(occurred_at);
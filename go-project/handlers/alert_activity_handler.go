@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"go-project/middleware"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadAlert fetches the fields of an alert used in notifications.
+func loadAlert(id int64) (models.Alert, error) {
+	var a models.Alert
+	var assignedTo sql.NullInt64
+	err := database.DB.QueryRow(`
+		SELECT id, alert_rule_id, type, severity, message, target_id, COALESCE(target_name, ''), status,
+		       occurrence_count, assigned_to, created_at
+		FROM alerts WHERE id = ?
+	`, id).Scan(&a.ID, &a.AlertRuleID, &a.Type, &a.Severity, &a.Message, &a.TargetID, &a.TargetName, &a.Status,
+		&a.OccurrenceCount, &assignedTo, &a.CreatedAt)
+	if assignedTo.Valid {
+		a.AssignedTo = &assignedTo.Int64
+	}
+	return a, err
+}
+
+// AssignAlert assigns an alert to a user, or unassigns it when user_id is
+// null or 0. The new assignee is notified unless they assigned it to
+// themselves.
+func AssignAlert(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	var input struct {
+		UserID *int64 `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actor, err := services.GetUserByID(middleware.GetUserID(c))
+	if err != nil || actor == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	alert, err := loadAlert(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert"})
+		return
+	}
+
+	var assignee *models.User
+	if input.UserID != nil && *input.UserID != 0 {
+		assignee, err = services.GetUserByID(*input.UserID)
+		if err != nil || assignee == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+			return
+		}
+		if assignee.Status != models.UserStatusActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not active"})
+			return
+		}
+	}
+
+	var assignedTo *int64
+	if assignee != nil {
+		assignedTo = &assignee.ID
+	}
+	if (alert.AssignedTo == nil && assignedTo == nil) ||
+		(alert.AssignedTo != nil && assignedTo != nil && *alert.AssignedTo == *assignedTo) {
+		c.JSON(http.StatusOK, gin.H{"message": "Alert assignment unchanged"})
+		return
+	}
+
+	_, err = database.DB.Exec("UPDATE alerts SET assigned_to = ? WHERE id = ?", assignedTo, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign alert"})
+		return
+	}
+
+	if assignee == nil {
+		services.RecordAlertActivity(id, models.AlertActivityUnassigned, &actor.ID, "")
+		services.PublishEvent(services.EventAlertAssigned, services.AlertAssignment{ID: id, By: actor.Username})
+		c.JSON(http.StatusOK, gin.H{"message": "Alert unassigned successfully"})
+		return
+	}
+
+	services.RecordAlertActivity(id, models.AlertActivityAssigned, &actor.ID, assignee.Username)
+	services.PublishEvent(services.EventAlertAssigned, services.AlertAssignment{
+		ID: id, AssignedTo: &assignee.ID, AssignedToUsername: assignee.Username, By: actor.Username,
+	})
+	if assignee.ID != actor.ID {
+		services.NotifyAlertAssignee(alert, assignee.ID, actor.Username)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert assigned successfully"})
+}
+
+func GetAlertComments(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT ac.id, ac.alert_id, ac.user_id, COALESCE(u.username, ''), ac.body, ac.created_at
+		FROM alert_comments ac
+		LEFT JOIN users u ON ac.user_id = u.id
+		WHERE ac.alert_id = ?
+		ORDER BY ac.created_at ASC, ac.id ASC
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+	defer rows.Close()
+
+	comments := []models.AlertComment{}
+	for rows.Next() {
+		var comment models.AlertComment
+		var userID sql.NullInt64
+		if err := rows.Scan(&comment.ID, &comment.AlertID, &userID, &comment.Username, &comment.Body, &comment.CreatedAt); err != nil {
+			continue
+		}
+		if userID.Valid {
+			comment.UserID = &userID.Int64
+		}
+		comments = append(comments, comment)
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func CreateAlertComment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	var input struct {
+		Body string `json:"body" binding:"required,max=10000"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := services.GetUserByID(middleware.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if _, err := loadAlert(id); err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert"})
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		INSERT INTO alert_comments (alert_id, user_id, body, created_at) VALUES (?, ?, ?, ?)
+	`, id, user.ID, input.Body, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+
+	commentID, _ := result.LastInsertId()
+	services.PublishEvent(services.EventAlertCommented, models.AlertComment{
+		ID: commentID, AlertID: id, UserID: &user.ID, Username: user.Username, Body: input.Body, CreatedAt: now,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"id": commentID, "message": "Comment created successfully"})
+}
+
+// GetAlertActivity returns an alert's full history, oldest first: state
+// changes and assignments, notifications sent and comments.
+func GetAlertActivity(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	if _, err := loadAlert(id); err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert"})
+		return
+	}
+
+	activity := []models.AlertActivity{}
+
+	entries, err := queryAlertActivity(`
+		SELECT aa.action, aa.user_id, COALESCE(u.username, ''), aa.detail, aa.created_at
+		FROM alert_activity aa
+		LEFT JOIN users u ON aa.user_id = u.id
+		WHERE aa.alert_id = ?
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert activity"})
+		return
+	}
+	activity = append(activity, entries...)
+
+	entries, err = queryAlertActivity(`
+		SELECT 'commented', ac.user_id, COALESCE(u.username, ''), ac.body, ac.created_at
+		FROM alert_comments ac
+		LEFT JOIN users u ON ac.user_id = u.id
+		WHERE ac.alert_id = ?
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert activity"})
+		return
+	}
+	activity = append(activity, entries...)
+
+	rows, err := database.DB.Query(`
+		SELECT step_order, target_type, COALESCE(recipient, ''), status, COALESCE(error, ''), notified_at
+		FROM alert_escalations
+		WHERE alert_id = ?
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert activity"})
+		return
+	}
+	for rows.Next() {
+		var step int
+		var targetType, recipient, status, errMsg string
+		var notifiedAt time.Time
+		if err := rows.Scan(&step, &targetType, &recipient, &status, &errMsg, &notifiedAt); err != nil {
+			continue
+		}
+
+		detail := fmt.Sprintf("Escalation step %d: %s %s", step, targetType, status)
+		if step == 0 {
+			detail = fmt.Sprintf("Direct notification: %s", status)
+		}
+		if recipient != "" {
+			detail += " to " + recipient
+		}
+		if errMsg != "" {
+			detail += " (" + errMsg + ")"
+		}
+
+		activity = append(activity, models.AlertActivity{
+			Action:    models.AlertActivityNotified,
+			Detail:    detail,
+			CreatedAt: notifiedAt,
+		})
+	}
+	rows.Close()
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].CreatedAt.Before(activity[j].CreatedAt)
+	})
+
+	c.JSON(http.StatusOK, activity)
+}
+
+func queryAlertActivity(query string, alertID int64) ([]models.AlertActivity, error) {
+	rows, err := database.DB.Query(query, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AlertActivity
+	for rows.Next() {
+		var entry models.AlertActivity
+		var userID sql.NullInt64
+		if err := rows.Scan(&entry.Action, &userID, &entry.Username, &entry.Detail, &entry.CreatedAt); err != nil {
+			continue
+		}
+		if userID.Valid {
+			entry.UserID = &userID.Int64
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
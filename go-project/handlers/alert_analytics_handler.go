@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertResponseStats summarises response times for a set of alerts. Mean
+// times are in seconds and null when no alert was acknowledged or resolved.
+type AlertResponseStats struct {
+	Key          string   `json:"key"`
+	Label        string   `json:"label"`
+	Count        int      `json:"count"`
+	Acknowledged int      `json:"acknowledged"`
+	Resolved     int      `json:"resolved"`
+	MTTASeconds  *float64 `json:"mtta_seconds"`
+	MTTRSeconds  *float64 `json:"mttr_seconds"`
+
+	ackTotal, resolveTotal time.Duration
+}
+
+type NoisyAlertRule struct {
+	RuleID      int64  `json:"rule_id"`
+	RuleName    string `json:"rule_name"`
+	Alerts      int    `json:"alerts"`
+	Occurrences int    `json:"occurrences"`
+}
+
+type AlertVolume struct {
+	Period     string         `json:"period"`
+	Count      int            `json:"count"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+type AlertAnalytics struct {
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	GroupBy       string               `json:"group_by"`
+	Period        string               `json:"period"`
+	Summary       AlertResponseStats   `json:"summary"`
+	Groups        []AlertResponseStats `json:"groups"`
+	NoisiestRules []NoisyAlertRule     `json:"noisiest_rules"`
+	Volume        []AlertVolume        `json:"volume"`
+}
+
+func (s *AlertResponseStats) add(createdAt time.Time, acknowledgedAt, resolvedAt sql.NullTime) {
+	s.Count++
+	if acknowledgedAt.Valid {
+		s.Acknowledged++
+		s.ackTotal += acknowledgedAt.Time.Sub(createdAt)
+	}
+	if resolvedAt.Valid {
+		s.Resolved++
+		s.resolveTotal += resolvedAt.Time.Sub(createdAt)
+	}
+}
+
+func (s *AlertResponseStats) finish() {
+	if s.Acknowledged > 0 {
+		mtta := s.ackTotal.Seconds() / float64(s.Acknowledged)
+		s.MTTASeconds = &mtta
+	}
+	if s.Resolved > 0 {
+		mttr := s.resolveTotal.Seconds() / float64(s.Resolved)
+		s.MTTRSeconds = &mttr
+	}
+}
+
+// GetAlertAnalytics reports response times and alert volume for alerts
+// created between from and to (default: the last 30 days).
+func GetAlertAnalytics(c *gin.Context) {
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = parseAnalyticsTime(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = parseAnalyticsTime(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", "rule")
+	if groupBy != "rule" && groupBy != "severity" && groupBy != "target" && groupBy != "period" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be rule, severity, target or period"})
+		return
+	}
+
+	period := c.DefaultQuery("period", "day")
+	if period != "day" && period != "week" && period != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be day, week or month"})
+		return
+	}
+
+	top, err := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if err != nil || top < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top must be a positive number"})
+		return
+	}
+
+	// created_at is stored in more than one format, so the range is filtered
+	// after scanning rather than in SQL.
+	rows, err := database.DB.Query(`
+		SELECT a.alert_rule_id, COALESCE(r.name, ''), a.severity, a.target_id, COALESCE(a.target_name, ''),
+		       a.occurrence_count, a.created_at, a.acknowledged_at, a.resolved_at
+		FROM alerts a
+		LEFT JOIN alert_rules r ON a.alert_rule_id = r.id
+		WHERE a.created_at >= date(?, '-1 day')
+	`, from.Format("2006-01-02"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
+		return
+	}
+	defer rows.Close()
+
+	report := AlertAnalytics{From: from, To: to, GroupBy: groupBy, Period: period}
+	groups := map[string]*AlertResponseStats{}
+	noisy := map[int64]*NoisyAlertRule{}
+	volume := map[string]*AlertVolume{}
+
+	for rows.Next() {
+		var ruleID, targetID int64
+		var ruleName, severity, targetName string
+		var occurrences int
+		var createdAt time.Time
+		var acknowledgedAt, resolvedAt sql.NullTime
+
+		if err := rows.Scan(&ruleID, &ruleName, &severity, &targetID, &targetName,
+			&occurrences, &createdAt, &acknowledgedAt, &resolvedAt); err != nil {
+			continue
+		}
+		if createdAt.Before(from) || !createdAt.Before(to) {
+			continue
+		}
+
+		bucket := periodStart(createdAt, period).Format("2006-01-02")
+
+		var key, label string
+		switch groupBy {
+		case "rule":
+			key, label = strconv.FormatInt(ruleID, 10), ruleName
+		case "severity":
+			key, label = severity, severity
+		case "target":
+			key = fmt.Sprintf("%d:%s", targetID, targetName)
+			label = targetName
+			if label == "" {
+				label = strconv.FormatInt(targetID, 10)
+			}
+		case "period":
+			key, label = bucket, bucket
+		}
+
+		g, ok := groups[key]
+		if !ok {
+			g = &AlertResponseStats{Key: key, Label: label}
+			groups[key] = g
+		}
+		g.add(createdAt, acknowledgedAt, resolvedAt)
+		report.Summary.add(createdAt, acknowledgedAt, resolvedAt)
+
+		n, ok := noisy[ruleID]
+		if !ok {
+			n = &NoisyAlertRule{RuleID: ruleID, RuleName: ruleName}
+			noisy[ruleID] = n
+		}
+		n.Alerts++
+		n.Occurrences += occurrences
+
+		v, ok := volume[bucket]
+		if !ok {
+			v = &AlertVolume{Period: bucket, BySeverity: map[string]int{}}
+			volume[bucket] = v
+		}
+		v.Count++
+		v.BySeverity[severity]++
+	}
+
+	report.Summary.Key, report.Summary.Label = "all", "All alerts"
+	report.Summary.finish()
+
+	report.Groups = []AlertResponseStats{}
+	for _, g := range groups {
+		g.finish()
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if groupBy == "period" {
+			return report.Groups[i].Key < report.Groups[j].Key
+		}
+		if report.Groups[i].Count != report.Groups[j].Count {
+			return report.Groups[i].Count > report.Groups[j].Count
+		}
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+
+	report.NoisiestRules = []NoisyAlertRule{}
+	for _, n := range noisy {
+		report.NoisiestRules = append(report.NoisiestRules, *n)
+	}
+	sort.Slice(report.NoisiestRules, func(i, j int) bool {
+		a, b := report.NoisiestRules[i], report.NoisiestRules[j]
+		if a.Alerts != b.Alerts {
+			return a.Alerts > b.Alerts
+		}
+		if a.Occurrences != b.Occurrences {
+			return a.Occurrences > b.Occurrences
+		}
+		return a.RuleID < b.RuleID
+	})
+	if len(report.NoisiestRules) > top {
+		report.NoisiestRules = report.NoisiestRules[:top]
+	}
+
+	// Every period in the range is listed, including ones without alerts, so
+	// the series can be charted directly.
+	report.Volume = []AlertVolume{}
+	for p := periodStart(from, period); p.Before(to); p = nextPeriod(p, period) {
+		bucket := p.Format("2006-01-02")
+		if v, ok := volume[bucket]; ok {
+			report.Volume = append(report.Volume, *v)
+		} else {
+			report.Volume = append(report.Volume, AlertVolume{Period: bucket, BySeverity: map[string]int{}})
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func parseAnalyticsTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// periodStart returns the UTC start of the day, ISO week (Monday) or month
+// containing t.
+func periodStart(t time.Time, period string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+func nextPeriod(t time.Time, period string) time.Time {
+	switch period {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
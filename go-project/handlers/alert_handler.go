@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
 	"go-project/database"
+	"go-project/middleware"
 	"go-project/models"
+	"go-project/services"
 	"log"
 	"net/http"
 	"strconv"
@@ -14,7 +17,8 @@ import (
 
 func GetAlertRules(c *gin.Context) {
 	rows, err := database.DB.Query(`
-		SELECT id, name, type, target_id, condition_type, threshold, enabled, created_at, updated_at
+		SELECT id, name, type, target_id, condition_type, threshold, for_duration, for_checks, escalation_policy_id,
+		       enabled, severity, severity_tiers, message_template, created_at, updated_at
 		FROM alert_rules
 		ORDER BY created_at DESC
 	`)
@@ -27,40 +31,96 @@ func GetAlertRules(c *gin.Context) {
 	var rules []models.AlertRule
 	for rows.Next() {
 		var r models.AlertRule
-		err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.TargetID, &r.ConditionType, &r.Threshold, &r.Enabled, &r.CreatedAt, &r.UpdatedAt)
+		var policyID sql.NullInt64
+		var tiers string
+		err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.TargetID, &r.ConditionType, &r.Threshold, &r.ForDuration, &r.ForChecks,
+			&policyID, &r.Enabled, &r.Severity, &tiers, &r.MessageTemplate, &r.CreatedAt, &r.UpdatedAt)
 		if err != nil {
 			continue
 		}
+		if policyID.Valid {
+			r.EscalationPolicyID = &policyID.Int64
+		}
+		r.SeverityTiers = services.DecodeSeverityTiers(tiers)
 		rules = append(rules, r)
 	}
 
 	c.JSON(http.StatusOK, rules)
 }
 
-func CreateAlertRule(c *gin.Context) {
-	var input struct {
-		Name          string  `json:"name" binding:"required"`
-		Type          string  `json:"type" binding:"required"`
-		TargetID      int64   `json:"target_id" binding:"required"`
-		ConditionType string  `json:"condition_type" binding:"required"`
-		Threshold     float64 `json:"threshold"`
+type alertRuleInput struct {
+	Name               string                `json:"name" binding:"required"`
+	Type               string                `json:"type" binding:"required"`
+	TargetID           int64                 `json:"target_id" binding:"min=0"` // 0 covers all items for compliance rules
+	ConditionType      string                `json:"condition_type" binding:"required"`
+	Threshold          float64               `json:"threshold"`
+	ForDuration        int                   `json:"for_duration" binding:"min=0"`
+	ForChecks          int                   `json:"for_checks" binding:"min=0"`
+	EscalationPolicyID *int64                `json:"escalation_policy_id"`
+	Enabled            *bool                 `json:"enabled"`
+	Severity           string                `json:"severity"`
+	SeverityTiers      []models.SeverityTier `json:"severity_tiers"`
+	MessageTemplate    string                `json:"message_template"`
+}
+
+// validate returns an error message for inputs the binding tags cannot catch.
+func (input alertRuleInput) validate() string {
+	needsTarget := input.Type == string(models.AlertRuleTypeMonitor) || input.Type == string(models.AlertRuleTypeInfrastructure)
+	if input.TargetID == 0 && needsTarget {
+		return "target_id is required"
+	}
+	if err := services.ValidateRuleFormat(input.rule()); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (input alertRuleInput) rule() models.AlertRule {
+	enabled := true
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	return models.AlertRule{
+		Name:               input.Name,
+		Type:               models.AlertRuleType(input.Type),
+		TargetID:           input.TargetID,
+		ConditionType:      models.AlertConditionType(input.ConditionType),
+		Threshold:          input.Threshold,
+		ForDuration:        input.ForDuration,
+		ForChecks:          input.ForChecks,
+		EscalationPolicyID: input.EscalationPolicyID,
+		Enabled:            enabled,
+		Severity:           models.AlertSeverity(input.Severity),
+		SeverityTiers:      input.SeverityTiers,
+		MessageTemplate:    input.MessageTemplate,
 	}
+}
 
+func CreateAlertRule(c *gin.Context) {
+	var input alertRuleInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		log.Printf("JSON bind error: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	enabled := 1
+	if msg := input.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
 
-	log.Printf("Creating alert rule: name=%s, type=%s, target_id=%d, condition=%s, threshold=%f",
-		input.Name, input.Type, input.TargetID, input.ConditionType, input.Threshold)
+	rule := input.rule()
+
+	log.Printf("Creating alert rule: name=%s, type=%s, target_id=%d, condition=%s, threshold=%f, for_duration=%d, for_checks=%d",
+		rule.Name, rule.Type, rule.TargetID, rule.ConditionType, rule.Threshold, rule.ForDuration, rule.ForChecks)
 
 	result, err := database.DB.Exec(`
-		INSERT INTO alert_rules (name, type, target_id, condition_type, threshold, enabled)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, input.Name, input.Type, input.TargetID, input.ConditionType, input.Threshold, enabled)
+		INSERT INTO alert_rules (name, type, target_id, condition_type, threshold, for_duration, for_checks, escalation_policy_id, enabled,
+		                         severity, severity_tiers, message_template)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, string(rule.Type), rule.TargetID, string(rule.ConditionType), rule.Threshold, rule.ForDuration, rule.ForChecks,
+		rule.EscalationPolicyID, rule.Enabled, string(rule.Severity), services.EncodeSeverityTiers(rule.SeverityTiers), rule.MessageTemplate)
 
 	if err != nil {
 		log.Printf("Database error: %v", err)
@@ -72,6 +132,152 @@ func CreateAlertRule(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Alert rule created successfully"})
 }
 
+func UpdateAlertRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert rule ID"})
+		return
+	}
+
+	var input alertRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := input.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	rule := input.rule()
+
+	result, err := database.DB.Exec(`
+		UPDATE alert_rules
+		SET name = ?, type = ?, target_id = ?, condition_type = ?, threshold = ?, for_duration = ?, for_checks = ?,
+		    escalation_policy_id = ?, enabled = COALESCE(?, enabled), severity = ?, severity_tiers = ?, message_template = ?
+		WHERE id = ?
+	`, rule.Name, string(rule.Type), rule.TargetID, string(rule.ConditionType), rule.Threshold, rule.ForDuration, rule.ForChecks,
+		rule.EscalationPolicyID, input.Enabled, string(rule.Severity), services.EncodeSeverityTiers(rule.SeverityTiers),
+		rule.MessageTemplate, id)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert rule"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule updated successfully"})
+}
+
+func EnableAlertRule(c *gin.Context) {
+	setAlertRuleEnabled(c, true)
+}
+
+func DisableAlertRule(c *gin.Context) {
+	setAlertRuleEnabled(c, false)
+}
+
+func setAlertRuleEnabled(c *gin.Context, enabled bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert rule ID"})
+		return
+	}
+
+	result, err := database.DB.Exec("UPDATE alert_rules SET enabled = ? WHERE id = ?", enabled, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert rule"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+
+	if enabled {
+		c.JSON(http.StatusOK, gin.H{"message": "Alert rule enabled"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "Alert rule disabled"})
+	}
+}
+
+// DryRunAlertRule evaluates an unsaved rule body against current data.
+func DryRunAlertRule(c *gin.Context) {
+	var input alertRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := input.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	dryRunAlertRule(c, input.rule())
+}
+
+// DryRunSavedAlertRule evaluates a saved rule against current data, whether
+// or not it is enabled.
+func DryRunSavedAlertRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert rule ID"})
+		return
+	}
+
+	var rule models.AlertRule
+	var policyID sql.NullInt64
+	var tiers string
+	err = database.DB.QueryRow(`
+		SELECT id, name, type, target_id, condition_type, threshold, for_duration, for_checks, escalation_policy_id,
+		       enabled, severity, severity_tiers, message_template, created_at, updated_at
+		FROM alert_rules WHERE id = ?
+	`, id).Scan(&rule.ID, &rule.Name, &rule.Type, &rule.TargetID, &rule.ConditionType, &rule.Threshold, &rule.ForDuration,
+		&rule.ForChecks, &policyID, &rule.Enabled, &rule.Severity, &tiers, &rule.MessageTemplate, &rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert rule"})
+		return
+	}
+	if policyID.Valid {
+		rule.EscalationPolicyID = &policyID.Int64
+	}
+	rule.SeverityTiers = services.DecodeSeverityTiers(tiers)
+
+	dryRunAlertRule(c, rule)
+}
+
+func dryRunAlertRule(c *gin.Context, rule models.AlertRule) {
+	evaluations, err := services.EvaluateRule(rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to evaluate rule: " + err.Error()})
+		return
+	}
+
+	wouldFire := false
+	for _, e := range evaluations {
+		if e.Fire {
+			wouldFire = true
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rule":        rule,
+		"would_fire":  wouldFire,
+		"evaluations": evaluations,
+	})
+}
+
 func DeleteAlertRule(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -94,7 +300,10 @@ func GetAlerts(c *gin.Context) {
 
 	query := `
 		SELECT id, alert_rule_id, type, severity, message, current_value, target_id, target_name, 
-		       status, acknowledged_at, acknowledged_by, resolved_at, created_at
+		       status, acknowledged_at, acknowledged_by, resolved_at,
+		       escalation_policy_id, escalation_step, next_escalation_at, silence_id,
+		       COALESCE(fingerprint, ''), occurrence_count, last_seen_at, labels, acknowledged_by_id, assigned_to,
+		       COALESCE((SELECT username FROM users WHERE users.id = alerts.assigned_to), ''), created_at
 		FROM alerts
 		WHERE 1=1
 	`
@@ -111,6 +320,24 @@ func GetAlerts(c *gin.Context) {
 		args = append(args, severity)
 	}
 
+	// assigned_to is a user ID, "me" or "none".
+	switch assignedTo := c.Query("assigned_to"); assignedTo {
+	case "":
+	case "none":
+		query += " AND assigned_to IS NULL"
+	case "me":
+		query += " AND assigned_to = ?"
+		args = append(args, middleware.GetUserID(c))
+	default:
+		userID, err := strconv.ParseInt(assignedTo, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "assigned_to must be a user ID, me or none"})
+			return
+		}
+		query += " AND assigned_to = ?"
+		args = append(args, userID)
+	}
+
 	query += " ORDER BY created_at DESC LIMIT 100"
 
 	rows, err := database.DB.Query(query, args...)
@@ -123,11 +350,16 @@ func GetAlerts(c *gin.Context) {
 	var alerts []models.Alert
 	for rows.Next() {
 		var a models.Alert
-		var acknowledgedAt, resolvedAt sql.NullTime
+		var acknowledgedAt, resolvedAt, nextEscalationAt, lastSeenAt sql.NullTime
 		var acknowledgedBy sql.NullString
+		var policyID, silenceID, acknowledgedByID, assignedTo sql.NullInt64
+		var labels sql.NullString
 
 		err := rows.Scan(&a.ID, &a.AlertRuleID, &a.Type, &a.Severity, &a.Message, &a.CurrentValue, &a.TargetID, &a.TargetName,
-			&a.Status, &acknowledgedAt, &acknowledgedBy, &resolvedAt, &a.CreatedAt)
+			&a.Status, &acknowledgedAt, &acknowledgedBy, &resolvedAt,
+			&policyID, &a.EscalationStep, &nextEscalationAt, &silenceID,
+			&a.Fingerprint, &a.OccurrenceCount, &lastSeenAt, &labels, &acknowledgedByID, &assignedTo,
+			&a.AssignedToUsername, &a.CreatedAt)
 		if err != nil {
 			continue
 		}
@@ -138,9 +370,30 @@ func GetAlerts(c *gin.Context) {
 		if acknowledgedBy.Valid {
 			a.AcknowledgedBy = acknowledgedBy.String
 		}
+		if acknowledgedByID.Valid {
+			a.AcknowledgedByID = &acknowledgedByID.Int64
+		}
+		if assignedTo.Valid {
+			a.AssignedTo = &assignedTo.Int64
+		}
 		if resolvedAt.Valid {
 			a.ResolvedAt = &resolvedAt.Time
 		}
+		if policyID.Valid {
+			a.EscalationPolicyID = &policyID.Int64
+		}
+		if nextEscalationAt.Valid {
+			a.NextEscalationAt = &nextEscalationAt.Time
+		}
+		if silenceID.Valid {
+			a.SilenceID = &silenceID.Int64
+		}
+		if lastSeenAt.Valid {
+			a.LastSeenAt = &lastSeenAt.Time
+		}
+		if labels.Valid {
+			json.Unmarshal([]byte(labels.String), &a.Labels)
+		}
 
 		alerts = append(alerts, a)
 	}
@@ -155,17 +408,31 @@ func AcknowledgeAlert(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(`
+	user, err := services.GetUserByID(middleware.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
 		UPDATE alerts 
-		SET status = 'acknowledged', acknowledged_at = ?, acknowledged_by = 'admin'
-		WHERE id = ?
-	`, time.Now(), id)
+		SET status = 'acknowledged', acknowledged_at = ?, acknowledged_by = ?, acknowledged_by_id = ?, next_escalation_at = NULL
+		WHERE id = ? AND status = 'active'
+	`, now, user.Username, user.ID, id)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to acknowledge alert"})
 		return
 	}
 
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		services.RecordAlertActivity(id, models.AlertActivityAcknowledged, &user.ID, "")
+		services.PublishEvent(services.EventAlertAcknowledged, services.AlertStatusChange{
+			ID: id, Status: models.AlertStatusAcknowledged, By: user.Username, At: now.UTC(),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Alert acknowledged successfully"})
 }
 
@@ -176,16 +443,65 @@ func ResolveAlert(c *gin.Context) {
 		return
 	}
 
-	_, err = database.DB.Exec(`
+	user, err := services.GetUserByID(middleware.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
 		UPDATE alerts 
-		SET status = 'resolved', resolved_at = ?
-		WHERE id = ?
-	`, time.Now(), id)
+		SET status = 'resolved', resolved_at = ?, next_escalation_at = NULL
+		WHERE id = ? AND status != 'resolved'
+	`, now, id)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve alert"})
 		return
 	}
 
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		services.RecordAlertActivity(id, models.AlertActivityResolved, &user.ID, "")
+		services.PublishEvent(services.EventAlertResolved, services.AlertStatusChange{
+			ID: id, Status: models.AlertStatusResolved, By: user.Username, At: now.UTC(),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Alert resolved successfully"})
 }
+
+func GetAlertEscalations(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, alert_id, step_order, target_type, target_id, COALESCE(recipient, ''), status, COALESCE(error, ''), notified_at
+		FROM alert_escalations
+		WHERE alert_id = ?
+		ORDER BY notified_at ASC
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch escalation history"})
+		return
+	}
+	defer rows.Close()
+
+	var history []models.AlertEscalation
+	for rows.Next() {
+		var e models.AlertEscalation
+		if err := rows.Scan(&e.ID, &e.AlertID, &e.StepOrder, &e.TargetType, &e.TargetID, &e.Recipient, &e.Status, &e.Error, &e.NotifiedAt); err != nil {
+			continue
+		}
+		history = append(history, e)
+	}
+
+	if history == nil {
+		history = []models.AlertEscalation{}
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -160,6 +160,21 @@ func GetCurrentUser(c *gin.Context) {
 	})
 }
 
+// CreateStreamTicket issues a single-use ticket to open an event stream or a
+// task log stream, which browsers cannot authenticate with a header.
+func CreateStreamTicket(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("userRole")
+
+	ticket, expiresAt, err := services.GenerateStreamTicket(userID.(int64), role.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue stream ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket, "expires_at": expiresAt})
+}
+
 func ChangePassword(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
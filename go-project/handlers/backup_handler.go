@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetServerBackups lists backup archives on every online node of the
+// cluster or only ?node=, from every backup storage or only ?storage=, and
+// only of ?vmid= when given.
+func GetServerBackups(c *gin.Context) {
+	_, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+
+	vmid := 0
+	if v := c.Query("vmid"); v != "" {
+		var err error
+		if vmid, err = strconv.Atoi(v); err != nil || vmid <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid VM ID"})
+			return
+		}
+	}
+
+	nodes, ok := clusterNodes(c, client)
+	if !ok {
+		return
+	}
+
+	backups, err := client.GetClusterBackups(nodes, vmid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if storage := c.Query("storage"); storage != "" {
+		backups = slices.DeleteFunc(backups, func(b services.ProxmoxBackupVolume) bool {
+			return b.Storage != storage
+		})
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+// GetServerBackupStorages lists the storages backups can be written to from
+// ?node=, by default the server's node.
+func GetServerBackupStorages(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	node, ok := targetNode(c, s, client, c.Query("node"))
+	if !ok {
+		return
+	}
+
+	storages, err := client.GetStorages(node, "backup")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if storages == nil {
+		storages = []services.ProxmoxStorage{}
+	}
+
+	c.JSON(http.StatusOK, storages)
+}
+
+func CreateServerBackup(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		VMID    int    `json:"vmid" binding:"required"`
+		Storage string `json:"storage" binding:"required"`
+		Mode    string `json:"mode"`
+		Notes   string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Mode == "" {
+		input.Mode = "snapshot"
+	}
+	if !services.BackupModes[input.Mode] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be snapshot, suspend or stop"})
+		return
+	}
+
+	node, ok := guestNode(c, client, "", input.VMID)
+	if !ok {
+		return
+	}
+	upid, err := client.Backup(node, input.VMID, input.Storage, input.Mode, input.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditServerAction(c, s.ID, "backup_create",
+		fmt.Sprintf("Backup of guest %d on node %s to %s", input.VMID, node, input.Storage))
+	taskID := trackTask(c, s, upid, fmt.Sprintf("Guest %d: back up to %s", input.VMID, input.Storage))
+	c.JSON(http.StatusCreated, gin.H{"message": "Backup initiated", "upid": upid, "task_id": taskID})
+}
+
+// RestoreServerBackup restores an archive as guest vmid. The guest type is
+// taken from the archive name unless given; force overwrites an existing
+// guest with the same ID. New guests are restored on node, by default the
+// server's node; archives on local storage must be restored on the node
+// holding them.
+func RestoreServerBackup(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		VolID   string `json:"volid" binding:"required"`
+		VMID    int    `json:"vmid" binding:"required"`
+		Type    string `json:"type"`
+		Storage string `json:"storage"`
+		Node    string `json:"node"`
+		Force   bool   `json:"force"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	guestType := input.Type
+	if guestType == "" {
+		guestType = services.BackupGuestType(input.VolID)
+	}
+	if guestType != services.GuestQEMU && guestType != services.GuestLXC {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be qemu or lxc"})
+		return
+	}
+
+	// An existing guest can only be overwritten on its own node.
+	guest, err := client.FindGuest(input.VMID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var node string
+	if guest != nil {
+		node = guest.Node
+	} else if node, ok = targetNode(c, s, client, input.Node); !ok {
+		return
+	}
+
+	upid, err := client.RestoreBackup(node, guestType, input.VMID, input.VolID, input.Storage, input.Force)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditServerAction(c, s.ID, "backup_restore",
+		fmt.Sprintf("Restore of %s as %s %d on node %s", input.VolID, guestLabel(guestType), input.VMID, node))
+	taskID := trackTask(c, s, upid, fmt.Sprintf("%s %d: restore %s", guestLabel(guestType), input.VMID, input.VolID))
+	c.JSON(http.StatusOK, gin.H{"message": "Restore initiated", "upid": upid, "task_id": taskID})
+}
+
+// GetServerBackupJobs lists the scheduled backup jobs of the server's
+// cluster.
+func GetServerBackupJobs(c *gin.Context) {
+	_, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := client.GetBackupJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if jobs == nil {
+		jobs = []services.ProxmoxBackupJob{}
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// defaultBackupMaxAgeDays is how recent a backup must be to count when the
+// report is requested without ?days=.
+const defaultBackupMaxAgeDays = 7
+
+func backupMaxAgeDays(days int) (int, bool) {
+	if days == 0 {
+		return defaultBackupMaxAgeDays, true
+	}
+	return days, days > 0 && days <= 365
+}
+
+// GetBackupReport lists guests on all Proxmox servers without a backup newer
+// than ?days= (7 by default).
+func GetBackupReport(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+	days, ok := backupMaxAgeDays(days)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+
+	report, err := services.BuildBackupReport(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build backup report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// AttachBackupEvidence runs the backup report and records its summary as the
+// evidence of a compliance record, marking it audited now. With set_status
+// the record also becomes compliant when every guest is covered,
+// non-compliant when some are not, and under review when servers could not
+// be checked.
+func AttachBackupEvidence(c *gin.Context) {
+	var input struct {
+		ComplianceID int64 `json:"compliance_id" binding:"required"`
+		Days         int   `json:"days"`
+		SetStatus    bool  `json:"set_status"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	days, ok := backupMaxAgeDays(input.Days)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+
+	var exists int
+	database.DB.QueryRow("SELECT COUNT(*) FROM compliance WHERE id = ?", input.ComplianceID).Scan(&exists)
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Compliance record not found"})
+		return
+	}
+
+	report, err := services.BuildBackupReport(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build backup report"})
+		return
+	}
+
+	// A nil status leaves the record's status as it is.
+	var status *models.ComplianceStatus
+	if input.SetStatus {
+		s := models.ComplianceStatusCompliant
+		if len(report.Uncovered) > 0 {
+			s = models.ComplianceStatusNonCompliant
+		} else if len(report.Errors) > 0 {
+			s = models.ComplianceStatusUnderReview
+		}
+		status = &s
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE compliance SET evidence = ?, last_audit_date = ?, status = COALESCE(?, status) WHERE id = ?
+	`, services.BackupEvidence(report), time.Now(), status, input.ComplianceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update compliance record"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
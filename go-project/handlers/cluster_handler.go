@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"go-project/services"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetServerGuests lists the VMs and containers on every node of the server's
+// cluster, narrowed by ?type= (qemu or lxc) and ?node=.
+func GetServerGuests(c *gin.Context) {
+	_, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+
+	guestType := c.Query("type")
+	if guestType != "" && guestType != services.GuestQEMU && guestType != services.GuestLXC {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be qemu or lxc"})
+		return
+	}
+
+	guests, err := client.GetClusterGuests()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	node := c.Query("node")
+	guests = slices.DeleteFunc(guests, func(g services.ProxmoxClusterResource) bool {
+		return (guestType != "" && g.Type != guestType) || (node != "" && g.Node != node)
+	})
+
+	c.JSON(http.StatusOK, guests)
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type escalationPolicyInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Steps       []struct {
+		DelayMinutes int    `json:"delay_minutes" binding:"min=0"`
+		TargetType   string `json:"target_type" binding:"required,oneof=user channel schedule"`
+		TargetID     int64  `json:"target_id" binding:"required"`
+	} `json:"steps" binding:"required,min=1,dive"`
+}
+
+func GetEscalationPolicies(c *gin.Context) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, COALESCE(description, ''), created_at, updated_at
+		FROM escalation_policies
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch escalation policies"})
+		return
+	}
+
+	var policies []models.EscalationPolicy
+	for rows.Next() {
+		var p models.EscalationPolicy
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			continue
+		}
+		policies = append(policies, p)
+	}
+	rows.Close()
+
+	for i := range policies {
+		policies[i].Steps = loadEscalationSteps(policies[i].ID)
+	}
+
+	if policies == nil {
+		policies = []models.EscalationPolicy{}
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+func GetEscalationPolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid escalation policy ID"})
+		return
+	}
+
+	var p models.EscalationPolicy
+	err = database.DB.QueryRow(`
+		SELECT id, name, COALESCE(description, ''), created_at, updated_at
+		FROM escalation_policies WHERE id = ?
+	`, id).Scan(&p.ID, &p.Name, &p.Description, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escalation policy not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch escalation policy"})
+		return
+	}
+
+	p.Steps = loadEscalationSteps(p.ID)
+
+	c.JSON(http.StatusOK, p)
+}
+
+func CreateEscalationPolicy(c *gin.Context) {
+	var input escalationPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create escalation policy"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO escalation_policies (name, description) VALUES (?, ?)
+	`, input.Name, input.Description)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create escalation policy"})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	if err := replaceEscalationSteps(tx, id, input); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save escalation steps"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create escalation policy"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Escalation policy created successfully"})
+}
+
+func UpdateEscalationPolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid escalation policy ID"})
+		return
+	}
+
+	var input escalationPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update escalation policy"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE escalation_policies SET name = ?, description = ? WHERE id = ?
+	`, input.Name, input.Description, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update escalation policy"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escalation policy not found"})
+		return
+	}
+
+	if err := replaceEscalationSteps(tx, id, input); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save escalation steps"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update escalation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Escalation policy updated successfully"})
+}
+
+func DeleteEscalationPolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid escalation policy ID"})
+		return
+	}
+
+	_, err = database.DB.Exec("DELETE FROM escalation_policies WHERE id = ?", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete escalation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Escalation policy deleted successfully"})
+}
+
+// replaceEscalationSteps rewrites a policy's steps in the order given,
+// numbering them from 1.
+func replaceEscalationSteps(tx *sql.Tx, policyID int64, input escalationPolicyInput) error {
+	if _, err := tx.Exec("DELETE FROM escalation_steps WHERE policy_id = ?", policyID); err != nil {
+		return err
+	}
+
+	for i, step := range input.Steps {
+		_, err := tx.Exec(`
+			INSERT INTO escalation_steps (policy_id, step_order, delay_minutes, target_type, target_id)
+			VALUES (?, ?, ?, ?, ?)
+		`, policyID, i+1, step.DelayMinutes, step.TargetType, step.TargetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadEscalationSteps(policyID int64) []models.EscalationStep {
+	steps := []models.EscalationStep{}
+
+	rows, err := database.DB.Query(`
+		SELECT id, policy_id, step_order, delay_minutes, target_type, target_id
+		FROM escalation_steps
+		WHERE policy_id = ?
+		ORDER BY step_order ASC
+	`, policyID)
+	if err != nil {
+		return steps
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.EscalationStep
+		if err := rows.Scan(&s.ID, &s.PolicyID, &s.StepOrder, &s.DelayMinutes, &s.TargetType, &s.TargetID); err != nil {
+			continue
+		}
+		steps = append(steps, s)
+	}
+
+	return steps
+}
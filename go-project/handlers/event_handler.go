@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"go-project/services"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventFilter builds a filter from the comma-separated types query parameter.
+// A type ending in ".*" matches every event with that prefix, so
+// types=alert.* streams all alert events. Without the parameter every event
+// is sent.
+func eventFilter(c *gin.Context) func(services.EventType) bool {
+	value := c.Query("types")
+	if value == "" {
+		return func(services.EventType) bool { return true }
+	}
+
+	var types []string
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	return func(eventType services.EventType) bool {
+		for _, t := range types {
+			if prefix, ok := strings.CutSuffix(t, "*"); ok {
+				if strings.HasPrefix(string(eventType), prefix) {
+					return true
+				}
+			} else if t == string(eventType) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// StreamEvents sends alert, monitor, server and task events as server-sent
+// events.
+func StreamEvents(c *gin.Context) {
+	filter := eventFilter(c)
+
+	events, unsubscribe := services.GetEventBus().Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// An initial comment flushes the headers so clients see the stream open.
+	io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			if filter(event.Type) {
+				c.SSEvent(string(event.Type), event)
+			}
+			return true
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// StreamEventsWebSocket sends the same events as StreamEvents over a
+// WebSocket, one JSON message per event.
+func StreamEventsWebSocket(c *gin.Context) {
+	services.StreamEvents(c.Writer, c.Request, eventFilter(c))
+}
@@ -34,9 +34,12 @@ type InfrastructureNode struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// GetInfrastructureNodes lists the nodes of every Proxmox server from the
+// inventory, as of its last sync. Nodes not synced for a while are reported
+// with an unknown status.
 func GetInfrastructureNodes(c *gin.Context) {
 	rows, err := database.DB.Query(`
-		SELECT id, name, type, ip_address, port, username, password, realm, verify_ssl
+		SELECT id, name, type, ip_address, COALESCE(status, '')
 		FROM servers
 		WHERE type = 'proxmox'
 	`)
@@ -44,69 +47,86 @@ func GetInfrastructureNodes(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch servers"})
 		return
 	}
-	defer rows.Close()
-
-	var nodes []InfrastructureNode
-
+	servers := map[int64]models.Server{}
 	for rows.Next() {
 		var srv models.Server
-		var pwd string
-		err := rows.Scan(&srv.ID, &srv.Name, &srv.Type, &srv.IPAddress, &srv.Port, &srv.Username, &pwd, &srv.Realm, &srv.VerifySSL)
-		if err != nil {
+		if err := rows.Scan(&srv.ID, &srv.Name, &srv.Type, &srv.IPAddress, &srv.Status); err != nil {
 			continue
 		}
+		servers[srv.ID] = srv
+	}
+	rows.Close()
+
+	inventory, err := services.ListInventoryNodes(services.InventoryFilter{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch nodes"})
+		return
+	}
+	guests, err := services.ListInventoryGuests(services.InventoryFilter{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guests"})
+		return
+	}
 
-		baseURL := "https://" + srv.IPAddress + ":" + strconv.Itoa(srv.Port)
-		proxmoxClient := services.NewProxmoxClient(baseURL, srv.Username, pwd, srv.Realm, srv.VerifySSL)
+	type nodeKey struct {
+		serverID int64
+		node     string
+	}
+	byNode := map[nodeKey][]models.InventoryGuest{}
+	for _, guest := range guests {
+		key := nodeKey{guest.ServerID, guest.Node}
+		byNode[key] = append(byNode[key], guest)
+	}
 
-		proxmoxNodes, err := proxmoxClient.GetNodes()
-		if err != nil {
+	nodes := []InfrastructureNode{}
+	for _, node := range inventory {
+		srv, ok := servers[node.ServerID]
+		if !ok {
 			continue
 		}
 
-		for _, node := range proxmoxNodes {
-			nodeData := InfrastructureNode{
-				ID:            srv.ID,
-				ServerName:    srv.Name,
-				NodeName:      node.Node,
-				ServerType:    string(srv.Type),
-				IPAddress:     srv.IPAddress,
-				ServerStatus:  "active",
-				LastSync:      time.Now().Format(time.RFC3339),
-				NodeStatus:    node.Status,
-				CPU:           node.CPU,
-				Memory:        float64(node.Mem),
-				MaxMemory:     float64(node.Maxmem),
-				MemoryPercent: 0,
-				Uptime:        int64(node.Uptime),
-				CreatedAt:     srv.CreatedAt,
-				UpdatedAt:     srv.UpdatedAt,
-			}
-
-			if node.Maxmem > 0 {
-				nodeData.MemoryPercent = (float64(node.Mem) / float64(node.Maxmem)) * 100
-			}
-
-			vms, _ := proxmoxClient.GetVMs(node.Node)
-			lxcs, _ := proxmoxClient.GetLXCs(node.Node)
+		nodeData := InfrastructureNode{
+			ID:            srv.ID,
+			ServerName:    srv.Name,
+			NodeName:      node.Node,
+			ServerType:    string(srv.Type),
+			IPAddress:     srv.IPAddress,
+			ServerStatus:  srv.Status,
+			LastSync:      node.LastSeen.Format(time.RFC3339),
+			NodeStatus:    node.Status,
+			CPU:           node.CPU,
+			Memory:        float64(node.Mem),
+			MaxMemory:     float64(node.MaxMem),
+			MemoryPercent: 0,
+			Uptime:        node.Uptime,
+			CreatedAt:     node.FirstSeen,
+			UpdatedAt:     node.LastSeen,
+		}
+		if time.Since(node.LastSeen) > services.InventoryStaleAfter {
+			nodeData.NodeStatus = "unknown"
+		}
 
-			nodeData.VMCount = len(vms)
-			nodeData.LXCCount = len(lxcs)
+		if node.MaxMem > 0 {
+			nodeData.MemoryPercent = (float64(node.Mem) / float64(node.MaxMem)) * 100
+		}
 
-			for _, vm := range vms {
-				if vm.Status == "running" {
+		for _, guest := range byNode[nodeKey{node.ServerID, node.Node}] {
+			switch guest.Type {
+			case services.GuestQEMU:
+				nodeData.VMCount++
+				if guest.Status == "running" {
 					nodeData.RunningVMs++
 				}
-			}
-			for _, lxc := range lxcs {
-				if lxc.Status == "running" {
+			case services.GuestLXC:
+				nodeData.LXCCount++
+				if guest.Status == "running" {
 					nodeData.RunningLXCs++
 				}
 			}
-			nodeData.TotalInstances = nodeData.VMCount + nodeData.LXCCount
-
-			nodes = append(nodes, nodeData)
 		}
+		nodeData.TotalInstances = nodeData.VMCount + nodeData.LXCCount
+
+		nodes = append(nodes, nodeData)
 	}
 
 	c.JSON(http.StatusOK, nodes)
@@ -115,3 +135,135 @@ func GetInfrastructureNodes(c *gin.Context) {
 func GetInfrastructureNodeDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Node details endpoint"})
 }
+
+// storageForecastDays reads ?days=, the window storage forecasts are fitted
+// over.
+func storageForecastDays(c *gin.Context) (int, bool) {
+	days := services.StorageForecastDays
+	if v := c.Query("days"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil || days < 1 || days > 30 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 30"})
+			return 0, false
+		}
+	}
+	return days, true
+}
+
+// GetInfrastructureStorage lists every storage of every Proxmox server, or
+// only of ?server_id=, fullest first with a fill forecast over ?days=.
+func GetInfrastructureStorage(c *gin.Context) {
+	var serverID int64
+	if v := c.Query("server_id"); v != "" {
+		var err error
+		if serverID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+			return
+		}
+	}
+	days, ok := storageForecastDays(c)
+	if !ok {
+		return
+	}
+
+	overview, err := services.BuildStorageOverview(serverID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch storage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// GetInfrastructureStorageHistory returns the sampled usage of one storage
+// over the last ?days= with its forecast. Shared storages are addressed
+// without ?node=.
+func GetInfrastructureStorageHistory(c *gin.Context) {
+	serverID, err := strconv.ParseInt(c.Query("server_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+		return
+	}
+	storage := c.Query("storage")
+	if storage == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "storage is required"})
+		return
+	}
+	days, ok := storageForecastDays(c)
+	if !ok {
+		return
+	}
+
+	node := c.Query("node")
+	samples, err := services.GetStorageHistory(serverID, node, storage, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch storage history"})
+		return
+	}
+	forecast, err := services.ForecastStorage(serverID, node, storage, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to forecast storage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"samples": samples, "forecast": forecast})
+}
+
+// GetInfrastructureMetrics returns the collected history of a node, or of the
+// guest ?vmid=, between ?from= and ?to= (RFC 3339, default the last 24
+// hours). The timeframe is the finest still kept for ?from= unless
+// ?timeframe= is hour, day or week.
+func GetInfrastructureMetrics(c *gin.Context) {
+	serverID, err := strconv.ParseInt(c.Query("server_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+		return
+	}
+
+	node := c.Query("node")
+	var vmid int
+	if v := c.Query("vmid"); v != "" {
+		if vmid, err = strconv.Atoi(v); err != nil || vmid <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid VM ID"})
+			return
+		}
+	} else if node == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "node or vmid is required"})
+		return
+	}
+
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 time"})
+			return
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 time"})
+			return
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	timeframe := c.Query("timeframe")
+	if timeframe == "" {
+		timeframe = services.MetricTimeframeFor(from)
+	} else if !services.ValidMetricTimeframe(timeframe) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timeframe must be hour, day or week"})
+		return
+	}
+
+	series, err := services.GetMetricHistory(serverID, node, vmid, timeframe, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-project/services"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// alertmanagerPayload is the body of a Prometheus Alertmanager webhook.
+type alertmanagerPayload struct {
+	Version string `json:"version"`
+	Status  string `json:"status"`
+	Alerts  []struct {
+		Status      string            `json:"status"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+		Fingerprint string            `json:"fingerprint"`
+	} `json:"alerts" binding:"required"`
+}
+
+type genericAlertInput struct {
+	Name        string            `json:"name"`
+	Status      string            `json:"status"` // firing (default) or resolved
+	Severity    string            `json:"severity"`
+	Message     string            `json:"message"`
+	Target      string            `json:"target"`
+	Value       float64           `json:"value"`
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+}
+
+func IngestAlertmanager(c *gin.Context) {
+	var payload alertmanagerPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var failed int
+	for _, a := range payload.Alerts {
+		name := a.Labels["alertname"]
+		if name == "" {
+			name = "alertmanager"
+		}
+
+		message := a.Annotations["summary"]
+		if message == "" {
+			message = a.Annotations["description"]
+		}
+
+		err := services.IngestAlert(services.ExternalAlert{
+			Name:        name,
+			Resolved:    a.Status == "resolved",
+			Severity:    a.Labels["severity"],
+			Message:     message,
+			Target:      a.Labels["instance"],
+			Fingerprint: a.Fingerprint,
+			Labels:      a.Labels,
+		})
+		if err != nil {
+			log.Printf("[ALERT] Failed to ingest Alertmanager alert %s: %v", name, err)
+			failed++
+		}
+	}
+
+	ingestResponse(c, len(payload.Alerts), failed)
+}
+
+// IngestAlerts accepts one generic alert object or an array of them.
+func IngestAlerts(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var inputs []genericAlertInput
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &inputs)
+	} else {
+		var input genericAlertInput
+		err = json.Unmarshal(trimmed, &input)
+		inputs = append(inputs, input)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert payload: " + err.Error()})
+		return
+	}
+
+	for _, input := range inputs {
+		if input.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Every alert needs a name"})
+			return
+		}
+		if input.Status != "" && input.Status != "firing" && input.Status != "resolved" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be firing or resolved"})
+			return
+		}
+	}
+
+	var failed int
+	for _, input := range inputs {
+		err := services.IngestAlert(services.ExternalAlert{
+			Name:        input.Name,
+			Resolved:    input.Status == "resolved",
+			Severity:    input.Severity,
+			Message:     input.Message,
+			Target:      input.Target,
+			Value:       input.Value,
+			Fingerprint: input.Fingerprint,
+			Labels:      input.Labels,
+		})
+		if err != nil {
+			log.Printf("[ALERT] Failed to ingest alert %s: %v", input.Name, err)
+			failed++
+		}
+	}
+
+	ingestResponse(c, len(inputs), failed)
+}
+
+func ingestResponse(c *gin.Context, received, failed int) {
+	if failed > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"received": received, "failed": failed, "error": "Failed to ingest some alerts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"received": received})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inventoryFilter reads ?server_id=, ?node= and ?include_gone=true.
+func inventoryFilter(c *gin.Context) (services.InventoryFilter, bool) {
+	filter := services.InventoryFilter{
+		Node:        c.Query("node"),
+		IncludeGone: c.Query("include_gone") == "true",
+	}
+	if v := c.Query("server_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+			return filter, false
+		}
+		filter.ServerID = id
+	}
+	return filter, true
+}
+
+// GetInventoryNodes lists the synced nodes of every Proxmox server, with
+// those that left their cluster when ?include_gone=true.
+func GetInventoryNodes(c *gin.Context) {
+	filter, ok := inventoryFilter(c)
+	if !ok {
+		return
+	}
+
+	nodes, err := services.ListInventoryNodes(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory nodes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, nodes)
+}
+
+// GetInventoryGuests lists the synced VMs and containers of every Proxmox
+// server, with deleted ones when ?include_gone=true.
+func GetInventoryGuests(c *gin.Context) {
+	filter, ok := inventoryFilter(c)
+	if !ok {
+		return
+	}
+
+	guests, err := services.ListInventoryGuests(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory guests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, guests)
+}
+
+// GetInventoryEvents lists inventory changes, newest first.
+func GetInventoryEvents(c *gin.Context) {
+	filter := services.InventoryEventFilter{
+		Node:  c.Query("node"),
+		Event: models.InventoryEventType(c.Query("event")),
+	}
+
+	if v := c.Query("server_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+			return
+		}
+		filter.ServerID = id
+	}
+	if v := c.Query("vmid"); v != "" {
+		vmid, err := strconv.Atoi(v)
+		if err != nil || vmid <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid VM ID"})
+			return
+		}
+		filter.VMID = vmid
+	}
+	if v := c.Query("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return
+		}
+		filter.Since = since
+	}
+
+	filter.Limit = 50
+	if v := c.Query("limit"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil && limit > 0 && limit <= 500 {
+			filter.Limit = limit
+		}
+	}
+
+	events, err := services.ListInventoryEvents(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/middleware"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetLicenseAlerts(c *gin.Context) {
+	query := `
+		SELECT a.id, a.license_id, l.name, a.alert_type, a.threshold_days, a.due_date, COALESCE(a.message, ''),
+		       a.triggered_at, a.acknowledged_at, COALESCE(a.acknowledged_by, ''), a.created_at
+		FROM license_alerts a
+		INNER JOIN licenses l ON a.license_id = l.id
+		WHERE 1=1
+	`
+	args := []interface{}{}
+
+	if licenseID := c.Query("license_id"); licenseID != "" {
+		query += " AND a.license_id = ?"
+		args = append(args, licenseID)
+	}
+
+	if alertType := c.Query("alert_type"); alertType != "" {
+		query += " AND a.alert_type = ?"
+		args = append(args, alertType)
+	}
+
+	switch c.Query("acknowledged") {
+	case "true":
+		query += " AND a.acknowledged_at IS NOT NULL"
+	case "false":
+		query += " AND a.acknowledged_at IS NULL"
+	}
+
+	query += " ORDER BY a.created_at DESC LIMIT 100"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch license alerts"})
+		return
+	}
+	defer rows.Close()
+
+	alerts := []models.LicenseAlert{}
+	for rows.Next() {
+		var a models.LicenseAlert
+		var dueDate, triggeredAt, acknowledgedAt sql.NullTime
+
+		err := rows.Scan(&a.ID, &a.LicenseID, &a.LicenseName, &a.AlertType, &a.ThresholdDays, &dueDate, &a.Message,
+			&triggeredAt, &acknowledgedAt, &a.AcknowledgedBy, &a.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		if dueDate.Valid {
+			a.DueDate = &dueDate.Time
+		}
+		if triggeredAt.Valid {
+			a.TriggeredAt = &triggeredAt.Time
+		}
+		if acknowledgedAt.Valid {
+			a.AcknowledgedAt = &acknowledgedAt.Time
+		}
+
+		alerts = append(alerts, a)
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func AcknowledgeLicenseAlert(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid license alert ID"})
+		return
+	}
+
+	user, err := services.GetUserByID(middleware.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE license_alerts SET acknowledged_at = ?, acknowledged_by = ?
+		WHERE id = ? AND acknowledged_at IS NULL
+	`, time.Now(), user.Username, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to acknowledge license alert"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unacknowledged license alert not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "License alert acknowledged"})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type notificationChannelInput struct {
+	Name    string `json:"name" binding:"required"`
+	Type    string `json:"type" binding:"required,oneof=email webhook slack"`
+	Target  string `json:"target" binding:"required"`
+	Enabled *bool  `json:"enabled"`
+}
+
+func GetNotificationChannels(c *gin.Context) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, type, target, enabled, created_at, updated_at
+		FROM notification_channels
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification channels"})
+		return
+	}
+	defer rows.Close()
+
+	var channels []models.NotificationChannel
+	for rows.Next() {
+		var ch models.NotificationChannel
+		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Target, &ch.Enabled, &ch.CreatedAt, &ch.UpdatedAt); err != nil {
+			continue
+		}
+		channels = append(channels, ch)
+	}
+
+	if channels == nil {
+		channels = []models.NotificationChannel{}
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+func CreateNotificationChannel(c *gin.Context) {
+	var input notificationChannelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enabled := true
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO notification_channels (name, type, target, enabled)
+		VALUES (?, ?, ?, ?)
+	`, input.Name, input.Type, input.Target, enabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification channel"})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Notification channel created successfully"})
+}
+
+func UpdateNotificationChannel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification channel ID"})
+		return
+	}
+
+	var input notificationChannelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enabled := true
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE notification_channels SET name = ?, type = ?, target = ?, enabled = ?
+		WHERE id = ?
+	`, input.Name, input.Type, input.Target, enabled, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification channel"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification channel updated successfully"})
+}
+
+func DeleteNotificationChannel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification channel ID"})
+		return
+	}
+
+	_, err = database.DB.Exec("DELETE FROM notification_channels WHERE id = ?", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification channel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification channel deleted successfully"})
+}
+
+func TestNotificationChannel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification channel ID"})
+		return
+	}
+
+	var ch models.NotificationChannel
+	err = database.DB.QueryRow(`
+		SELECT id, name, type, target, enabled FROM notification_channels WHERE id = ?
+	`, id).Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Target, &ch.Enabled)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification channel"})
+		return
+	}
+
+	err = services.SendToChannel(ch, services.Notification{
+		Subject: "Test notification",
+		Body:    "This is a test notification from the compliance management system.",
+	})
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"delivered": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delivered": true})
+}
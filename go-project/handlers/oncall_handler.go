@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type onCallScheduleInput struct {
+	Name          string  `json:"name" binding:"required"`
+	Description   string  `json:"description"`
+	Timezone      string  `json:"timezone"`
+	RotationType  string  `json:"rotation_type" binding:"required,oneof=daily weekly"`
+	HandoffTime   string  `json:"handoff_time"`
+	RotationStart string  `json:"rotation_start" binding:"required"`
+	MemberIDs     []int64 `json:"member_ids"` // Rotation order
+}
+
+// validate fills defaults and checks that the schedule's rotation can be
+// computed.
+func (input *onCallScheduleInput) validate() error {
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+	if input.HandoffTime == "" {
+		input.HandoffTime = "09:00"
+	}
+
+	_, err := services.RotationShift(models.OnCallSchedule{
+		Timezone:      input.Timezone,
+		RotationType:  models.RotationType(input.RotationType),
+		HandoffTime:   input.HandoffTime,
+		RotationStart: input.RotationStart,
+	}, time.Now())
+	return err
+}
+
+func GetOnCallSchedules(c *gin.Context) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, COALESCE(description, ''), timezone, rotation_type, handoff_time, rotation_start, created_at, updated_at
+		FROM oncall_schedules
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch on-call schedules"})
+		return
+	}
+
+	var schedules []models.OnCallSchedule
+	for rows.Next() {
+		var s models.OnCallSchedule
+		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.Timezone, &s.RotationType, &s.HandoffTime, &s.RotationStart,
+			&s.CreatedAt, &s.UpdatedAt); err != nil {
+			continue
+		}
+		schedules = append(schedules, s)
+	}
+	rows.Close()
+
+	for i := range schedules {
+		schedules[i].Members = loadOnCallMembers(schedules[i].ID)
+		schedules[i].Overrides = loadOnCallOverrides(schedules[i].ID)
+	}
+
+	if schedules == nil {
+		schedules = []models.OnCallSchedule{}
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func GetOnCallSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	var s models.OnCallSchedule
+	err = database.DB.QueryRow(`
+		SELECT id, name, COALESCE(description, ''), timezone, rotation_type, handoff_time, rotation_start, created_at, updated_at
+		FROM oncall_schedules WHERE id = ?
+	`, id).Scan(&s.ID, &s.Name, &s.Description, &s.Timezone, &s.RotationType, &s.HandoffTime, &s.RotationStart,
+		&s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedule"})
+		return
+	}
+
+	s.Members = loadOnCallMembers(s.ID)
+	s.Overrides = loadOnCallOverrides(s.ID)
+
+	c.JSON(http.StatusOK, s)
+}
+
+func CreateOnCallSchedule(c *gin.Context) {
+	var input onCallScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := input.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO oncall_schedules (name, description, timezone, rotation_type, handoff_time, rotation_start)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, input.Name, input.Description, input.Timezone, input.RotationType, input.HandoffTime, input.RotationStart)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	if err := replaceOnCallMembers(tx, id, input.MemberIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule members"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Schedule created successfully"})
+}
+
+func UpdateOnCallSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	var input onCallScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := input.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE oncall_schedules
+		SET name = ?, description = ?, timezone = ?, rotation_type = ?, handoff_time = ?, rotation_start = ?
+		WHERE id = ?
+	`, input.Name, input.Description, input.Timezone, input.RotationType, input.HandoffTime, input.RotationStart, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	if err := replaceOnCallMembers(tx, id, input.MemberIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule members"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule updated successfully"})
+}
+
+func DeleteOnCallSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	_, err = database.DB.Exec("DELETE FROM oncall_schedules WHERE id = ?", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+func GetCurrentOnCall(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	at := time.Now()
+	if atParam := c.Query("at"); atParam != "" {
+		at, err = time.Parse(time.RFC3339, atParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid at timestamp, expected RFC3339"})
+			return
+		}
+	}
+
+	userID, err := services.CurrentOnCall(id, at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID == 0 {
+		c.JSON(http.StatusOK, gin.H{"schedule_id": id, "at": at, "user": nil})
+		return
+	}
+
+	user, err := services.GetUserByID(userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch on-call user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedule_id": id,
+		"at":          at,
+		"user": gin.H{
+			"id":        user.ID,
+			"username":  user.Username,
+			"email":     user.Email,
+			"full_name": user.FullName,
+			"phone":     user.Phone,
+		},
+	})
+}
+
+func CreateOnCallOverride(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	var input struct {
+		UserID   int64     `json:"user_id" binding:"required"`
+		StartsAt time.Time `json:"starts_at" binding:"required"`
+		EndsAt   time.Time `json:"ends_at" binding:"required"`
+		Reason   string    `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !input.EndsAt.After(input.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+
+	// Stored in UTC so overrides compare consistently regardless of the
+	// offset they were submitted with.
+	result, err := database.DB.Exec(`
+		INSERT INTO oncall_overrides (schedule_id, user_id, starts_at, ends_at, reason)
+		VALUES (?, ?, ?, ?, ?)
+	`, scheduleID, input.UserID, input.StartsAt.UTC(), input.EndsAt.UTC(), input.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create override"})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Override created successfully"})
+}
+
+func DeleteOnCallOverride(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid override ID"})
+		return
+	}
+
+	_, err = database.DB.Exec("DELETE FROM oncall_overrides WHERE id = ?", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Override deleted successfully"})
+}
+
+func replaceOnCallMembers(tx *sql.Tx, scheduleID int64, userIDs []int64) error {
+	if _, err := tx.Exec("DELETE FROM oncall_schedule_members WHERE schedule_id = ?", scheduleID); err != nil {
+		return err
+	}
+
+	for position, userID := range userIDs {
+		_, err := tx.Exec(`
+			INSERT INTO oncall_schedule_members (schedule_id, user_id, position)
+			VALUES (?, ?, ?)
+		`, scheduleID, userID, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadOnCallMembers(scheduleID int64) []models.OnCallMember {
+	members := []models.OnCallMember{}
+
+	rows, err := database.DB.Query(`
+		SELECT m.user_id, u.username, m.position
+		FROM oncall_schedule_members m
+		INNER JOIN users u ON m.user_id = u.id
+		WHERE m.schedule_id = ?
+		ORDER BY m.position ASC
+	`, scheduleID)
+	if err != nil {
+		return members
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.OnCallMember
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Position); err != nil {
+			continue
+		}
+		members = append(members, m)
+	}
+
+	return members
+}
+
+// loadOnCallOverrides returns overrides that have not ended yet.
+func loadOnCallOverrides(scheduleID int64) []models.OnCallOverride {
+	overrides := []models.OnCallOverride{}
+
+	rows, err := database.DB.Query(`
+		SELECT o.id, o.schedule_id, o.user_id, u.username, o.starts_at, o.ends_at, COALESCE(o.reason, ''), o.created_at
+		FROM oncall_overrides o
+		INNER JOIN users u ON o.user_id = u.id
+		WHERE o.schedule_id = ? AND o.ends_at > ?
+		ORDER BY o.starts_at ASC
+	`, scheduleID, time.Now().UTC())
+	if err != nil {
+		return overrides
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o models.OnCallOverride
+		if err := rows.Scan(&o.ID, &o.ScheduleID, &o.UserID, &o.Username, &o.StartsAt, &o.EndsAt, &o.Reason, &o.CreatedAt); err != nil {
+			continue
+		}
+		overrides = append(overrides, o)
+	}
+
+	return overrides
+}
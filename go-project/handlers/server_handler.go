@@ -113,8 +113,15 @@ func CreateServer(c *gin.Context) {
 
 	status := "inactive"
 	if input.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", input.IPAddress, input.Port)
-		client := services.NewProxmoxClient(baseURL, input.Username, input.Password, input.Realm, input.VerifySSL)
+		client := services.NewProxmoxClientForServer(models.Server{
+			IPAddress: input.IPAddress,
+			Port:      input.Port,
+			Username:  input.Username,
+			Password:  input.Password,
+			APIToken:  input.APIToken,
+			Realm:     input.Realm,
+			VerifySSL: input.VerifySSL,
+		})
 
 		if connected, _ := client.TestConnection(); connected {
 			status = "active"
@@ -174,8 +181,15 @@ func UpdateServer(c *gin.Context) {
 
 	status := input.Status
 	if input.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", input.IPAddress, input.Port)
-		client := services.NewProxmoxClient(baseURL, input.Username, input.Password, input.Realm, input.VerifySSL)
+		client := services.NewProxmoxClientForServer(models.Server{
+			IPAddress: input.IPAddress,
+			Port:      input.Port,
+			Username:  input.Username,
+			Password:  input.Password,
+			APIToken:  input.APIToken,
+			Realm:     input.Realm,
+			VerifySSL: input.VerifySSL,
+		})
 
 		if connected, _ := client.TestConnection(); connected {
 			status = "active"
@@ -198,6 +212,7 @@ func UpdateServer(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update server"})
 		return
 	}
+	services.GetProxmoxSessions().Invalidate(id)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Server updated successfully"})
 }
@@ -214,6 +229,7 @@ func DeleteServer(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete server"})
 		return
 	}
+	services.GetProxmoxSessions().Invalidate(id)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Server deleted successfully"})
 }
@@ -225,22 +241,15 @@ func TestServerConnection(c *gin.Context) {
 		return
 	}
 
-	var s models.Server
-	var password string
-	err = database.DB.QueryRow(`
-		SELECT type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), 
-		       COALESCE(realm, ''), verify_ssl
-		FROM servers WHERE id = ?
-	`, id).Scan(&s.Type, &s.IPAddress, &s.Port, &s.Username, &password, &s.Realm, &s.VerifySSL)
+	s, err := services.GetServerByID(id)
 
-	if err != nil {
+	if err != nil || s == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
 		return
 	}
 
 	if s.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port)
-		client := services.NewProxmoxClient(baseURL, s.Username, password, s.Realm, s.VerifySSL)
+		client := services.ProxmoxClientFor(*s)
 
 		connected, err := client.TestConnection()
 
@@ -249,6 +258,7 @@ func TestServerConnection(c *gin.Context) {
 			newStatus = "active"
 			database.DB.Exec("UPDATE servers SET status = ?, last_sync = ? WHERE id = ?",
 				newStatus, time.Now(), id)
+			services.PublishServerStatusChange(id, s.Name, s.Status, newStatus)
 
 			c.JSON(http.StatusOK, gin.H{"connected": true, "status": newStatus})
 		} else {
@@ -259,6 +269,7 @@ func TestServerConnection(c *gin.Context) {
 
 			database.DB.Exec("UPDATE servers SET status = ? WHERE id = ?",
 				newStatus, id)
+			services.PublishServerStatusChange(id, s.Name, s.Status, newStatus)
 
 			c.JSON(http.StatusOK, gin.H{
 				"connected": false,
@@ -279,22 +290,15 @@ func GetServerLogs(c *gin.Context) {
 		return
 	}
 
-	var s models.Server
-	var password string
-	err = database.DB.QueryRow(`
-		SELECT type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), 
-		       COALESCE(realm, ''), verify_ssl
-		FROM servers WHERE id = ?
-	`, id).Scan(&s.Type, &s.IPAddress, &s.Port, &s.Username, &password, &s.Realm, &s.VerifySSL)
+	s, err := services.GetServerByID(id)
 
-	if err != nil {
+	if err != nil || s == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
 		return
 	}
 
 	if s.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port)
-		client := services.NewProxmoxClient(baseURL, s.Username, password, s.Realm, s.VerifySSL)
+		client := services.ProxmoxClientFor(*s)
 
 		logs, err := client.GetClusterLogs()
 		if err != nil {
@@ -309,52 +313,36 @@ func GetServerLogs(c *gin.Context) {
 	c.JSON(http.StatusBadRequest, gin.H{"error": "Logs not supported for this server type"})
 }
 
+// GetServerLXCs lists the containers on every online node of the cluster,
+// or only on ?node=.
 func GetServerLXCs(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+	_, client, ok := proxmoxServer(c)
+	if !ok {
 		return
 	}
-
-	var s models.Server
-	var password string
-	err = database.DB.QueryRow(`
-		SELECT type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), 
-		       COALESCE(realm, ''), verify_ssl, COALESCE(node, '')
-		FROM servers WHERE id = ?
-	`, id).Scan(&s.Type, &s.IPAddress, &s.Port, &s.Username, &password, &s.Realm, &s.VerifySSL, &s.Node)
-
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
+	nodes, ok := clusterNodes(c, client)
+	if !ok {
 		return
 	}
 
-	if s.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port)
-		client := services.NewProxmoxClient(baseURL, s.Username, password, s.Realm, s.VerifySSL)
-
-		node := s.Node
-		if node == "" {
-			node = "pve" // Default fallback
-		}
-
-		lxcs, err := client.GetLXCs(node)
+	lxcs := []services.ProxmoxLXCInfo{}
+	for _, node := range nodes {
+		nodeLXCs, err := client.GetLXCs(node)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-
-		c.JSON(http.StatusOK, lxcs)
-		return
+		lxcs = append(lxcs, nodeLXCs...)
 	}
 
-	c.JSON(http.StatusBadRequest, gin.H{"error": "LXC not supported for this server type"})
+	c.JSON(http.StatusOK, lxcs)
 }
 
+// CreateServerLXC creates a container on node, by default the server's
+// configured node or else the cluster's first online node.
 func CreateServerLXC(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+	s, client, ok := proxmoxServer(c)
+	if !ok {
 		return
 	}
 
@@ -362,6 +350,7 @@ func CreateServerLXC(c *gin.Context) {
 		VMID       int               `json:"vmid" binding:"required"`
 		OSTemplate string            `json:"ostemplate" binding:"required"`
 		Storage    string            `json:"storage" binding:"required"`
+		Node       string            `json:"node"`
 		Params     map[string]string `json:"params"`
 	}
 
@@ -370,136 +359,59 @@ func CreateServerLXC(c *gin.Context) {
 		return
 	}
 
-	var s models.Server
-	var password string
-	err = database.DB.QueryRow(`
-		SELECT type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), 
-		       COALESCE(realm, ''), verify_ssl, COALESCE(node, '')
-		FROM servers WHERE id = ?
-	`, id).Scan(&s.Type, &s.IPAddress, &s.Port, &s.Username, &password, &s.Realm, &s.VerifySSL, &s.Node)
-
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
+	node, ok := targetNode(c, s, client, input.Node)
+	if !ok {
 		return
 	}
 
-	if s.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port)
-		client := services.NewProxmoxClient(baseURL, s.Username, password, s.Realm, s.VerifySSL)
-
-		node := s.Node
-		if node == "" {
-			node = "pve"
-		}
-
-		err := client.CreateLXC(node, input.VMID, input.OSTemplate, input.Storage, input.Params)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusCreated, gin.H{"message": "LXC creation initiated"})
+	upid, err := client.CreateLXC(node, input.VMID, input.OSTemplate, input.Storage, input.Params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusBadRequest, gin.H{"error": "LXC not supported for this server type"})
+	taskID := trackTask(c, s, upid, fmt.Sprintf("Container %d: create", input.VMID))
+	c.JSON(http.StatusCreated, gin.H{"message": "LXC creation initiated", "node": node, "upid": upid, "task_id": taskID})
 }
 
+// ManageServerLXC starts, stops or removes a container on whichever node of
+// the cluster it is on.
 func ManageServerLXC(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	vmid, verr := strconv.Atoi(c.Param("vmid"))
-	action := c.Param("action")
-
-	if err != nil || verr != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server or VM ID"})
+	s, client, ok := proxmoxServer(c)
+	if !ok {
 		return
 	}
-
-	var s models.Server
-	var password string
-	err = database.DB.QueryRow(`
-		SELECT type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), 
-		       COALESCE(realm, ''), verify_ssl, COALESCE(node, '')
-		FROM servers WHERE id = ?
-	`, id).Scan(&s.Type, &s.IPAddress, &s.Port, &s.Username, &password, &s.Realm, &s.VerifySSL, &s.Node)
-
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
+	vmid, ok := vmidParam(c)
+	if !ok {
 		return
 	}
 
-	if s.Type == "proxmox" {
-		baseURL := fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port)
-		client := services.NewProxmoxClient(baseURL, s.Username, password, s.Realm, s.VerifySSL)
-
-		node := s.Node
-		if node == "" {
-			node = "pve"
-		}
-
-		var err error
-		switch action {
-		case "start":
-			err = client.StartLXC(node, vmid)
-		case "stop":
-			err = client.StopLXC(node, vmid)
-		case "remove":
-			err = client.RemoveLXC(node, vmid)
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action"})
-			return
-		}
-
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("LXC %s initiated", action)})
+	action := c.Param("action")
+	if action != "start" && action != "stop" && action != "remove" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action"})
 		return
 	}
 
-	c.JSON(http.StatusBadRequest, gin.H{"error": "LXC not supported for this server type"})
-}
-
-func ConnectServerShell(c *gin.Context) {
-	log.Printf("[SHELL] Terminal connection request for server %s", c.Param("id"))
-
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		log.Printf("[SHELL] Invalid ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+	node, ok := guestNode(c, client, services.GuestLXC, vmid)
+	if !ok {
 		return
 	}
 
-	var s models.Server
-	var password string
-	err = database.DB.QueryRow(`
-		SELECT type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), 
-		       COALESCE(realm, ''), verify_ssl, COALESCE(node, '')
-		FROM servers WHERE id = ?
-	`, id).Scan(&s.Type, &s.IPAddress, &s.Port, &s.Username, &password, &s.Realm, &s.VerifySSL, &s.Node)
-
-	if err != nil {
-		log.Printf("[SHELL] Server lookup failed: %v", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
-		return
+	var upid string
+	var err error
+	switch action {
+	case "start":
+		upid, err = client.StartLXC(node, vmid)
+	case "stop":
+		upid, err = client.StopLXC(node, vmid)
+	case "remove":
+		upid, err = client.RemoveLXC(node, vmid)
 	}
-
-	if s.Type == "proxmox" {
-		node := s.Node
-		if node == "" {
-			node = "pve"
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"type":         "redirect",
-			"message":      "Access Proxmox Terminal directly",
-			"proxmox_url":  fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port),
-			"instructions": "Click the button below to open Proxmox's web interface. The terminal can be accessed from the 'Shell' option in the Proxmox web UI.",
-		})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusBadRequest, gin.H{"error": "Shell not supported for this server type"})
+	taskID := trackTask(c, s, upid, fmt.Sprintf("Container %d: %s", vmid, action))
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("LXC %s initiated", action), "upid": upid, "task_id": taskID})
 }
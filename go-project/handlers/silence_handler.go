@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/middleware"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type alertSilenceInput struct {
+	AlertRuleID      *int64     `json:"alert_rule_id"`
+	TargetID         *int64     `json:"target_id"`
+	TargetName       *string    `json:"target_name"`
+	Type             *string    `json:"type" binding:"omitempty,oneof=monitor infrastructure"`
+	Severity         *string    `json:"severity" binding:"omitempty,oneof=critical high medium low info"`
+	SuppressCreation bool       `json:"suppress_creation"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           time.Time  `json:"ends_at" binding:"required"`
+	Comment          string     `json:"comment" binding:"required"`
+}
+
+// validate checks the input and returns the silence window in UTC.
+func (input alertSilenceInput) validate() (time.Time, time.Time, string) {
+	if input.AlertRuleID == nil && input.TargetID == nil && input.TargetName == nil &&
+		input.Type == nil && input.Severity == nil {
+		return time.Time{}, time.Time{}, "At least one matcher is required"
+	}
+
+	startsAt := time.Now().UTC()
+	if input.StartsAt != nil {
+		startsAt = input.StartsAt.UTC()
+	}
+	endsAt := input.EndsAt.UTC()
+
+	if !endsAt.After(startsAt) {
+		return time.Time{}, time.Time{}, "ends_at must be after starts_at"
+	}
+
+	return startsAt, endsAt, ""
+}
+
+const alertSilenceColumns = `
+	id, alert_rule_id, target_id, target_name, type, severity, suppress_creation,
+	starts_at, ends_at, created_by, comment, created_at, updated_at
+`
+
+func scanAlertSilence(scanner interface{ Scan(...interface{}) error }) (models.AlertSilence, error) {
+	var s models.AlertSilence
+	var ruleID, targetID sql.NullInt64
+	var targetName, silenceType, severity sql.NullString
+
+	err := scanner.Scan(&s.ID, &ruleID, &targetID, &targetName, &silenceType, &severity, &s.SuppressCreation,
+		&s.StartsAt, &s.EndsAt, &s.CreatedBy, &s.Comment, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return s, err
+	}
+
+	if ruleID.Valid {
+		s.AlertRuleID = &ruleID.Int64
+	}
+	if targetID.Valid {
+		s.TargetID = &targetID.Int64
+	}
+	if targetName.Valid {
+		s.TargetName = &targetName.String
+	}
+	if silenceType.Valid {
+		s.Type = &silenceType.String
+	}
+	if severity.Valid {
+		sev := models.AlertSeverity(severity.String)
+		s.Severity = &sev
+	}
+
+	now := time.Now()
+	s.Active = !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+
+	return s, nil
+}
+
+func GetAlertSilences(c *gin.Context) {
+	query := "SELECT " + alertSilenceColumns + " FROM alert_silences"
+	args := []interface{}{}
+
+	if c.Query("active") == "true" {
+		now := time.Now().UTC()
+		query += " WHERE starts_at <= ? AND ends_at > ?"
+		args = append(args, now, now)
+	}
+
+	query += " ORDER BY ends_at DESC"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch silences"})
+		return
+	}
+	defer rows.Close()
+
+	silences := []models.AlertSilence{}
+	for rows.Next() {
+		s, err := scanAlertSilence(rows)
+		if err != nil {
+			continue
+		}
+		silences = append(silences, s)
+	}
+
+	c.JSON(http.StatusOK, silences)
+}
+
+func GetAlertSilence(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid silence ID"})
+		return
+	}
+
+	s, err := scanAlertSilence(database.DB.QueryRow("SELECT "+alertSilenceColumns+" FROM alert_silences WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Silence not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch silence"})
+		return
+	}
+
+	c.JSON(http.StatusOK, s)
+}
+
+func CreateAlertSilence(c *gin.Context) {
+	var input alertSilenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startsAt, endsAt, msg := input.validate()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	user, err := services.GetUserByID(middleware.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO alert_silences (alert_rule_id, target_id, target_name, type, severity, suppress_creation,
+		                            starts_at, ends_at, created_by, comment)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.AlertRuleID, input.TargetID, input.TargetName, input.Type, input.Severity, input.SuppressCreation,
+		startsAt, endsAt, user.Username, input.Comment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create silence"})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Silence created successfully"})
+}
+
+func UpdateAlertSilence(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid silence ID"})
+		return
+	}
+
+	var input alertSilenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startsAt, endsAt, msg := input.validate()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE alert_silences
+		SET alert_rule_id = ?, target_id = ?, target_name = ?, type = ?, severity = ?, suppress_creation = ?,
+		    starts_at = ?, ends_at = ?, comment = ?
+		WHERE id = ?
+	`, input.AlertRuleID, input.TargetID, input.TargetName, input.Type, input.Severity, input.SuppressCreation,
+		startsAt, endsAt, input.Comment, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update silence"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Silence not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Silence updated successfully"})
+}
+
+// ExpireAlertSilence ends a silence now while keeping it for the alerts it
+// already muted.
+func ExpireAlertSilence(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid silence ID"})
+		return
+	}
+
+	now := time.Now().UTC()
+	result, err := database.DB.Exec(`
+		UPDATE alert_silences SET ends_at = ? WHERE id = ? AND ends_at > ?
+	`, now, id, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to expire silence"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Active silence not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Silence expired successfully"})
+}
+
+func DeleteAlertSilence(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid silence ID"})
+		return
+	}
+
+	_, err = database.DB.Exec("DELETE FROM alert_silences WHERE id = ?", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete silence"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Silence deleted successfully"})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"fmt"
+	"go-project/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Snapshot handlers are shared by VMs and containers; guestType is
+// services.GuestQEMU or services.GuestLXC.
+
+func guestLabel(guestType string) string {
+	if guestType == services.GuestLXC {
+		return "Container"
+	}
+	return "VM"
+}
+
+// snapshotNameParam reads the :snapname parameter, writing the error response
+// and returning false when it is not a valid snapshot name.
+func snapshotNameParam(c *gin.Context) (string, bool) {
+	name := c.Param("snapname")
+	if !services.ValidSnapshotName(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot name"})
+		return "", false
+	}
+	return name, true
+}
+
+func GetSnapshots(guestType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, client, ok := proxmoxServer(c)
+		if !ok {
+			return
+		}
+		vmid, node, ok := guestParam(c, client, guestType)
+		if !ok {
+			return
+		}
+
+		snapshots, err := client.GetSnapshots(node, guestType, vmid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, snapshots)
+	}
+}
+
+// CreateSnapshot takes a snapshot with an optional description. For VMs,
+// vmstate also saves the RAM so a rollback resumes the running machine.
+func CreateSnapshot(guestType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, client, ok := proxmoxServer(c)
+		if !ok {
+			return
+		}
+		vmid, node, ok := guestParam(c, client, guestType)
+		if !ok {
+			return
+		}
+
+		var input struct {
+			Name        string `json:"name" binding:"required"`
+			Description string `json:"description"`
+			VMState     bool   `json:"vmstate"`
+		}
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !services.ValidSnapshotName(input.Name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Snapshot names must start with a letter and contain 2-40 letters, digits, '-' or '_'"})
+			return
+		}
+
+		upid, err := client.CreateSnapshot(node, guestType, vmid, input.Name, input.Description, input.VMState)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		auditServerAction(c, s.ID, "snapshot_create",
+			fmt.Sprintf("Snapshot %s of %s %d on node %s", input.Name, guestLabel(guestType), vmid, node))
+		taskID := trackTask(c, s, upid, fmt.Sprintf("%s %d: create snapshot %s", guestLabel(guestType), vmid, input.Name))
+		c.JSON(http.StatusCreated, gin.H{"message": "Snapshot creation initiated", "upid": upid, "task_id": taskID})
+	}
+}
+
+// UpdateSnapshot replaces a snapshot's description.
+func UpdateSnapshot(guestType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, client, ok := proxmoxServer(c)
+		if !ok {
+			return
+		}
+		vmid, node, ok := guestParam(c, client, guestType)
+		if !ok {
+			return
+		}
+		name, ok := snapshotNameParam(c)
+		if !ok {
+			return
+		}
+
+		var input struct {
+			Description string `json:"description"`
+		}
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := client.UpdateSnapshotDescription(node, guestType, vmid, name, input.Description); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Snapshot updated successfully"})
+	}
+}
+
+func RollbackSnapshot(guestType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, client, ok := proxmoxServer(c)
+		if !ok {
+			return
+		}
+		vmid, node, ok := guestParam(c, client, guestType)
+		if !ok {
+			return
+		}
+		name, ok := snapshotNameParam(c)
+		if !ok {
+			return
+		}
+
+		upid, err := client.RollbackSnapshot(node, guestType, vmid, name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		auditServerAction(c, s.ID, "snapshot_rollback",
+			fmt.Sprintf("Snapshot %s of %s %d on node %s", name, guestLabel(guestType), vmid, node))
+		taskID := trackTask(c, s, upid, fmt.Sprintf("%s %d: roll back to snapshot %s", guestLabel(guestType), vmid, name))
+		c.JSON(http.StatusOK, gin.H{"message": "Snapshot rollback initiated", "upid": upid, "task_id": taskID})
+	}
+}
+
+func DeleteSnapshot(guestType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, client, ok := proxmoxServer(c)
+		if !ok {
+			return
+		}
+		vmid, node, ok := guestParam(c, client, guestType)
+		if !ok {
+			return
+		}
+		name, ok := snapshotNameParam(c)
+		if !ok {
+			return
+		}
+
+		upid, err := client.DeleteSnapshot(node, guestType, vmid, name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		auditServerAction(c, s.ID, "snapshot_delete",
+			fmt.Sprintf("Snapshot %s of %s %d on node %s", name, guestLabel(guestType), vmid, node))
+		taskID := trackTask(c, s, upid, fmt.Sprintf("%s %d: delete snapshot %s", guestLabel(guestType), vmid, name))
+		c.JSON(http.StatusOK, gin.H{"message": "Snapshot deletion initiated", "upid": upid, "task_id": taskID})
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"go-project/middleware"
+	"go-project/models"
+	"go-project/services"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trackTask records a task started by the request's user so its outcome is
+// polled, returning the tracked task's ID, or nil when upid is not a task.
+func trackTask(c *gin.Context, s *models.Server, upid, description string) *int64 {
+	if upid == "" {
+		return nil
+	}
+
+	userID := middleware.GetUserID(c)
+	id, err := services.TrackTask(s.ID, upid, &userID, description)
+	if err != nil {
+		log.Printf("[TASKS] Failed to track task %s: %v", upid, err)
+		return nil
+	}
+	return &id
+}
+
+var taskStatuses = map[string]bool{
+	string(models.TaskStatusRunning): true,
+	string(models.TaskStatusOK):      true,
+	string(models.TaskStatusFailed):  true,
+	string(models.TaskStatusUnknown): true,
+}
+
+// GetTasks lists tracked tasks, newest first, filtered by ?server_id=,
+// ?vmid=, ?status= and ?mine=true.
+func GetTasks(c *gin.Context) {
+	var filter services.TaskFilter
+
+	if v := c.Query("server_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+			return
+		}
+		filter.ServerID = id
+	}
+	if v := c.Query("vmid"); v != "" {
+		vmid, err := strconv.Atoi(v)
+		if err != nil || vmid <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid VM ID"})
+			return
+		}
+		filter.VMID = vmid
+	}
+	if status := c.Query("status"); status != "" {
+		if !taskStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be running, ok, failed or unknown"})
+			return
+		}
+		filter.Status = status
+	}
+	if c.Query("mine") == "true" {
+		filter.UserID = middleware.GetUserID(c)
+	}
+
+	filter.Limit = 50
+	if v := c.Query("limit"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil && limit > 0 && limit <= 500 {
+			filter.Limit = limit
+		}
+	}
+
+	tasks, err := services.ListTasks(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// taskParam loads the task named by the :id parameter, writing the error
+// response and returning false when it does not exist.
+func taskParam(c *gin.Context) (*models.ProxmoxTask, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return nil, false
+	}
+
+	task, err := services.GetTask(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+		return nil, false
+	}
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, false
+	}
+	return task, true
+}
+
+func GetTask(c *gin.Context) {
+	task, ok := taskParam(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+const (
+	// taskLogPageSize is how many log lines are fetched from the node at once.
+	taskLogPageSize = 500
+	// taskLogFollowInterval is how often a followed log is checked for new
+	// lines.
+	taskLogFollowInterval = time.Second
+)
+
+// GetTaskLog returns a task's log lines from ?start=. Clients accepting
+// text/event-stream instead follow the log: each line is sent as a "line"
+// event as it is written, and an "end" event with the task follows once it
+// has finished.
+func GetTaskLog(c *gin.Context) {
+	task, ok := taskParam(c)
+	if !ok {
+		return
+	}
+
+	s, err := services.GetServerByID(task.ServerID)
+	if err != nil || s == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
+		return
+	}
+	client := services.ProxmoxClientFor(*s)
+
+	start, _ := strconv.Atoi(c.Query("start"))
+	if start < 0 {
+		start = 0
+	}
+
+	if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		lines, err := client.GetTaskLog(task.Node, task.UPID, start, taskLogPageSize)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if lines == nil {
+			lines = []services.ProxmoxTaskLogLine{}
+		}
+		c.JSON(http.StatusOK, lines)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(taskLogFollowInterval)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		// The status is read before the log so lines written just before the
+		// task finished are not missed.
+		current, err := services.GetTask(task.ID)
+		if err != nil || current == nil {
+			c.SSEvent("error", gin.H{"error": "Task not found"})
+			return false
+		}
+
+		lines, err := client.GetTaskLog(task.Node, task.UPID, start, taskLogPageSize)
+		if err != nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			return false
+		}
+		for _, line := range lines {
+			c.SSEvent("line", line)
+		}
+		start += len(lines)
+
+		if len(lines) == 0 && current.Status != models.TaskStatusRunning {
+			c.SSEvent("end", current)
+			return false
+		}
+		if len(lines) == taskLogPageSize {
+			return true
+		}
+
+		select {
+		case <-ticker.C:
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"fmt"
+	"go-project/middleware"
+	"go-project/models"
+	"go-project/services"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// terminalSession describes the session opened with ticket, for its
+// recording.
+func terminalSession(c *gin.Context, s *models.Server, ticket *services.TerminalClaims) models.TerminalSession {
+	serverID, userID := s.ID, ticket.UserID
+	return models.TerminalSession{
+		ServerID:   &serverID,
+		ServerName: s.Name,
+		UserID:     &userID,
+		Node:       ticket.Node,
+		GuestType:  ticket.GuestType,
+		VMID:       ticket.VMID,
+		ClientIP:   c.ClientIP(),
+	}
+}
+
+// issueTerminalTicket responds with a single-use ticket for target.
+func issueTerminalTicket(c *gin.Context, target services.TerminalTarget) {
+	ticket, expiresAt, err := services.GenerateTerminalTicket(middleware.GetUserID(c), middleware.GetUserRole(c), target)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue terminal ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket, "node": target.Node, "expires_at": expiresAt})
+}
+
+// CreateServerShellTicket issues a single-use ticket to open a shell on
+// ?node= of the server, or its default node, with ConnectServerShell.
+func CreateServerShellTicket(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	node, ok := targetNode(c, s, client, c.Query("node"))
+	if !ok {
+		return
+	}
+
+	issueTerminalTicket(c, services.TerminalTarget{ServerID: s.ID, Node: node})
+}
+
+// ConnectServerShell opens a shell on the node named by the request's terminal
+// ticket and relays it over a WebSocket.
+func ConnectServerShell(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+
+	// Console tickets must not open a shell on the node.
+	ticket := middleware.GetTerminalTicket(c)
+	if ticket.ServerID != s.ID || ticket.VMID != 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Terminal ticket is not for this shell"})
+		return
+	}
+
+	term, err := client.GetNodeTerminal(ticket.Node)
+	if err != nil {
+		log.Printf("[SHELL] Failed to open terminal on node %s of server %d: %v", ticket.Node, s.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditServerAction(c, s.ID, "server_shell", fmt.Sprintf("Shell on node %s", ticket.Node))
+	services.ProxyTerminal(c.Writer, c.Request, client, term, terminalSession(c, s, ticket))
+}
+
+// CreateGuestConsoleTicket issues a single-use ticket to open the console of
+// a running container, or the serial console of a running VM, with
+// ConnectGuestConsole.
+func CreateGuestConsoleTicket(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	vmid, ok := vmidParam(c)
+	if !ok {
+		return
+	}
+
+	guest, err := client.FindGuest(vmid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if guest == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Guest %d not found", vmid)})
+		return
+	}
+	if guest.Status != "running" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %d is not running", guestLabel(guest.Type), vmid)})
+		return
+	}
+
+	issueTerminalTicket(c, services.TerminalTarget{
+		ServerID: s.ID, Node: guest.Node, GuestType: guest.Type, VMID: vmid,
+	})
+}
+
+// ConnectGuestConsole opens the console of the guest named by the request's
+// terminal ticket and relays it over a WebSocket. Unlike the node shell, it
+// gives no access to the node itself.
+func ConnectGuestConsole(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	vmid, ok := vmidParam(c)
+	if !ok {
+		return
+	}
+
+	ticket := middleware.GetTerminalTicket(c)
+	if ticket.ServerID != s.ID || ticket.VMID != vmid {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Terminal ticket is not for this console"})
+		return
+	}
+
+	term, err := client.GetGuestTerminal(ticket.Node, ticket.GuestType, vmid)
+	if err != nil {
+		log.Printf("[SHELL] Failed to open console of %s %d on server %d: %v", ticket.GuestType, vmid, s.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditServerAction(c, s.ID, "guest_console",
+		fmt.Sprintf("%s %d on node %s", guestLabel(ticket.GuestType), vmid, ticket.Node))
+	services.ProxyTerminal(c.Writer, c.Request, client, term, terminalSession(c, s, ticket))
+}
+
+// GetTerminalSessions lists recorded terminal sessions, newest first,
+// filtered by ?server_id=, ?user_id= and ?vmid=.
+func GetTerminalSessions(c *gin.Context) {
+	var filter services.TerminalSessionFilter
+
+	if v := c.Query("server_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+			return
+		}
+		filter.ServerID = id
+	}
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = id
+	}
+	if v := c.Query("vmid"); v != "" {
+		vmid, err := strconv.Atoi(v)
+		if err != nil || vmid <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid VM ID"})
+			return
+		}
+		filter.VMID = vmid
+	}
+
+	filter.Limit = 50
+	if v := c.Query("limit"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil && limit > 0 && limit <= 500 {
+			filter.Limit = limit
+		}
+	}
+
+	sessions, err := services.ListTerminalSessions(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch terminal sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// terminalSessionParam loads the session named by the :id parameter and the
+// path of its recording, writing the error response and returning false when
+// it does not exist.
+func terminalSessionParam(c *gin.Context) (*models.TerminalSession, string, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return nil, "", false
+	}
+
+	session, recording, err := services.GetTerminalSession(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch terminal session"})
+		return nil, "", false
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Terminal session not found"})
+		return nil, "", false
+	}
+	return session, recording, true
+}
+
+func GetTerminalSession(c *gin.Context) {
+	session, _, ok := terminalSessionParam(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// GetTerminalSessionRecording downloads a session's asciicast v2 recording.
+// The recording of an open session holds its output so far.
+func GetTerminalSessionRecording(c *gin.Context) {
+	session, recording, ok := terminalSessionParam(c)
+	if !ok {
+		return
+	}
+	if recording == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recording not found"})
+		return
+	}
+	if _, err := os.Stat(recording); err != nil {
+		log.Printf("[TERMINAL] Recording of session %d is missing: %v", session.ID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recording not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	services.CreateAuditLog(&userID, "terminal_recording_download", "terminal_sessions", &session.ID, "", c.ClientIP())
+
+	c.Header("Content-Type", "application/x-asciicast")
+	c.FileAttachment(recording, fmt.Sprintf("terminal-session-%d.cast", session.ID))
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"fmt"
+	"go-project/middleware"
+	"go-project/models"
+	"go-project/services"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// proxmoxServer loads the Proxmox server named by the :id parameter with its
+// shared client. It writes the error response and returns false when the ID
+// is invalid or the server does not exist or is not a Proxmox server.
+func proxmoxServer(c *gin.Context) (*models.Server, *services.ProxmoxClient, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server ID"})
+		return nil, nil, false
+	}
+
+	s, err := services.GetServerByID(id)
+	if err != nil || s == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Server not found"})
+		return nil, nil, false
+	}
+
+	if s.Type != models.ServerTypeProxmox {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not supported for this server type"})
+		return nil, nil, false
+	}
+
+	return s, services.ProxmoxClientFor(*s), true
+}
+
+// vmidParam parses the :vmid parameter, writing the error response and
+// returning false when it is invalid.
+func vmidParam(c *gin.Context) (int, bool) {
+	vmid, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil || vmid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid VM ID"})
+		return 0, false
+	}
+	return vmid, true
+}
+
+// guestParam parses the :vmid parameter and finds the node the guest runs on,
+// writing the error response and returning false when the ID is invalid or
+// no guest of guestType has it.
+func guestParam(c *gin.Context, client *services.ProxmoxClient, guestType string) (int, string, bool) {
+	vmid, ok := vmidParam(c)
+	if !ok {
+		return 0, "", false
+	}
+	node, ok := guestNode(c, client, guestType, vmid)
+	return vmid, node, ok
+}
+
+// guestNode finds the node of the cluster a guest runs on. guestType may be
+// empty to accept either a VM or a container.
+func guestNode(c *gin.Context, client *services.ProxmoxClient, guestType string, vmid int) (string, bool) {
+	guest, err := client.FindGuest(vmid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	if guest == nil || (guestType != "" && guest.Type != guestType) {
+		label := "Guest"
+		if guestType != "" {
+			label = guestLabel(guestType)
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("%s %d not found", label, vmid)})
+		return "", false
+	}
+	return guest.Node, true
+}
+
+// clusterNodes returns the online nodes of the server's cluster, or only the
+// one named by ?node=. It writes the error response and returns false when
+// the nodes cannot be listed or ?node= is not an online node.
+func clusterNodes(c *gin.Context, client *services.ProxmoxClient) ([]string, bool) {
+	nodes, err := client.GetOnlineNodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if requested := c.Query("node"); requested != "" {
+		if !slices.Contains(nodes, requested) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Node %s not found or offline", requested)})
+			return nil, false
+		}
+		return []string{requested}, true
+	}
+	return nodes, true
+}
+
+// targetNode picks the node to create a guest or open a shell on: the
+// requested one, which must be online, else the server's configured node,
+// else the cluster's first online node.
+func targetNode(c *gin.Context, s *models.Server, client *services.ProxmoxClient, requested string) (string, bool) {
+	if requested == "" && s.Node != "" {
+		return s.Node, true
+	}
+
+	nodes, err := client.GetOnlineNodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	if requested != "" {
+		if !slices.Contains(nodes, requested) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Node %s not found or offline", requested)})
+			return "", false
+		}
+		return requested, true
+	}
+	if len(nodes) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No online nodes in the cluster"})
+		return "", false
+	}
+	return nodes[0], true
+}
+
+// auditServerAction records a change made to a server's guests.
+func auditServerAction(c *gin.Context, serverID int64, action, details string) {
+	userID := middleware.GetUserID(c)
+	services.CreateAuditLog(&userID, action, "servers", &serverID, details, c.ClientIP())
+}
+
+// GetServerVMs lists the VMs on every online node of the cluster, or only
+// on ?node=.
+func GetServerVMs(c *gin.Context) {
+	_, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	nodes, ok := clusterNodes(c, client)
+	if !ok {
+		return
+	}
+
+	vms := []services.ProxmoxVMInfo{}
+	for _, node := range nodes {
+		nodeVMs, err := client.GetVMs(node)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		vms = append(vms, nodeVMs...)
+	}
+
+	c.JSON(http.StatusOK, vms)
+}
+
+// ManageServerVM runs a power action (start, shutdown, stop, reboot, suspend
+// or resume) on a VM.
+func ManageServerVM(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	vmid, ok := vmidParam(c)
+	if !ok {
+		return
+	}
+
+	action := c.Param("action")
+	if !services.VMActions[action] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action"})
+		return
+	}
+
+	node, ok := guestNode(c, client, services.GuestQEMU, vmid)
+	if !ok {
+		return
+	}
+	upid, err := client.VMAction(node, vmid, action)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditServerAction(c, s.ID, "vm_"+action, fmt.Sprintf("VM %d on node %s", vmid, node))
+	taskID := trackTask(c, s, upid, fmt.Sprintf("VM %d: %s", vmid, action))
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("VM %s initiated", action), "upid": upid, "task_id": taskID})
+}
+
+// DeleteServerVM destroys a VM. With ?purge=true it is also removed from
+// backup jobs, replication and HA.
+func DeleteServerVM(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	vmid, ok := vmidParam(c)
+	if !ok {
+		return
+	}
+
+	node, ok := guestNode(c, client, services.GuestQEMU, vmid)
+	if !ok {
+		return
+	}
+	upid, err := client.DeleteVM(node, vmid, c.Query("purge") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditServerAction(c, s.ID, "vm_delete", fmt.Sprintf("VM %d on node %s", vmid, node))
+	taskID := trackTask(c, s, upid, fmt.Sprintf("VM %d: delete", vmid))
+	c.JSON(http.StatusOK, gin.H{"message": "VM deletion initiated", "upid": upid, "task_id": taskID})
+}
+
+func GetServerVMConfig(c *gin.Context) {
+	_, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	vmid, node, ok := guestParam(c, client, services.GuestQEMU)
+	if !ok {
+		return
+	}
+
+	config, err := client.GetVMConfig(node, vmid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// diskSize matches sizes accepted by a disk resize, e.g. 32G or +10G.
+var diskSize = regexp.MustCompile(`^\+?\d+(\.\d+)?[KMGT]?$`)
+
+// UpdateServerVMConfig changes a VM's CPU, memory and disks. Omitted fields
+// are left unchanged. Disks adds or replaces drives (e.g. "scsi1":
+// "local-lvm:32" for a new 32 GiB disk), delete detaches drives and resize
+// grows them (e.g. "scsi0": "+10G").
+func UpdateServerVMConfig(c *gin.Context) {
+	s, client, ok := proxmoxServer(c)
+	if !ok {
+		return
+	}
+	vmid, ok := vmidParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Cores   *int              `json:"cores"`
+		Sockets *int              `json:"sockets"`
+		CPU     *string           `json:"cpu"`
+		Memory  *int              `json:"memory"`
+		Balloon *int              `json:"balloon"`
+		Disks   map[string]string `json:"disks"`
+		Delete  []string          `json:"delete"`
+		Resize  map[string]string `json:"resize"`
+		Digest  string            `json:"digest"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := url.Values{}
+	if input.Cores != nil {
+		if *input.Cores < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cores must be at least 1"})
+			return
+		}
+		params.Set("cores", strconv.Itoa(*input.Cores))
+	}
+	if input.Sockets != nil {
+		if *input.Sockets < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sockets must be at least 1"})
+			return
+		}
+		params.Set("sockets", strconv.Itoa(*input.Sockets))
+	}
+	if input.CPU != nil {
+		params.Set("cpu", *input.CPU)
+	}
+	if input.Memory != nil {
+		if *input.Memory < 16 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "memory must be at least 16 MiB"})
+			return
+		}
+		params.Set("memory", strconv.Itoa(*input.Memory))
+	}
+	if input.Balloon != nil {
+		if *input.Balloon < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "balloon must not be negative"})
+			return
+		}
+		params.Set("balloon", strconv.Itoa(*input.Balloon))
+	}
+	for key, volume := range input.Disks {
+		if !services.IsVMDiskKey(key) || volume == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid disk %q", key)})
+			return
+		}
+		params.Set(key, volume)
+	}
+	for _, key := range input.Delete {
+		if !services.IsVMDiskKey(key) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid disk %q", key)})
+			return
+		}
+	}
+	if len(input.Delete) > 0 {
+		params.Set("delete", strings.Join(input.Delete, ","))
+	}
+	for key, size := range input.Resize {
+		if !services.IsVMDiskKey(key) || !diskSize.MatchString(size) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid resize of disk %q to %q", key, size)})
+			return
+		}
+	}
+	if len(params) == 0 && len(input.Resize) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No changes given"})
+		return
+	}
+
+	node, ok := guestNode(c, client, services.GuestQEMU, vmid)
+	if !ok {
+		return
+	}
+	upids := []string{}
+
+	if len(params) > 0 {
+		if input.Digest != "" {
+			params.Set("digest", input.Digest)
+		}
+
+		upid, err := client.UpdateVMConfig(node, vmid, params)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if upid != "" {
+			upids = append(upids, upid)
+			trackTask(c, s, upid, fmt.Sprintf("VM %d: update configuration", vmid))
+		}
+	}
+
+	for disk, size := range input.Resize {
+		upid, err := client.ResizeVMDisk(node, vmid, disk, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "upids": upids})
+			return
+		}
+		if upid != "" {
+			upids = append(upids, upid)
+			trackTask(c, s, upid, fmt.Sprintf("VM %d: resize %s to %s", vmid, disk, size))
+		}
+	}
+
+	auditServerAction(c, s.ID, "vm_config", fmt.Sprintf("VM %d on node %s", vmid, node))
+	c.JSON(http.StatusOK, gin.H{"message": "VM configuration update initiated", "upids": upids})
+}
@@ -32,11 +32,33 @@ func main() {
 	alertChecker.Start()
 	defer alertChecker.Stop()
 
+	escalationService := services.GetEscalationService()
+	escalationService.Start()
+	defer escalationService.Stop()
+
+	licenseAlertService := services.GetLicenseAlertService()
+	licenseAlertService.Start()
+	defer licenseAlertService.Stop()
+
+	storageCollector := services.GetStorageCollector()
+	storageCollector.Start()
+	defer storageCollector.Stop()
+
+	inventorySyncer := services.GetInventorySyncer()
+	inventorySyncer.Start()
+	defer inventorySyncer.Stop()
+
+	metricsCollector := services.GetMetricsCollector()
+	metricsCollector.Start()
+	defer metricsCollector.Stop()
+
+	taskPoller := services.GetTaskPoller()
+	taskPoller.Start()
+	defer taskPoller.Stop()
+
 	r := gin.Default()
 	r.Use(corsMiddleware())
 
-	r.GET("/api/v1/servers/:id/shell", handlers.ConnectServerShell)
-
 	auth := r.Group("/api/v1/auth")
 	{
 		auth.POST("/login", handlers.Login)
@@ -44,6 +66,33 @@ func main() {
 		auth.POST("/logout", handlers.Logout)
 		auth.POST("/change-password", middleware.AuthRequired(), handlers.ChangePassword)
 		auth.GET("/me", middleware.AuthRequired(), handlers.GetCurrentUser)
+		auth.POST("/stream-ticket", middleware.AuthRequired(), handlers.CreateStreamTicket)
+	}
+
+	ingest := r.Group("/api/v1/ingest")
+	ingest.Use(middleware.IngestTokenRequired())
+	{
+		ingest.POST("/alertmanager", handlers.IngestAlertmanager)
+		ingest.POST("/alerts", handlers.IngestAlerts)
+	}
+
+	// Browser WebSockets cannot send headers, so terminals are authenticated
+	// with a single-use ticket from the API in place of the access token.
+	terminal := r.Group("/api/v1")
+	terminal.Use(middleware.TerminalTicketRequired())
+	{
+		terminal.GET("/servers/:id/shell", middleware.RequirePermission("servers", "shell"), handlers.ConnectServerShell)
+		terminal.GET("/servers/:id/guests/:vmid/console", middleware.RequirePermission("guests", "console"), handlers.ConnectGuestConsole)
+	}
+
+	// EventSource cannot send headers either, so browsers open event and
+	// task log streams with a single-use ticket from /auth/stream-ticket.
+	streams := r.Group("/api/v1")
+	streams.Use(middleware.StreamTicketRequired())
+	{
+		streams.GET("/events", handlers.StreamEvents)
+		streams.GET("/events/ws", handlers.StreamEventsWebSocket)
+		streams.GET("/tasks/:id/log", handlers.GetTaskLog)
 	}
 
 	api := r.Group("/api/v1")
@@ -59,10 +108,57 @@ func main() {
 			servers.PUT("/:id", middleware.RequirePermission("servers", "update"), handlers.UpdateServer)
 			servers.DELETE("/:id", middleware.RequirePermission("servers", "delete"), handlers.DeleteServer)
 			servers.POST("/:id/test", handlers.TestServerConnection)
+			servers.POST("/:id/shell/ticket", middleware.RequirePermission("servers", "shell"), handlers.CreateServerShellTicket)
 			servers.GET("/:id/logs", handlers.GetServerLogs)
+			servers.GET("/:id/guests", handlers.GetServerGuests)
+			servers.POST("/:id/guests/:vmid/console/ticket", middleware.RequirePermission("guests", "console"), handlers.CreateGuestConsoleTicket)
 			servers.GET("/:id/lxcs", handlers.GetServerLXCs)
 			servers.POST("/:id/lxcs", handlers.CreateServerLXC)
 			servers.POST("/:id/lxcs/:vmid/:action", handlers.ManageServerLXC)
+			servers.GET("/:id/lxcs/:vmid/snapshots", middleware.RequirePermission("snapshots", "read"), handlers.GetSnapshots(services.GuestLXC))
+			servers.POST("/:id/lxcs/:vmid/snapshots", middleware.RequirePermission("snapshots", "create"), handlers.CreateSnapshot(services.GuestLXC))
+			servers.PUT("/:id/lxcs/:vmid/snapshots/:snapname", middleware.RequirePermission("snapshots", "update"), handlers.UpdateSnapshot(services.GuestLXC))
+			servers.POST("/:id/lxcs/:vmid/snapshots/:snapname/rollback", middleware.RequirePermission("snapshots", "rollback"), handlers.RollbackSnapshot(services.GuestLXC))
+			servers.DELETE("/:id/lxcs/:vmid/snapshots/:snapname", middleware.RequirePermission("snapshots", "delete"), handlers.DeleteSnapshot(services.GuestLXC))
+
+			servers.GET("/:id/backups", middleware.RequirePermission("backups", "read"), handlers.GetServerBackups)
+			servers.POST("/:id/backups", middleware.RequirePermission("backups", "create"), handlers.CreateServerBackup)
+			servers.GET("/:id/backups/storages", middleware.RequirePermission("backups", "read"), handlers.GetServerBackupStorages)
+			servers.POST("/:id/backups/restore", middleware.RequirePermission("backups", "restore"), handlers.RestoreServerBackup)
+			servers.GET("/:id/backup-jobs", middleware.RequirePermission("backups", "read"), handlers.GetServerBackupJobs)
+
+			vms := servers.Group("/:id/vms")
+			{
+				vms.GET("", middleware.RequirePermission("vms", "read"), handlers.GetServerVMs)
+				vms.GET("/:vmid/config", middleware.RequirePermission("vms", "read"), handlers.GetServerVMConfig)
+				vms.PUT("/:vmid/config", middleware.RequirePermission("vms", "update"), handlers.UpdateServerVMConfig)
+				vms.POST("/:vmid/:action", middleware.RequirePermission("vms", "manage"), handlers.ManageServerVM)
+				vms.DELETE("/:vmid", middleware.RequirePermission("vms", "delete"), handlers.DeleteServerVM)
+				vms.GET("/:vmid/snapshots", middleware.RequirePermission("snapshots", "read"), handlers.GetSnapshots(services.GuestQEMU))
+				vms.POST("/:vmid/snapshots", middleware.RequirePermission("snapshots", "create"), handlers.CreateSnapshot(services.GuestQEMU))
+				vms.PUT("/:vmid/snapshots/:snapname", middleware.RequirePermission("snapshots", "update"), handlers.UpdateSnapshot(services.GuestQEMU))
+				vms.POST("/:vmid/snapshots/:snapname/rollback", middleware.RequirePermission("snapshots", "rollback"), handlers.RollbackSnapshot(services.GuestQEMU))
+				vms.DELETE("/:vmid/snapshots/:snapname", middleware.RequirePermission("snapshots", "delete"), handlers.DeleteSnapshot(services.GuestQEMU))
+			}
+		}
+
+		terminalSessions := api.Group("/terminal-sessions")
+		{
+			terminalSessions.GET("", middleware.RequirePermission("terminal_sessions", "read"), handlers.GetTerminalSessions)
+			terminalSessions.GET("/:id", middleware.RequirePermission("terminal_sessions", "read"), handlers.GetTerminalSession)
+			terminalSessions.GET("/:id/recording", middleware.RequirePermission("terminal_sessions", "read"), handlers.GetTerminalSessionRecording)
+		}
+
+		tasks := api.Group("/tasks")
+		{
+			tasks.GET("", handlers.GetTasks)
+			tasks.GET("/:id", handlers.GetTask)
+		}
+
+		backups := api.Group("/backups")
+		{
+			backups.GET("/report", middleware.RequirePermission("backups", "read"), handlers.GetBackupReport)
+			backups.POST("/report/evidence", middleware.RequirePermission("compliance", "update"), handlers.AttachBackupEvidence)
 		}
 
 		licenses := api.Group("/licenses")
@@ -73,6 +169,8 @@ func main() {
 			licenses.GET("/utilization", handlers.GetUtilizationReport)
 			licenses.GET("/vendor-spend", handlers.GetVendorSpendReport)
 			licenses.GET("/expiring-report", handlers.GetExpiringLicensesReport)
+			licenses.GET("/alerts", handlers.GetLicenseAlerts)
+			licenses.POST("/alerts/:id/acknowledge", middleware.RequirePermission("licenses", "update"), handlers.AcknowledgeLicenseAlert)
 			licenses.GET("/:id", handlers.GetLicense)
 			licenses.POST("", middleware.RequirePermission("licenses", "create"), handlers.CreateLicense)
 			licenses.PUT("/:id", middleware.RequirePermission("licenses", "update"), handlers.UpdateLicense)
@@ -123,16 +221,69 @@ func main() {
 		infrastructure := api.Group("/infrastructure")
 		{
 			infrastructure.GET("/nodes", handlers.GetInfrastructureNodes)
+			infrastructure.GET("/storage", handlers.GetInfrastructureStorage)
+			infrastructure.GET("/storage/history", handlers.GetInfrastructureStorageHistory)
+			infrastructure.GET("/metrics", handlers.GetInfrastructureMetrics)
+			infrastructure.GET("/inventory/nodes", handlers.GetInventoryNodes)
+			infrastructure.GET("/inventory/guests", handlers.GetInventoryGuests)
+			infrastructure.GET("/inventory/events", handlers.GetInventoryEvents)
 		}
 
 		alerts := api.Group("/alerts")
 		{
 			alerts.GET("/rules", handlers.GetAlertRules)
 			alerts.POST("/rules", middleware.RequirePermission("alerts", "create"), handlers.CreateAlertRule)
+			alerts.PUT("/rules/:id", middleware.RequirePermission("alerts", "update"), handlers.UpdateAlertRule)
+			alerts.POST("/rules/:id/enable", middleware.RequirePermission("alerts", "update"), handlers.EnableAlertRule)
+			alerts.POST("/rules/:id/disable", middleware.RequirePermission("alerts", "update"), handlers.DisableAlertRule)
+			alerts.POST("/rules/dry-run", handlers.DryRunAlertRule)
+			alerts.POST("/rules/:id/dry-run", handlers.DryRunSavedAlertRule)
 			alerts.DELETE("/rules/:id", middleware.RequirePermission("alerts", "delete"), handlers.DeleteAlertRule)
 			alerts.GET("", handlers.GetAlerts)
+			alerts.GET("/analytics", handlers.GetAlertAnalytics)
 			alerts.POST("/:id/acknowledge", handlers.AcknowledgeAlert)
 			alerts.POST("/:id/resolve", handlers.ResolveAlert)
+			alerts.GET("/:id/escalations", handlers.GetAlertEscalations)
+			alerts.POST("/:id/assign", handlers.AssignAlert)
+			alerts.GET("/:id/comments", handlers.GetAlertComments)
+			alerts.POST("/:id/comments", handlers.CreateAlertComment)
+			alerts.GET("/:id/activity", handlers.GetAlertActivity)
+			alerts.GET("/silences", handlers.GetAlertSilences)
+			alerts.GET("/silences/:id", handlers.GetAlertSilence)
+			alerts.POST("/silences", middleware.RequirePermission("alerts", "create"), handlers.CreateAlertSilence)
+			alerts.PUT("/silences/:id", middleware.RequirePermission("alerts", "update"), handlers.UpdateAlertSilence)
+			alerts.POST("/silences/:id/expire", middleware.RequirePermission("alerts", "update"), handlers.ExpireAlertSilence)
+			alerts.DELETE("/silences/:id", middleware.RequirePermission("alerts", "delete"), handlers.DeleteAlertSilence)
+		}
+
+		escalationPolicies := api.Group("/escalation-policies")
+		{
+			escalationPolicies.GET("", handlers.GetEscalationPolicies)
+			escalationPolicies.GET("/:id", handlers.GetEscalationPolicy)
+			escalationPolicies.POST("", middleware.RequirePermission("alerts", "create"), handlers.CreateEscalationPolicy)
+			escalationPolicies.PUT("/:id", middleware.RequirePermission("alerts", "update"), handlers.UpdateEscalationPolicy)
+			escalationPolicies.DELETE("/:id", middleware.RequirePermission("alerts", "delete"), handlers.DeleteEscalationPolicy)
+		}
+
+		oncall := api.Group("/oncall")
+		{
+			oncall.GET("/schedules", handlers.GetOnCallSchedules)
+			oncall.GET("/schedules/:id", handlers.GetOnCallSchedule)
+			oncall.GET("/schedules/:id/current", handlers.GetCurrentOnCall)
+			oncall.POST("/schedules", middleware.RequirePermission("alerts", "create"), handlers.CreateOnCallSchedule)
+			oncall.PUT("/schedules/:id", middleware.RequirePermission("alerts", "update"), handlers.UpdateOnCallSchedule)
+			oncall.DELETE("/schedules/:id", middleware.RequirePermission("alerts", "delete"), handlers.DeleteOnCallSchedule)
+			oncall.POST("/schedules/:id/overrides", middleware.RequirePermission("alerts", "update"), handlers.CreateOnCallOverride)
+			oncall.DELETE("/overrides/:id", middleware.RequirePermission("alerts", "update"), handlers.DeleteOnCallOverride)
+		}
+
+		notificationChannels := api.Group("/notification-channels")
+		{
+			notificationChannels.GET("", handlers.GetNotificationChannels)
+			notificationChannels.POST("", middleware.RequirePermission("alerts", "create"), handlers.CreateNotificationChannel)
+			notificationChannels.PUT("/:id", middleware.RequirePermission("alerts", "update"), handlers.UpdateNotificationChannel)
+			notificationChannels.DELETE("/:id", middleware.RequirePermission("alerts", "delete"), handlers.DeleteNotificationChannel)
+			notificationChannels.POST("/:id/test", middleware.RequirePermission("alerts", "update"), handlers.TestNotificationChannel)
 		}
 	}
 
@@ -11,33 +11,100 @@ import (
 
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		if !authenticateBearer(c) {
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
+
+// authenticateBearer validates the access token in the Authorization header
+// and sets the user of the request, or responds 401 and reports false.
+func authenticateBearer(c *gin.Context) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		return false
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		return false
+	}
+
+	claims, err := services.ValidateToken(parts[1])
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return false
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("userRole", claims.Role)
+	return true
+}
+
+// StreamTicketRequired authenticates event and task log streams with the
+// single-use ticket in ?ticket=, since EventSource and browser WebSockets
+// cannot set headers. Clients that can still use the Authorization header.
+func StreamTicketRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			if !authenticateBearer(c) {
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		claims, err := services.RedeemStreamTicket(ticket)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or used stream ticket"})
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", claims.UserID)
+		c.Set("userRole", claims.Role)
+		c.Next()
+	}
+}
+
+// TerminalTicketRequired authenticates terminal WebSockets with the
+// single-use ticket in ?ticket=, in place of the access token.
+func TerminalTicketRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Terminal ticket required"})
 			c.Abort()
 			return
 		}
 
-		claims, err := services.ValidateToken(parts[1])
+		claims, err := services.RedeemTerminalTicket(ticket)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or used terminal ticket"})
 			c.Abort()
 			return
 		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("userRole", claims.Role)
+		c.Set("terminalTicket", claims)
 		c.Next()
 	}
 }
 
+// GetTerminalTicket returns the claims of the ticket the request was
+// authenticated with.
+func GetTerminalTicket(c *gin.Context) *services.TerminalClaims {
+	claims, _ := c.Get("terminalTicket")
+	return claims.(*services.TerminalClaims)
+}
+
 func RequirePermission(resource, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("userRole")
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IngestTokenRequired authenticates external alert senders with the bearer
+// token in ALERT_INGEST_TOKEN. Ingest is disabled while the variable is unset.
+func IngestTokenRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("ALERT_INGEST_TOKEN")
+		if expected == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Alert ingest is not configured"})
+			c.Abort()
+			return
+		}
+
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ingest token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
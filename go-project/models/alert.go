@@ -7,6 +7,8 @@ type AlertRuleType string
 const (
 	AlertRuleTypeMonitor        AlertRuleType = "monitor"
 	AlertRuleTypeInfrastructure AlertRuleType = "infrastructure"
+	AlertRuleTypeCompliance     AlertRuleType = "compliance"
+	AlertRuleTypeExternal       AlertRuleType = "external" // Created on ingest; never evaluated locally
 )
 
 type AlertConditionType string
@@ -17,6 +19,19 @@ const (
 	AlertConditionMemoryHigh  AlertConditionType = "memory_high"
 	AlertConditionLatencyHigh AlertConditionType = "latency_high"
 	AlertConditionUptimeLow   AlertConditionType = "uptime_low"
+	// AlertConditionStorageHigh fires on a storage's used percentage.
+	AlertConditionStorageHigh AlertConditionType = "storage_high"
+
+	// Compliance conditions. target_id is a compliance record ID, or a license
+	// ID for license requirement conditions; 0 covers all of them. threshold
+	// is a grace period in days past the due or audit date.
+	AlertConditionComplianceOverdue              AlertConditionType = "compliance_overdue"
+	AlertConditionComplianceNonCompliant         AlertConditionType = "compliance_non_compliant"
+	AlertConditionComplianceAuditOverdue         AlertConditionType = "compliance_audit_overdue"
+	AlertConditionLicenseRequirementOverdue      AlertConditionType = "license_requirement_overdue"
+	AlertConditionLicenseRequirementNonCompliant AlertConditionType = "license_requirement_non_compliant"
+
+	AlertConditionExternal AlertConditionType = "external"
 )
 
 type ComparisonOperator string
@@ -39,6 +54,15 @@ const (
 	AlertSeverityInfo     AlertSeverity = "info"
 )
 
+// Valid reports whether s is one of the defined severities.
+func (s AlertSeverity) Valid() bool {
+	switch s {
+	case AlertSeverityCritical, AlertSeverityHigh, AlertSeverityMedium, AlertSeverityLow, AlertSeverityInfo:
+		return true
+	}
+	return false
+}
+
 type AlertStatus string
 
 const (
@@ -48,29 +72,104 @@ const (
 )
 
 type AlertRule struct {
-	ID            int64              `json:"id" db:"id"`
-	Name          string             `json:"name" db:"name"`
-	Type          AlertRuleType      `json:"type" db:"type"`
-	TargetID      int64              `json:"target_id" db:"target_id"`
-	ConditionType AlertConditionType `json:"condition_type" db:"condition_type"`
-	Threshold     float64            `json:"threshold" db:"threshold"`
-	Enabled       bool               `json:"enabled" db:"enabled"`
-	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time          `json:"updated_at" db:"updated_at"`
+	ID                 int64              `json:"id" db:"id"`
+	Name               string             `json:"name" db:"name"`
+	Type               AlertRuleType      `json:"type" db:"type"`
+	TargetID           int64              `json:"target_id" db:"target_id"`
+	ConditionType      AlertConditionType `json:"condition_type" db:"condition_type"`
+	Threshold          float64            `json:"threshold" db:"threshold"`
+	ForDuration        int                `json:"for_duration" db:"for_duration"` // Seconds the condition must hold before firing
+	ForChecks          int                `json:"for_checks" db:"for_checks"`     // Consecutive breaching checks before firing
+	EscalationPolicyID *int64             `json:"escalation_policy_id" db:"escalation_policy_id"`
+	Enabled            bool               `json:"enabled" db:"enabled"`
+	Severity           AlertSeverity      `json:"severity" db:"severity"`                 // Empty uses the condition's default
+	SeverityTiers      []SeverityTier     `json:"severity_tiers" db:"severity_tiers"`     // Override Severity once the value reaches a tier
+	MessageTemplate    string             `json:"message_template" db:"message_template"` // text/template for the alert message
+	CreatedAt          time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// SeverityTier raises alerts at Severity once the value reaches Threshold:
+// at or above it, or at or below it for conditions where lower values are
+// worse, such as uptime_low.
+type SeverityTier struct {
+	Threshold float64       `json:"threshold"`
+	Severity  AlertSeverity `json:"severity"`
 }
 
 type Alert struct {
-	ID             int64         `json:"id" db:"id"`
-	AlertRuleID    int64         `json:"alert_rule_id" db:"alert_rule_id"`
-	Type           string        `json:"type" db:"type"`
-	Severity       AlertSeverity `json:"severity" db:"severity"`
-	Message        string        `json:"message" db:"message"`
-	CurrentValue   float64       `json:"current_value" db:"current_value"`
-	TargetID       int64         `json:"target_id" db:"target_id"`
-	TargetName     string        `json:"target_name" db:"target_name"`
-	Status         AlertStatus   `json:"status" db:"status"`
-	AcknowledgedAt *time.Time    `json:"acknowledged_at" db:"acknowledged_at"`
-	AcknowledgedBy string        `json:"acknowledged_by" db:"acknowledged_by"`
-	ResolvedAt     *time.Time    `json:"resolved_at" db:"resolved_at"`
-	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+	ID                 int64             `json:"id" db:"id"`
+	AlertRuleID        int64             `json:"alert_rule_id" db:"alert_rule_id"`
+	Type               string            `json:"type" db:"type"`
+	Severity           AlertSeverity     `json:"severity" db:"severity"`
+	Message            string            `json:"message" db:"message"`
+	CurrentValue       float64           `json:"current_value" db:"current_value"`
+	TargetID           int64             `json:"target_id" db:"target_id"`
+	TargetName         string            `json:"target_name" db:"target_name"`
+	Status             AlertStatus       `json:"status" db:"status"`
+	AcknowledgedAt     *time.Time        `json:"acknowledged_at" db:"acknowledged_at"`
+	AcknowledgedBy     string            `json:"acknowledged_by" db:"acknowledged_by"`
+	AcknowledgedByID   *int64            `json:"acknowledged_by_id" db:"acknowledged_by_id"`
+	AssignedTo         *int64            `json:"assigned_to" db:"assigned_to"`
+	AssignedToUsername string            `json:"assigned_to_username" db:"-"`
+	ResolvedAt         *time.Time        `json:"resolved_at" db:"resolved_at"`
+	EscalationPolicyID *int64            `json:"escalation_policy_id" db:"escalation_policy_id"`
+	EscalationStep     int               `json:"escalation_step" db:"escalation_step"`
+	NextEscalationAt   *time.Time        `json:"next_escalation_at" db:"next_escalation_at"`
+	SilenceID          *int64            `json:"silence_id" db:"silence_id"`   // Silence that muted or suppressed the alert
+	Fingerprint        string            `json:"fingerprint" db:"fingerprint"` // Rule and target; one open alert per fingerprint
+	OccurrenceCount    int               `json:"occurrence_count" db:"occurrence_count"`
+	LastSeenAt         *time.Time        `json:"last_seen_at" db:"last_seen_at"`
+	Labels             map[string]string `json:"labels,omitempty" db:"labels"` // Labels of external alerts
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+}
+
+type AlertComment struct {
+	ID        int64     `json:"id" db:"id"`
+	AlertID   int64     `json:"alert_id" db:"alert_id"`
+	UserID    *int64    `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"-"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type AlertActivityAction string
+
+const (
+	AlertActivityCreated      AlertActivityAction = "created"
+	AlertActivityNotified     AlertActivityAction = "notified" // From alert_escalations
+	AlertActivityAcknowledged AlertActivityAction = "acknowledged"
+	AlertActivityAssigned     AlertActivityAction = "assigned"
+	AlertActivityUnassigned   AlertActivityAction = "unassigned"
+	AlertActivityCommented    AlertActivityAction = "commented" // From alert_comments
+	AlertActivityResolved     AlertActivityAction = "resolved"
+)
+
+// AlertActivity is one entry in an alert's activity log. UserID is nil for
+// changes made by the system.
+type AlertActivity struct {
+	Action    AlertActivityAction `json:"action"`
+	UserID    *int64              `json:"user_id"`
+	Username  string              `json:"username"`
+	Detail    string              `json:"detail"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// AlertSilence mutes alerts matching every non-nil matcher between StartsAt
+// and EndsAt.
+type AlertSilence struct {
+	ID               int64          `json:"id" db:"id"`
+	AlertRuleID      *int64         `json:"alert_rule_id" db:"alert_rule_id"`
+	TargetID         *int64         `json:"target_id" db:"target_id"`
+	TargetName       *string        `json:"target_name" db:"target_name"`
+	Type             *string        `json:"type" db:"type"`
+	Severity         *AlertSeverity `json:"severity" db:"severity"`
+	SuppressCreation bool           `json:"suppress_creation" db:"suppress_creation"`
+	StartsAt         time.Time      `json:"starts_at" db:"starts_at"`
+	EndsAt           time.Time      `json:"ends_at" db:"ends_at"`
+	CreatedBy        string         `json:"created_by" db:"created_by"`
+	Comment          string         `json:"comment" db:"comment"`
+	Active           bool           `json:"active"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
 }
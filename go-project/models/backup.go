@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// GuestBackupStatus is a guest's most recent backup. LastBackup is nil when
+// no backup of the guest was found.
+type GuestBackupStatus struct {
+	ServerID   int64      `json:"server_id"`
+	ServerName string     `json:"server_name"`
+	Node       string     `json:"node"`
+	VMID       int        `json:"vmid"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"` // qemu or lxc
+	LastBackup *time.Time `json:"last_backup"`
+	AgeDays    *float64   `json:"age_days"`
+}
+
+// BackupCoverageReport lists the guests of all Proxmox servers without a
+// backup newer than MaxAgeDays.
+type BackupCoverageReport struct {
+	GeneratedAt  time.Time           `json:"generated_at"`
+	MaxAgeDays   int                 `json:"max_age_days"`
+	TotalGuests  int                 `json:"total_guests"`
+	CoveredCount int                 `json:"covered_count"`
+	Uncovered    []GuestBackupStatus `json:"uncovered"`
+	Errors       []ServerError       `json:"errors"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+type NotificationChannelType string
+
+const (
+	NotificationChannelEmail   NotificationChannelType = "email"
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	NotificationChannelSlack   NotificationChannelType = "slack"
+)
+
+type NotificationChannel struct {
+	ID        int64                   `json:"id" db:"id"`
+	Name      string                  `json:"name" db:"name"`
+	Type      NotificationChannelType `json:"type" db:"type"`
+	Target    string                  `json:"target" db:"target"` // Email address or webhook URL
+	Enabled   bool                    `json:"enabled" db:"enabled"`
+	CreatedAt time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time               `json:"updated_at" db:"updated_at"`
+}
+
+type RotationType string
+
+const (
+	RotationTypeDaily  RotationType = "daily"
+	RotationTypeWeekly RotationType = "weekly"
+)
+
+type OnCallSchedule struct {
+	ID            int64            `json:"id" db:"id"`
+	Name          string           `json:"name" db:"name"`
+	Description   string           `json:"description" db:"description"`
+	Timezone      string           `json:"timezone" db:"timezone"`             // IANA zone, e.g. "Europe/Berlin"
+	RotationType  RotationType     `json:"rotation_type" db:"rotation_type"`   // How often the shift hands off
+	HandoffTime   string           `json:"handoff_time" db:"handoff_time"`     // Local "HH:MM" of each handoff
+	RotationStart string           `json:"rotation_start" db:"rotation_start"` // Local "YYYY-MM-DD" of the first shift
+	Members       []OnCallMember   `json:"members"`
+	Overrides     []OnCallOverride `json:"overrides"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+type OnCallMember struct {
+	UserID   int64  `json:"user_id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+	Position int    `json:"position" db:"position"`
+}
+
+type OnCallOverride struct {
+	ID         int64     `json:"id" db:"id"`
+	ScheduleID int64     `json:"schedule_id" db:"schedule_id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	Username   string    `json:"username" db:"username"`
+	StartsAt   time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time `json:"ends_at" db:"ends_at"`
+	Reason     string    `json:"reason" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type EscalationTargetType string
+
+const (
+	EscalationTargetUser     EscalationTargetType = "user"
+	EscalationTargetChannel  EscalationTargetType = "channel"
+	EscalationTargetSchedule EscalationTargetType = "schedule"
+)
+
+type EscalationPolicy struct {
+	ID          int64            `json:"id" db:"id"`
+	Name        string           `json:"name" db:"name"`
+	Description string           `json:"description" db:"description"`
+	Steps       []EscalationStep `json:"steps"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+type EscalationStep struct {
+	ID           int64                `json:"id" db:"id"`
+	PolicyID     int64                `json:"policy_id" db:"policy_id"`
+	StepOrder    int                  `json:"step_order" db:"step_order"`
+	DelayMinutes int                  `json:"delay_minutes" db:"delay_minutes"` // Wait after the previous step if still unacknowledged
+	TargetType   EscalationTargetType `json:"target_type" db:"target_type"`
+	TargetID     int64                `json:"target_id" db:"target_id"`
+}
+
+type AlertEscalation struct {
+	ID         int64                `json:"id" db:"id"`
+	AlertID    int64                `json:"alert_id" db:"alert_id"`
+	StepOrder  int                  `json:"step_order" db:"step_order"`
+	TargetType EscalationTargetType `json:"target_type" db:"target_type"`
+	TargetID   int64                `json:"target_id" db:"target_id"`
+	Recipient  string               `json:"recipient" db:"recipient"`
+	Status     string               `json:"status" db:"status"` // sent, failed
+	Error      string               `json:"error" db:"error"`
+	NotifiedAt time.Time            `json:"notified_at" db:"notified_at"`
+}
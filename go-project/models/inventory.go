@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// InventoryNode is a node of a Proxmox server as of its last sync. CPU is
+// the used fraction of MaxCPU cores. RemovedAt is set once the node has left
+// the cluster.
+type InventoryNode struct {
+	ID         int64      `json:"id"`
+	ServerID   int64      `json:"server_id"`
+	ServerName string     `json:"server_name"`
+	Node       string     `json:"node"`
+	Status     string     `json:"status"`
+	CPU        float64    `json:"cpu"`
+	MaxCPU     int        `json:"maxcpu"`
+	Mem        int64      `json:"mem"`
+	MaxMem     int64      `json:"maxmem"`
+	Disk       int64      `json:"disk"`
+	MaxDisk    int64      `json:"maxdisk"`
+	Uptime     int64      `json:"uptime"`
+	ConfigHash string     `json:"config_hash"`
+	FirstSeen  time.Time  `json:"first_seen"`
+	LastSeen   time.Time  `json:"last_seen"`
+	RemovedAt  *time.Time `json:"removed_at"`
+}
+
+// InventoryGuest is a VM or container of a Proxmox server as of its last
+// sync. ConfigHash covers its name, size, template flag, pool and tags.
+// DeletedAt is set once the guest is gone.
+type InventoryGuest struct {
+	ID         int64      `json:"id"`
+	ServerID   int64      `json:"server_id"`
+	ServerName string     `json:"server_name"`
+	VMID       int        `json:"vmid"`
+	Type       string     `json:"type"` // qemu or lxc
+	Name       string     `json:"name"`
+	Node       string     `json:"node"`
+	Status     string     `json:"status"`
+	Template   bool       `json:"template"`
+	MaxCPU     int        `json:"maxcpu"`
+	MaxMem     int64      `json:"maxmem"`
+	MaxDisk    int64      `json:"maxdisk"`
+	Pool       string     `json:"pool"`
+	Tags       string     `json:"tags"`
+	ConfigHash string     `json:"config_hash"`
+	FirstSeen  time.Time  `json:"first_seen"`
+	LastSeen   time.Time  `json:"last_seen"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+type InventoryEventType string
+
+const (
+	InventoryNodeAdded         InventoryEventType = "node_added"
+	InventoryNodeRemoved       InventoryEventType = "node_removed"
+	InventoryNodeResized       InventoryEventType = "node_resized"
+	InventoryGuestCreated      InventoryEventType = "guest_created"
+	InventoryGuestDeleted      InventoryEventType = "guest_deleted"
+	InventoryGuestResized      InventoryEventType = "guest_resized"
+	InventoryGuestMigrated     InventoryEventType = "guest_migrated"
+	InventoryGuestRenamed      InventoryEventType = "guest_renamed"
+	InventoryGuestReconfigured InventoryEventType = "guest_reconfigured" // Template, pool or tags changed
+)
+
+// InventoryEvent is a change noticed between two syncs of a server, so
+// OccurredAt is when it was noticed rather than when it happened.
+type InventoryEvent struct {
+	ID         int64              `json:"id"`
+	ServerID   int64              `json:"server_id"`
+	ServerName string             `json:"server_name"`
+	Event      InventoryEventType `json:"event"`
+	Node       string             `json:"node"`
+	GuestType  string             `json:"guest_type"`
+	VMID       int                `json:"vmid"`
+	Name       string             `json:"name"`
+	OldValue   string             `json:"old_value"`
+	NewValue   string             `json:"new_value"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
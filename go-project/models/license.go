@@ -147,3 +147,26 @@ type VendorSpend struct {
 	LicenseCount int       `json:"license_count" db:"license_count"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
+
+type LicenseAlertType string
+
+const (
+	LicenseAlertExpiringSoon  LicenseAlertType = "expiring_soon"
+	LicenseAlertExpired       LicenseAlertType = "expired"
+	LicenseAlertRenewalDue    LicenseAlertType = "renewal_due"
+	LicenseAlertComplianceDue LicenseAlertType = "compliance_due"
+)
+
+type LicenseAlert struct {
+	ID             int64            `json:"id" db:"id"`
+	LicenseID      int64            `json:"license_id" db:"license_id"`
+	LicenseName    string           `json:"license_name" db:"license_name"`
+	AlertType      LicenseAlertType `json:"alert_type" db:"alert_type"`
+	ThresholdDays  int              `json:"threshold_days" db:"threshold_days"`
+	DueDate        *time.Time       `json:"due_date" db:"due_date"`
+	Message        string           `json:"message" db:"message"`
+	TriggeredAt    *time.Time       `json:"triggered_at" db:"triggered_at"`
+	AcknowledgedAt *time.Time       `json:"acknowledged_at" db:"acknowledged_at"`
+	AcknowledgedBy string           `json:"acknowledged_by" db:"acknowledged_by"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// MetricSample is a node's or guest's averages over a series' step. CPU is
+// the used fraction of MaxCPU cores; memory is in bytes, network and disk IO
+// in bytes per second. Nodes have IOWait and LoadAvg but no disk IO. Fields
+// are nil where Proxmox recorded nothing, e.g. while a guest was stopped.
+type MetricSample struct {
+	SampledAt time.Time `json:"sampled_at"`
+	CPU       *float64  `json:"cpu"`
+	MaxCPU    *float64  `json:"maxcpu"`
+	Mem       *float64  `json:"mem"`
+	MaxMem    *float64  `json:"maxmem"`
+	NetIn     *float64  `json:"netin"`
+	NetOut    *float64  `json:"netout"`
+	DiskRead  *float64  `json:"diskread"`
+	DiskWrite *float64  `json:"diskwrite"`
+	IOWait    *float64  `json:"iowait"`
+	LoadAvg   *float64  `json:"loadavg"`
+}
+
+// MetricSeries is the history of a node, or of a guest when VMID is set,
+// from the collected Proxmox timeframe with the finest detail still kept for
+// From. Step is the spacing of its samples in seconds.
+type MetricSeries struct {
+	ServerID  int64          `json:"server_id"`
+	Node      string         `json:"node"`
+	VMID      int            `json:"vmid"`
+	Timeframe string         `json:"timeframe"`
+	Step      int64          `json:"step"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Samples   []MetricSample `json:"samples"`
+}
@@ -29,6 +29,14 @@ type Server struct {
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// ServerError is a server that could not be reached while building a report
+// across servers.
+type ServerError struct {
+	ServerID   int64  `json:"server_id"`
+	ServerName string `json:"server_name"`
+	Error      string `json:"error"`
+}
+
 type ProxmoxServer struct {
 	Server
 	Node        string `json:"node" db:"node"`
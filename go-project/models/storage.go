@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// StorageStatus is the current usage of a Proxmox storage. Shared storages,
+// which every node of a cluster sees, have an empty Node.
+type StorageStatus struct {
+	ServerID     int64            `json:"server_id"`
+	ServerName   string           `json:"server_name"`
+	Node         string           `json:"node"`
+	Storage      string           `json:"storage"`
+	Type         string           `json:"type"` // Storage plugin, e.g. dir, lvmthin, zfspool or nfs
+	Content      string           `json:"content"`
+	Shared       bool             `json:"shared"`
+	Active       bool             `json:"active"`
+	Used         int64            `json:"used"`
+	Total        int64            `json:"total"`
+	Avail        int64            `json:"avail"`
+	UsagePercent float64          `json:"usage_percent"`
+	Forecast     *StorageForecast `json:"forecast"`
+}
+
+// StorageForecast extrapolates a storage's growth over the last WindowDays.
+// FullAt and DaysUntilFull are nil when usage is flat or shrinking, or would
+// take more than five years to fill the storage.
+type StorageForecast struct {
+	WindowDays    int        `json:"window_days"`
+	Samples       int        `json:"samples"`
+	GrowthPerDay  float64    `json:"growth_per_day"` // Bytes
+	FullAt        *time.Time `json:"full_at"`
+	DaysUntilFull *float64   `json:"days_until_full"`
+}
+
+type StorageSample struct {
+	Used      int64     `json:"used"`
+	Total     int64     `json:"total"`
+	SampledAt time.Time `json:"sampled_at"`
+}
+
+// StorageOverview lists the storages of all Proxmox servers, fullest first.
+type StorageOverview struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Storages    []StorageStatus `json:"storages"`
+	Errors      []ServerError   `json:"errors"`
+}
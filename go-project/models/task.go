@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type ProxmoxTaskStatus string
+
+const (
+	TaskStatusRunning ProxmoxTaskStatus = "running"
+	TaskStatusOK      ProxmoxTaskStatus = "ok"
+	TaskStatusFailed  ProxmoxTaskStatus = "failed"
+	TaskStatusUnknown ProxmoxTaskStatus = "unknown" // No longer found on the node
+)
+
+// ProxmoxTask is a Proxmox task started through the API, such as starting a
+// container. ExitStatus is Proxmox's own result, "OK" or an error message.
+type ProxmoxTask struct {
+	ID          int64             `json:"id"`
+	ServerID    int64             `json:"server_id"`
+	ServerName  string            `json:"server_name"`
+	UPID        string            `json:"upid"`
+	Node        string            `json:"node"`
+	Type        string            `json:"type"` // Proxmox task type, e.g. vzstart or qmsnapshot
+	VMID        *int              `json:"vmid"`
+	Description string            `json:"description"`
+	UserID      *int64            `json:"user_id"`
+	Username    string            `json:"username,omitempty"`
+	Status      ProxmoxTaskStatus `json:"status"`
+	ExitStatus  string            `json:"exit_status"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  *time.Time        `json:"finished_at"`
+}
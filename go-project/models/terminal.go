@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TerminalSession is a recorded node shell or guest console session. Its
+// recording is an asciicast v2 file of the terminal output.
+type TerminalSession struct {
+	ID         int64      `json:"id"`
+	ServerID   *int64     `json:"server_id"`
+	ServerName string     `json:"server_name"`
+	UserID     *int64     `json:"user_id"`
+	Username   string     `json:"username"`
+	Node       string     `json:"node"`
+	GuestType  string     `json:"guest_type"` // Empty for node shells
+	VMID       int        `json:"vmid,omitempty"`
+	ClientIP   string     `json:"client_ip"`
+	Size       int64      `json:"size"`     // Bytes of the recording
+	Duration   float64    `json:"duration"` // Seconds from the start to the last event
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at"` // Nil while the session is open
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"time"
+)
+
+// RecordAlertActivity adds an entry to an alert's activity log. userID is nil
+// for changes made by the system.
+func RecordAlertActivity(alertID int64, action models.AlertActivityAction, userID *int64, detail string) {
+	_, err := database.DB.Exec(`
+		INSERT INTO alert_activity (alert_id, action, user_id, detail, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, alertID, string(action), userID, detail, time.Now())
+	if err != nil {
+		log.Printf("[ALERT] Failed to record %s activity for alert %d: %v", action, alertID, err)
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go-project/database"
 	"go-project/models"
@@ -54,8 +57,9 @@ func (s *AlertChecker) checkAlerts() {
 	log.Printf("[ALERT] Checking alerts...")
 
 	rows, err := database.DB.Query(`
-		SELECT id, name, type, target_id, condition_type, threshold, enabled
-		FROM alert_rules WHERE enabled = 1
+		SELECT id, name, type, target_id, condition_type, threshold, for_duration, for_checks, escalation_policy_id, enabled,
+		       severity, severity_tiers, message_template
+		FROM alert_rules WHERE enabled = 1 AND type != 'external'
 	`)
 	if err != nil {
 		log.Printf("[ALERT] Failed to fetch alert rules: %v", err)
@@ -67,9 +71,16 @@ func (s *AlertChecker) checkAlerts() {
 
 	for rows.Next() {
 		var rule models.AlertRule
-		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Type, &rule.TargetID, &rule.ConditionType, &rule.Threshold, &rule.Enabled); err != nil {
+		var policyID sql.NullInt64
+		var tiers string
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Type, &rule.TargetID, &rule.ConditionType, &rule.Threshold,
+			&rule.ForDuration, &rule.ForChecks, &policyID, &rule.Enabled, &rule.Severity, &tiers, &rule.MessageTemplate); err != nil {
 			continue
 		}
+		if policyID.Valid {
+			rule.EscalationPolicyID = &policyID.Int64
+		}
+		rule.SeverityTiers = DecodeSeverityTiers(tiers)
 
 		wg.Add(1)
 		go func(r models.AlertRule) {
@@ -81,16 +92,68 @@ func (s *AlertChecker) checkAlerts() {
 	wg.Wait()
 }
 
+// ErrUnsupportedCondition is returned by EvaluateRule for condition types
+// that are not implemented for the rule's type.
+var ErrUnsupportedCondition = errors.New("condition is not supported for this rule type")
+
+// AlertEvaluation is the outcome of evaluating a rule against one target.
+type AlertEvaluation struct {
+	Fire       bool                 `json:"fire"`
+	Value      float64              `json:"value"`
+	Message    string               `json:"message"`
+	Severity   models.AlertSeverity `json:"severity"`
+	TargetID   int64                `json:"target_id"`
+	TargetName string               `json:"target_name"`
+	Owner      string               `json:"owner,omitempty"` // Username or email notified directly when the alert fires
+}
+
 func (s *AlertChecker) checkRule(rule models.AlertRule) {
+	evaluations, err := EvaluateRule(rule)
+	if err == ErrUnsupportedCondition {
+		return
+	}
+	if err != nil {
+		log.Printf("[ALERT] Failed to evaluate rule %d: %v", rule.ID, err)
+		return
+	}
+
+	for _, e := range evaluations {
+		if e.Fire {
+			s.createAlert(rule, e)
+		}
+	}
+}
+
+// EvaluateRule evaluates a rule against current data without creating any
+// alerts. Infrastructure rules yield one evaluation per node of the target
+// server and compliance rules one per overdue item.
+func EvaluateRule(rule models.AlertRule) ([]AlertEvaluation, error) {
+	var evaluations []AlertEvaluation
+	var err error
+
 	switch rule.Type {
 	case models.AlertRuleTypeMonitor:
-		s.checkMonitorAlert(rule)
+		evaluations, err = evaluateMonitorRule(rule)
 	case models.AlertRuleTypeInfrastructure:
-		s.checkInfrastructureAlert(rule)
+		evaluations, err = evaluateInfrastructureRule(rule)
+	case models.AlertRuleTypeCompliance:
+		evaluations, err = evaluateComplianceRule(rule)
+	case models.AlertRuleTypeExternal:
+		return nil, errors.New("external rules are evaluated by the system that sends them")
+	default:
+		return nil, fmt.Errorf("unknown rule type: %s", rule.Type)
+	}
+	if err != nil {
+		return nil, err
 	}
+
+	for i := range evaluations {
+		applyRuleFormat(rule, &evaluations[i], nil)
+	}
+	return evaluations, nil
 }
 
-func (s *AlertChecker) checkMonitorAlert(rule models.AlertRule) {
+func evaluateMonitorRule(rule models.AlertRule) ([]AlertEvaluation, error) {
 	var monitorName string
 	var currentStatus string
 	var latency int64
@@ -99,150 +162,248 @@ func (s *AlertChecker) checkMonitorAlert(rule models.AlertRule) {
 	err := database.DB.QueryRow(`
 		SELECT name, status, latency, uptime FROM monitors WHERE id = ?
 	`, rule.TargetID).Scan(&monitorName, &currentStatus, &latency, &uptime)
-
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("monitor %d not found", rule.TargetID)
+	}
 	if err != nil {
-		log.Printf("[ALERT] Failed to fetch monitor %d: %v", rule.TargetID, err)
-		return
+		return nil, err
 	}
 
-	var currentValue float64
-	var shouldAlert bool
-	var message string
-	var severity models.AlertSeverity
+	e := AlertEvaluation{TargetID: rule.TargetID, TargetName: monitorName}
 
 	switch rule.ConditionType {
 	case models.AlertConditionStatusDown:
-		shouldAlert = currentStatus == "down"
-		severity = models.AlertSeverityCritical
-		message = fmt.Sprintf("Monitor %s is down", monitorName)
+		e.Fire, e.Value, err = conditionHeld(rule,
+			monitorSamples(rule.TargetID, "CASE WHEN status = 'down' THEN 1 ELSE 0 END"),
+			func(v float64) bool { return v == 1 })
+		e.Severity = models.AlertSeverityCritical
+		e.Message = fmt.Sprintf("Monitor %s is down%s", monitorName, heldFor(rule))
 
 	case models.AlertConditionLatencyHigh:
-		currentValue = float64(latency)
-		shouldAlert = currentValue >= rule.Threshold
-		severity = models.AlertSeverityHigh
-		message = fmt.Sprintf("Latency threshold met: %dms (>= %v)", latency, rule.Threshold)
+		e.Fire, e.Value, err = conditionHeld(rule,
+			monitorSamples(rule.TargetID, "latency"),
+			func(v float64) bool { return v >= rule.Threshold })
+		e.Severity = models.AlertSeverityHigh
+		e.Message = fmt.Sprintf("Latency threshold met: %.0fms (>= %v)%s", e.Value, rule.Threshold, heldFor(rule))
 
 	case models.AlertConditionUptimeLow:
-		currentValue = uptime
-		shouldAlert = currentValue <= rule.Threshold
-		severity = models.AlertSeverityMedium
-		message = fmt.Sprintf("Uptime threshold met: %.1f%% (<= %v)", uptime, rule.Threshold)
-	}
-
-	if shouldAlert {
-		s.createAlert(rule, severity, message, currentValue, monitorName)
+		// Uptime is already an aggregate over the monitor's history, so it is
+		// compared as a snapshot.
+		e.Value = uptime
+		e.Fire = uptime <= rule.Threshold
+		e.Severity = models.AlertSeverityMedium
+		e.Message = fmt.Sprintf("Uptime threshold met: %.1f%% (<= %v)", uptime, rule.Threshold)
+
+	default:
+		return nil, ErrUnsupportedCondition
 	}
-}
 
-func (s *AlertChecker) checkInfrastructureAlert(rule models.AlertRule) {
-	servers, err := database.DB.Query(`
-		SELECT id, name, ip_address, port, username, password, realm, verify_ssl
-		FROM servers
-		WHERE type = 'proxmox'
-	`)
 	if err != nil {
-		log.Printf("[ALERT] Failed to fetch servers: %v", err)
-		return
+		return nil, err
 	}
-	defer servers.Close()
-
-	var wg sync.WaitGroup
-	for servers.Next() {
-		var srv models.Server
-		var pwd string
-		if err := servers.Scan(&srv.ID, &srv.Name, &srv.IPAddress, &srv.Port, &srv.Username, &pwd, &srv.Realm, &srv.VerifySSL); err != nil {
-			continue
-		}
 
-		wg.Add(1)
-		go func(server models.Server) {
-			defer wg.Done()
-			s.checkServerCPUWarning(rule, server, pwd)
-		}(srv)
-	}
-	wg.Wait()
+	return []AlertEvaluation{e}, nil
 }
 
-func (s *AlertChecker) checkServerCPUWarning(rule models.AlertRule, server models.Server, password string) {
+// evaluateInfrastructureRule evaluates a rule against the node metrics the
+// monitor service records for the rule's target server.
+func evaluateInfrastructureRule(rule models.AlertRule) ([]AlertEvaluation, error) {
+	if rule.ConditionType == models.AlertConditionStorageHigh {
+		return evaluateStorageRule(rule)
+	}
 	if rule.ConditionType != models.AlertConditionCpuHigh {
-		return
+		return nil, ErrUnsupportedCondition
 	}
 
-	nodes, err := s.getProxmoxNodes(server, password)
+	// Only nodes with a recent sample are considered, so nodes removed from
+	// the cluster stop being evaluated.
+	rows, err := database.DB.Query(`
+		SELECT DISTINCT node FROM node_metrics
+		WHERE server_id = ? AND sampled_at >= ?
+	`, rule.TargetID, time.Now().Add(-sampleLookback))
 	if err != nil {
-		log.Printf("[ALERT] Failed to fetch nodes for server %d: %v", server.ID, err)
-		return
+		return nil, err
 	}
 
-	for _, node := range nodes {
-		if node.Status != "online" {
+	var nodes []string
+	for rows.Next() {
+		var node string
+		if err := rows.Scan(&node); err != nil {
 			continue
 		}
+		nodes = append(nodes, node)
+	}
+	rows.Close()
 
-		if node.CPU >= rule.Threshold {
-			s.createAlert(rule, models.AlertSeverityHigh,
-				fmt.Sprintf("CPU usage threshold met on %s: %.1f%% (>= %v)", node.Node, node.CPU, rule.Threshold),
-				node.CPU, node.Node)
+	evaluations := []AlertEvaluation{}
+	for _, node := range nodes {
+		held, cpu, err := conditionHeld(rule,
+			nodeSamples(rule.TargetID, node, "CASE WHEN status = 'online' THEN cpu ELSE NULL END"),
+			func(v float64) bool { return v >= rule.Threshold })
+		if err != nil {
+			log.Printf("[ALERT] Failed to evaluate rule %d for node %s: %v", rule.ID, node, err)
+			continue
 		}
+
+		evaluations = append(evaluations, AlertEvaluation{
+			Fire:       held,
+			Value:      cpu,
+			Message:    fmt.Sprintf("CPU usage threshold met on %s: %.1f%% (>= %v)%s", node, cpu, rule.Threshold, heldFor(rule)),
+			Severity:   models.AlertSeverityHigh,
+			TargetID:   rule.TargetID,
+			TargetName: node,
+		})
 	}
-}
 
-type ProxmoxNodeMetrics struct {
-	Node   string
-	Status string
-	CPU    float64
+	return evaluations, nil
 }
 
-func (s *AlertChecker) getProxmoxNodes(server models.Server, password string) ([]ProxmoxNodeMetrics, error) {
-	var nodes []ProxmoxNodeMetrics
-	client := NewProxmoxClient(
-		fmt.Sprintf("https://%s:%d", server.IPAddress, server.Port),
-		server.Username, password, server.Realm, server.VerifySSL)
-
-	proxmoxNodes, err := client.GetNodes()
+// evaluateStorageRule evaluates a storage_high rule against the usage the
+// storage collector samples for each storage of the rule's target server.
+func evaluateStorageRule(rule models.AlertRule) ([]AlertEvaluation, error) {
+	// Only storages sampled recently are considered, so removed storages
+	// stop being evaluated.
+	rows, err := database.DB.Query(`
+		SELECT DISTINCT node, storage FROM storage_samples
+		WHERE server_id = ? AND sampled_at >= ?
+	`, rule.TargetID, time.Now().Add(-3*storageSampleInterval))
 	if err != nil {
 		return nil, err
 	}
 
-	for _, node := range proxmoxNodes {
-		nodes = append(nodes, ProxmoxNodeMetrics{
-			Node:   node.Node,
-			Status: node.Status,
-			CPU:    node.CPU,
+	type storageKey struct{ node, storage string }
+	var storages []storageKey
+	for rows.Next() {
+		var key storageKey
+		if err := rows.Scan(&key.node, &key.storage); err != nil {
+			continue
+		}
+		storages = append(storages, key)
+	}
+	rows.Close()
+
+	evaluations := []AlertEvaluation{}
+	for _, st := range storages {
+		name := StorageTargetName(st.node, st.storage)
+		held, usage, err := conditionHeld(rule,
+			storageSamples(rule.TargetID, st.node, st.storage),
+			func(v float64) bool { return v >= rule.Threshold })
+		if err != nil {
+			log.Printf("[ALERT] Failed to evaluate rule %d for storage %s: %v", rule.ID, name, err)
+			continue
+		}
+
+		message := fmt.Sprintf("Storage %s is %.1f%% full (>= %v)%s", name, usage, rule.Threshold, heldFor(rule))
+		if held {
+			forecast, err := ForecastStorage(rule.TargetID, st.node, st.storage, StorageForecastDays)
+			if err == nil && forecast != nil && forecast.DaysUntilFull != nil {
+				message += fmt.Sprintf(", full in about %.1f days", *forecast.DaysUntilFull)
+			}
+		}
+
+		evaluations = append(evaluations, AlertEvaluation{
+			Fire:       held,
+			Value:      usage,
+			Message:    message,
+			Severity:   models.AlertSeverityHigh,
+			TargetID:   rule.TargetID,
+			TargetName: name,
 		})
 	}
-	return nodes, nil
+
+	return evaluations, nil
 }
 
-func (s *AlertChecker) createAlert(rule models.AlertRule, severity models.AlertSeverity, message string, currentValue float64, targetName string) {
-	shouldCreate := true
+// AlertFingerprint identifies the rule and target an alert is for.
+func AlertFingerprint(ruleID, targetID int64, targetName string) string {
+	return fmt.Sprintf("%d:%d:%s", ruleID, targetID, targetName)
+}
 
-	rows, err := database.DB.Query(`
-		SELECT id FROM alerts 
-		WHERE alert_rule_id = ? 
-		AND status IN ('active', 'acknowledged') 
-		AND created_at > datetime('now', '-5 minutes')
-		ORDER BY created_at DESC 
-		LIMIT 1
-	`, rule.ID)
-
-	if err == nil {
-		defer rows.Close()
-		if rows.Next() {
-			shouldCreate = false
-		}
+func (s *AlertChecker) createAlert(rule models.AlertRule, e AlertEvaluation) {
+	raiseAlert(rule, e, AlertFingerprint(rule.ID, e.TargetID, e.TargetName), nil)
+}
+
+// raiseAlert creates an alert for an evaluation, or records another
+// occurrence on the open alert with the same fingerprint, taking its latest
+// message, value and severity.
+func raiseAlert(rule models.AlertRule, e AlertEvaluation, fingerprint string, labels map[string]string) {
+	now := time.Now()
+
+	var labelsJSON interface{}
+	if len(labels) > 0 {
+		encoded, _ := json.Marshal(labels)
+		labelsJSON = string(encoded)
 	}
 
-	if !shouldCreate {
+	result, err := database.DB.Exec(`
+		UPDATE alerts
+		SET occurrence_count = occurrence_count + 1, last_seen_at = ?, message = ?, current_value = ?, severity = ?
+		WHERE fingerprint = ? AND status IN ('active', 'acknowledged')
+	`, now, e.Message, e.Value, string(e.Severity), fingerprint)
+	if err != nil {
+		log.Printf("[ALERT] Failed to update alert %s: %v", fingerprint, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
 		return
 	}
 
-	_, err = database.DB.Exec(`
-		INSERT INTO alerts (alert_rule_id, type, severity, message, current_value, target_id, target_name, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 'active')
-	`, rule.ID, string(rule.Type), string(severity), message, currentValue, rule.TargetID, targetName)
+	var silenceID interface{}
+	silence, err := MatchSilence(rule.ID, string(rule.Type), e.Severity, e.TargetID, e.TargetName)
+	if err != nil {
+		log.Printf("[ALERT] Failed to match silences for rule %d: %v", rule.ID, err)
+	}
+	if silence != nil {
+		if silence.SuppressCreation {
+			log.Printf("[ALERT] Alert for rule %d suppressed by silence %d", rule.ID, silence.ID)
+			return
+		}
+		silenceID = silence.ID
+	}
+
+	result, err = database.DB.Exec(`
+		INSERT INTO alerts (alert_rule_id, type, severity, message, current_value, target_id, target_name, status, silence_id,
+		                    fingerprint, occurrence_count, last_seen_at, labels)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'active', ?, ?, 1, ?, ?)
+	`, rule.ID, string(rule.Type), string(e.Severity), e.Message, e.Value, e.TargetID, e.TargetName, silenceID,
+		fingerprint, now, labelsJSON)
 
 	if err != nil {
 		log.Printf("[ALERT] Failed to create alert: %v", err)
+		return
+	}
+
+	alertID, _ := result.LastInsertId()
+	alert := models.Alert{
+		ID:              alertID,
+		AlertRuleID:     rule.ID,
+		Type:            string(rule.Type),
+		Severity:        e.Severity,
+		Message:         e.Message,
+		CurrentValue:    e.Value,
+		TargetID:        e.TargetID,
+		TargetName:      e.TargetName,
+		Status:          models.AlertStatusActive,
+		Fingerprint:     fingerprint,
+		OccurrenceCount: 1,
+		LastSeenAt:      &now,
+		Labels:          labels,
+		CreatedAt:       now,
+	}
+	if silence != nil {
+		alert.SilenceID = &silence.ID
+	}
+
+	RecordAlertActivity(alertID, models.AlertActivityCreated, nil, e.Message)
+	PublishEvent(EventAlertCreated, alert)
+
+	if e.Owner != "" && silence == nil {
+		NotifyAlertOwner(alert, e.Owner)
+	}
+
+	if rule.EscalationPolicyID != nil {
+		if err := ScheduleEscalation(alertID, *rule.EscalationPolicyID); err != nil {
+			log.Printf("[ALERT] Failed to schedule escalation for alert %d: %v", alertID, err)
+		}
 	}
 }
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"time"
+)
+
+// complianceItem is a compliance record or license requirement checked by a
+// compliance rule.
+type complianceItem struct {
+	ID         int64
+	Name       string
+	Status     string
+	Severity   models.AlertSeverity
+	AssignedTo string
+	Date       sql.NullTime
+}
+
+// evaluateComplianceRule returns an evaluation for every compliance record or
+// license requirement that is more than threshold days past its due or audit
+// date. Items that are not overdue are left out.
+func evaluateComplianceRule(rule models.AlertRule) ([]AlertEvaluation, error) {
+	var items []complianceItem
+	var err error
+	var describe func(item complianceItem, days int) string
+
+	switch rule.ConditionType {
+	case models.AlertConditionComplianceOverdue:
+		items, err = complianceRecords(rule.TargetID, "due_date", "status != 'compliant'")
+		describe = func(item complianceItem, days int) string {
+			return fmt.Sprintf("Compliance item %s is %d days overdue (%s)", item.Name, days, item.Status)
+		}
+
+	case models.AlertConditionComplianceNonCompliant:
+		items, err = complianceRecords(rule.TargetID, "due_date", "status = 'non_compliant'")
+		describe = func(item complianceItem, days int) string {
+			return fmt.Sprintf("Compliance item %s is still non-compliant %d days past its due date", item.Name, days)
+		}
+
+	case models.AlertConditionComplianceAuditOverdue:
+		items, err = complianceRecords(rule.TargetID, "next_audit_date", "1=1")
+		describe = func(item complianceItem, days int) string {
+			return fmt.Sprintf("Compliance item %s is %d days past its audit date", item.Name, days)
+		}
+
+	case models.AlertConditionLicenseRequirementOverdue:
+		items, err = licenseRequirements(rule.TargetID, "status IN ('pending', 'non_compliant')")
+		describe = func(item complianceItem, days int) string {
+			return fmt.Sprintf("License requirement %s is %d days overdue (%s)", item.Name, days, item.Status)
+		}
+
+	case models.AlertConditionLicenseRequirementNonCompliant:
+		items, err = licenseRequirements(rule.TargetID, "status = 'non_compliant'")
+		describe = func(item complianceItem, days int) string {
+			return fmt.Sprintf("License requirement %s is still non-compliant %d days past its due date", item.Name, days)
+		}
+
+	default:
+		return nil, ErrUnsupportedCondition
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	evaluations := []AlertEvaluation{}
+	for _, item := range items {
+		overdue := -daysUntil(item.Date.Time, now)
+		if overdue <= 0 || float64(overdue) <= rule.Threshold {
+			continue
+		}
+
+		evaluations = append(evaluations, AlertEvaluation{
+			Fire:       true,
+			Value:      float64(overdue),
+			Message:    describe(item, overdue),
+			Severity:   item.Severity,
+			TargetID:   item.ID,
+			TargetName: item.Name,
+			Owner:      item.AssignedTo,
+		})
+	}
+
+	return evaluations, nil
+}
+
+// complianceRecords loads unresolved compliance records with the given date
+// column set, optionally limited to one record.
+func complianceRecords(recordID int64, dateColumn, filter string) ([]complianceItem, error) {
+	query := `
+		SELECT id, title, status, severity, COALESCE(assigned_to, ''), ` + dateColumn + `
+		FROM compliance
+		WHERE resolved_date IS NULL AND ` + dateColumn + ` IS NOT NULL AND ` + filter
+	args := []interface{}{}
+
+	if recordID != 0 {
+		query += " AND id = ?"
+		args = append(args, recordID)
+	}
+
+	return queryComplianceItems(query, args...)
+}
+
+// licenseRequirements loads unresolved license compliance requirements with a
+// due date, optionally limited to one license. Non-compliant requirements are
+// raised as high severity, the rest as medium.
+func licenseRequirements(licenseID int64, filter string) ([]complianceItem, error) {
+	query := `
+		SELECT lc.id, l.name || ': ' || lc.requirement, lc.status,
+		       CASE WHEN lc.status = 'non_compliant' THEN 'high' ELSE 'medium' END,
+		       COALESCE(lc.assigned_to, ''), lc.due_date
+		FROM license_compliance lc
+		INNER JOIN licenses l ON lc.license_id = l.id
+		WHERE lc.resolved_date IS NULL AND lc.due_date IS NOT NULL AND lc.` + filter
+	args := []interface{}{}
+
+	if licenseID != 0 {
+		query += " AND lc.license_id = ?"
+		args = append(args, licenseID)
+	}
+
+	return queryComplianceItems(query, args...)
+}
+
+func queryComplianceItems(query string, args ...interface{}) ([]complianceItem, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []complianceItem
+	for rows.Next() {
+		var item complianceItem
+		if err := rows.Scan(&item.ID, &item.Name, &item.Status, &item.Severity, &item.AssignedTo, &item.Date); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-project/models"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// alertMessageData is what a rule's message template can refer to, e.g.
+// "{{.TargetName}} at {{printf \"%.1f\" .Value}}% (limit {{.Threshold}})".
+type alertMessageData struct {
+	RuleID     int64
+	RuleName   string
+	Condition  models.AlertConditionType
+	Threshold  float64
+	TargetID   int64
+	TargetName string
+	Value      float64
+	Severity   models.AlertSeverity
+	Message    string // The built-in message
+	Labels     map[string]string
+}
+
+// ParseMessageTemplate parses an alert rule's message template.
+func ParseMessageTemplate(text string) (*template.Template, error) {
+	return template.New("message").Option("missingkey=zero").Parse(text)
+}
+
+// DecodeSeverityTiers decodes the severity_tiers column of an alert rule.
+func DecodeSeverityTiers(value string) []models.SeverityTier {
+	tiers := []models.SeverityTier{}
+	if value == "" {
+		return tiers
+	}
+	if err := json.Unmarshal([]byte(value), &tiers); err != nil {
+		log.Printf("[ALERT] Ignoring invalid severity tiers %q: %v", value, err)
+		return []models.SeverityTier{}
+	}
+	return tiers
+}
+
+// EncodeSeverityTiers encodes severity tiers for the severity_tiers column.
+func EncodeSeverityTiers(tiers []models.SeverityTier) string {
+	if len(tiers) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(tiers)
+	return string(encoded)
+}
+
+// lowerIsWorse reports whether a condition breaches as its value falls.
+func lowerIsWorse(condition models.AlertConditionType) bool {
+	return condition == models.AlertConditionUptimeLow
+}
+
+// applyRuleFormat sets an evaluation's severity and message from the rule's
+// severity, severity tiers and message template, leaving the condition's
+// defaults for whatever the rule does not set.
+func applyRuleFormat(rule models.AlertRule, e *AlertEvaluation, labels map[string]string) {
+	if rule.Severity != "" {
+		e.Severity = rule.Severity
+	}
+	if tier, ok := severityTier(rule, e.Value); ok {
+		e.Severity = tier.Severity
+	}
+
+	if strings.TrimSpace(rule.MessageTemplate) == "" {
+		return
+	}
+
+	tmpl, err := ParseMessageTemplate(rule.MessageTemplate)
+	if err != nil {
+		log.Printf("[ALERT] Invalid message template on rule %d: %v", rule.ID, err)
+		return
+	}
+
+	var message strings.Builder
+	err = tmpl.Execute(&message, alertMessageData{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		Condition:  rule.ConditionType,
+		Threshold:  rule.Threshold,
+		TargetID:   e.TargetID,
+		TargetName: e.TargetName,
+		Value:      e.Value,
+		Severity:   e.Severity,
+		Message:    e.Message,
+		Labels:     labels,
+	})
+	if err != nil {
+		log.Printf("[ALERT] Failed to render message template on rule %d: %v", rule.ID, err)
+		return
+	}
+	e.Message = message.String()
+}
+
+// severityTier returns the most severe tier value has reached, that is the
+// tier with the highest threshold at or below value, or the lowest at or
+// above it for conditions where lower values are worse.
+func severityTier(rule models.AlertRule, value float64) (models.SeverityTier, bool) {
+	var best models.SeverityTier
+	found := false
+
+	for _, tier := range rule.SeverityTiers {
+		if lowerIsWorse(rule.ConditionType) {
+			if value <= tier.Threshold && (!found || tier.Threshold < best.Threshold) {
+				best, found = tier, true
+			}
+		} else if value >= tier.Threshold && (!found || tier.Threshold > best.Threshold) {
+			best, found = tier, true
+		}
+	}
+
+	return best, found
+}
+
+// ValidateRuleFormat checks an alert rule's severity, tiers and template.
+func ValidateRuleFormat(rule models.AlertRule) error {
+	if rule.Severity != "" && !rule.Severity.Valid() {
+		return fmt.Errorf("invalid severity %q", rule.Severity)
+	}
+
+	seen := map[float64]bool{}
+	for _, tier := range rule.SeverityTiers {
+		if !tier.Severity.Valid() {
+			return fmt.Errorf("invalid severity %q in severity_tiers", tier.Severity)
+		}
+		if seen[tier.Threshold] {
+			return fmt.Errorf("severity_tiers has more than one tier at threshold %v", tier.Threshold)
+		}
+		seen[tier.Threshold] = true
+	}
+
+	if _, err := ParseMessageTemplate(rule.MessageTemplate); err != nil {
+		return fmt.Errorf("invalid message_template: %v", err)
+	}
+
+	return nil
+}
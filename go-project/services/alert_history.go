@@ -0,0 +1,150 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"time"
+)
+
+// sampleLookback is how far before a rule's duration window samples are
+// loaded, so the sample that opened the window is visible.
+const sampleLookback = 5 * time.Minute
+
+// metricSample is one stored observation. Value is invalid when the target
+// could not be measured, which always breaks a breaching run.
+type metricSample struct {
+	Value sql.NullFloat64
+	At    time.Time
+}
+
+// sampleQuery returns samples newest first. A non-zero since only returns
+// samples taken at or after it and a positive limit caps the row count.
+type sampleQuery func(since time.Time, limit int) ([]metricSample, error)
+
+// historySamples builds a sampleQuery over a subquery exposing value and at
+// columns.
+func historySamples(subquery string, args ...interface{}) sampleQuery {
+	return func(since time.Time, limit int) ([]metricSample, error) {
+		query := "SELECT value, at FROM (" + subquery + ") WHERE 1=1"
+		queryArgs := append([]interface{}{}, args...)
+
+		if !since.IsZero() {
+			query += " AND at >= ?"
+			queryArgs = append(queryArgs, since)
+		}
+
+		query += " ORDER BY at DESC"
+
+		if limit > 0 {
+			query += " LIMIT ?"
+			queryArgs = append(queryArgs, limit)
+		}
+
+		rows, err := database.DB.Query(query, queryArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var samples []metricSample
+		for rows.Next() {
+			var sample metricSample
+			if err := rows.Scan(&sample.Value, &sample.At); err != nil {
+				return nil, err
+			}
+			samples = append(samples, sample)
+		}
+
+		return samples, rows.Err()
+	}
+}
+
+// monitorSamples reads a monitor's check history, with value computed by the
+// given SQL expression over monitor_logs.
+func monitorSamples(monitorID int64, value string) sampleQuery {
+	return historySamples(
+		"SELECT "+value+" AS value, checked_at AS at FROM monitor_logs WHERE monitor_id = ?",
+		monitorID)
+}
+
+// nodeSamples reads a Proxmox node's metric history, with value computed by
+// the given SQL expression over node_metrics.
+func nodeSamples(serverID int64, node, value string) sampleQuery {
+	return historySamples(
+		"SELECT "+value+" AS value, sampled_at AS at FROM node_metrics WHERE server_id = ? AND node = ?",
+		serverID, node)
+}
+
+// storageSamples reads a storage's used percentage history.
+func storageSamples(serverID int64, node, storage string) sampleQuery {
+	return historySamples(
+		"SELECT CASE WHEN total > 0 THEN used * 100.0 / total END AS value, sampled_at AS at FROM storage_samples WHERE server_id = ? AND node = ? AND storage = ?",
+		serverID, node, storage)
+}
+
+// conditionHeld reports whether breach has held for the rule's for_checks
+// most recent samples and continuously for its for_duration, along with the
+// latest sample value. Rules without either setting fire on the latest sample.
+func conditionHeld(rule models.AlertRule, query sampleQuery, breach func(float64) bool) (bool, float64, error) {
+	checks := rule.ForChecks
+	if checks < 1 {
+		checks = 1
+	}
+
+	samples, err := query(time.Time{}, checks)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(samples) == 0 {
+		return false, 0, nil
+	}
+
+	current := samples[0].Value.Float64
+	if len(samples) < checks {
+		return false, current, nil
+	}
+	for _, sample := range samples {
+		if !sample.Value.Valid || !breach(sample.Value.Float64) {
+			return false, current, nil
+		}
+	}
+
+	if rule.ForDuration <= 0 {
+		return true, current, nil
+	}
+
+	now := time.Now()
+	duration := time.Duration(rule.ForDuration) * time.Second
+
+	window, err := query(now.Add(-duration-sampleLookback), 0)
+	if err != nil {
+		return false, current, err
+	}
+
+	// Walk back to the oldest sample of the breaching run; the condition has
+	// held for as long as that run spans.
+	var runStart time.Time
+	for _, sample := range window {
+		if !sample.Value.Valid || !breach(sample.Value.Float64) {
+			break
+		}
+		runStart = sample.At
+	}
+
+	return !runStart.IsZero() && now.Sub(runStart) >= duration, current, nil
+}
+
+// heldFor describes a rule's duration requirements for alert messages.
+func heldFor(rule models.AlertRule) string {
+	switch {
+	case rule.ForDuration > 0 && rule.ForChecks > 1:
+		return fmt.Sprintf(" for %s and %d checks", time.Duration(rule.ForDuration)*time.Second, rule.ForChecks)
+	case rule.ForDuration > 0:
+		return fmt.Sprintf(" for %s", time.Duration(rule.ForDuration)*time.Second)
+	case rule.ForChecks > 1:
+		return fmt.Sprintf(" for %d checks", rule.ForChecks)
+	}
+	return ""
+}
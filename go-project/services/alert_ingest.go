@@ -0,0 +1,178 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExternalAlert is an alert sent by an outside system such as Prometheus
+// Alertmanager or a monitoring script.
+type ExternalAlert struct {
+	Name        string
+	Resolved    bool
+	Severity    string
+	Message     string
+	Target      string
+	Value       float64
+	Fingerprint string
+	Labels      map[string]string
+}
+
+// IngestAlert records an external alert under the external rule named after
+// it, creating the rule on first use. Firing alerts go through the same
+// deduplication, silences and escalation as built-in rules; resolved ones
+// close the matching open alert. Alerts for disabled rules are dropped.
+func IngestAlert(a ExternalAlert) error {
+	rule, err := externalRule(a.Name)
+	if err != nil {
+		return err
+	}
+	if !rule.Enabled {
+		return nil
+	}
+
+	fingerprint := "external:" + a.Fingerprint
+	if a.Fingerprint == "" {
+		fingerprint = "external:" + labelsFingerprint(a.Name, a.Target, a.Labels)
+	}
+
+	if a.Resolved {
+		return resolveExternalAlerts(fingerprint)
+	}
+
+	message := a.Message
+	if message == "" {
+		message = a.Name
+	}
+
+	e := AlertEvaluation{
+		Fire:       true,
+		Value:      a.Value,
+		Message:    message,
+		Severity:   ExternalSeverity(a.Severity),
+		TargetName: a.Target,
+	}
+	applyRuleFormat(rule, &e, a.Labels)
+
+	raiseAlert(rule, e, fingerprint, a.Labels)
+
+	return nil
+}
+
+// resolveExternalAlerts closes the open alerts with the given fingerprint.
+func resolveExternalAlerts(fingerprint string) error {
+	rows, err := database.DB.Query(`
+		SELECT id FROM alerts WHERE fingerprint = ? AND status IN ('active', 'acknowledged')
+	`, fingerprint)
+	if err != nil {
+		return err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, id := range ids {
+		_, err := database.DB.Exec(`
+			UPDATE alerts SET status = 'resolved', resolved_at = ?, next_escalation_at = NULL
+			WHERE id = ?
+		`, now, id)
+		if err != nil {
+			return err
+		}
+		RecordAlertActivity(id, models.AlertActivityResolved, nil, "Resolved by the sending system")
+		PublishEvent(EventAlertResolved, AlertStatusChange{ID: id, Status: models.AlertStatusResolved, At: now.UTC()})
+	}
+
+	return nil
+}
+
+// externalRule returns the external rule with the given name, creating an
+// enabled one if it does not exist yet.
+func externalRule(name string) (models.AlertRule, error) {
+	rule, err := loadExternalRule(name)
+	if err != sql.ErrNoRows {
+		return rule, err
+	}
+
+	// OR IGNORE covers a concurrent ingest creating the same rule.
+	_, err = database.DB.Exec(`
+		INSERT OR IGNORE INTO alert_rules (name, type, target_id, condition_type, threshold, enabled)
+		VALUES (?, 'external', 0, 'external', 0, 1)
+	`, name)
+	if err != nil {
+		return rule, fmt.Errorf("failed to create external rule %q: %w", name, err)
+	}
+
+	return loadExternalRule(name)
+}
+
+func loadExternalRule(name string) (models.AlertRule, error) {
+	var rule models.AlertRule
+	var policyID sql.NullInt64
+	var tiers string
+	err := database.DB.QueryRow(`
+		SELECT id, name, type, target_id, condition_type, enabled, escalation_policy_id,
+		       severity, severity_tiers, message_template
+		FROM alert_rules WHERE type = 'external' AND name = ?
+	`, name).Scan(&rule.ID, &rule.Name, &rule.Type, &rule.TargetID, &rule.ConditionType, &rule.Enabled, &policyID,
+		&rule.Severity, &tiers, &rule.MessageTemplate)
+	if err != nil {
+		return rule, err
+	}
+	if policyID.Valid {
+		rule.EscalationPolicyID = &policyID.Int64
+	}
+	rule.SeverityTiers = DecodeSeverityTiers(tiers)
+	return rule, nil
+}
+
+// ExternalSeverity maps the severity names used by common alerting tools onto
+// alert severities, defaulting to medium.
+func ExternalSeverity(severity string) models.AlertSeverity {
+	switch strings.ToLower(severity) {
+	case "critical", "page", "emergency", "fatal":
+		return models.AlertSeverityCritical
+	case "high", "error", "major":
+		return models.AlertSeverityHigh
+	case "medium", "warning", "warn", "minor":
+		return models.AlertSeverityMedium
+	case "low":
+		return models.AlertSeverityLow
+	case "info", "informational", "none":
+		return models.AlertSeverityInfo
+	}
+	return models.AlertSeverityMedium
+}
+
+// labelsFingerprint derives a stable fingerprint for senders that do not
+// provide one.
+func labelsFingerprint(name, target string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s", name, target)
+	for _, k := range keys {
+		fmt.Fprintf(h, "\x00%s=%s", k, labels[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
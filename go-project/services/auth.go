@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"go-project/database"
@@ -91,6 +92,150 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	// Terminal and stream tickets are signed with the same key but only open
+	// terminals and streams.
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+const (
+	// terminalTicketAudience marks a JWT as a terminal ticket.
+	terminalTicketAudience = "terminal"
+	// streamTicketAudience marks a JWT as an event or task log stream ticket.
+	streamTicketAudience = "stream"
+	// ticketTTL is how long a terminal or stream ticket can be used. Browsers
+	// connect right after fetching one.
+	ticketTTL = 30 * time.Second
+)
+
+// TerminalTarget is what a terminal ticket opens: the shell of a node, or the
+// console of the guest VMID when it is set.
+type TerminalTarget struct {
+	ServerID  int64  `json:"server_id"`
+	Node      string `json:"node"`
+	GuestType string `json:"guest_type,omitempty"`
+	VMID      int    `json:"vmid,omitempty"`
+}
+
+// TerminalClaims authorize their user to open one terminal.
+type TerminalClaims struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+	TerminalTarget
+	jwt.RegisteredClaims
+}
+
+// StreamClaims authorize their user to open one event or task log stream.
+type StreamClaims struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+var (
+	// usedTickets holds the IDs of redeemed tickets until they expire, so
+	// each ticket opens a single connection.
+	usedTickets   = map[string]time.Time{}
+	usedTicketsMu sync.Mutex
+)
+
+// ticketClaims returns the registered claims of a new single-use ticket for
+// audience.
+func ticketClaims(audience string) (jwt.RegisteredClaims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return jwt.RegisteredClaims{}, err
+	}
+
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        hex.EncodeToString(id),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ticketTTL)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}, nil
+}
+
+// redeemTicket validates a ticket for audience into claims and marks it used.
+func redeemTicket(ticket, audience string, claims jwt.Claims, registered *jwt.RegisteredClaims) error {
+	token, err := jwt.ParseWithClaims(ticket, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	}, jwt.WithAudience(audience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return err
+	}
+	if !token.Valid || registered.ID == "" {
+		return errors.New("invalid ticket")
+	}
+
+	usedTicketsMu.Lock()
+	defer usedTicketsMu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range usedTickets {
+		if now.After(expiresAt) {
+			delete(usedTickets, id)
+		}
+	}
+	if _, used := usedTickets[registered.ID]; used {
+		return errors.New("ticket already used")
+	}
+	usedTickets[registered.ID] = registered.ExpiresAt.Time
+	return nil
+}
+
+// GenerateTerminalTicket issues a short-lived ticket to open a terminal on
+// target. WebSockets cannot send headers, so the ticket is passed in the URL
+// in place of the access token.
+func GenerateTerminalTicket(userID int64, role string, target TerminalTarget) (string, time.Time, error) {
+	registered, err := ticketClaims(terminalTicketAudience)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims := &TerminalClaims{
+		UserID:           userID,
+		Role:             role,
+		TerminalTarget:   target,
+		RegisteredClaims: registered,
+	}
+	ticket, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	return ticket, registered.ExpiresAt.Time, err
+}
+
+// RedeemTerminalTicket validates a terminal ticket and marks it used.
+func RedeemTerminalTicket(ticket string) (*TerminalClaims, error) {
+	claims := &TerminalClaims{}
+	if err := redeemTicket(ticket, terminalTicketAudience, claims, &claims.RegisteredClaims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateStreamTicket issues a short-lived ticket to open one event stream
+// or task log stream. EventSource and browser WebSockets cannot send headers,
+// so the ticket is passed in the URL in place of the access token, which
+// would otherwise end up in access logs.
+func GenerateStreamTicket(userID int64, role string) (string, time.Time, error) {
+	registered, err := ticketClaims(streamTicketAudience)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims := &StreamClaims{UserID: userID, Role: role, RegisteredClaims: registered}
+	ticket, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	return ticket, registered.ExpiresAt.Time, err
+}
+
+// RedeemStreamTicket validates a stream ticket and marks it used.
+func RedeemStreamTicket(ticket string) (*StreamClaims, error) {
+	claims := &StreamClaims{}
+	if err := redeemTicket(ticket, streamTicketAudience, claims, &claims.RegisteredClaims); err != nil {
+		return nil, err
+	}
 	return claims, nil
 }
 
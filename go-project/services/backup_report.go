@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"go-project/models"
+	"math"
+	"strings"
+	"time"
+)
+
+// BuildBackupReport checks every guest of every Proxmox server for a backup
+// newer than maxAgeDays. Servers that cannot be reached are listed as errors
+// rather than failing the report.
+func BuildBackupReport(maxAgeDays int) (models.BackupCoverageReport, error) {
+	now := time.Now().UTC()
+	report := models.BackupCoverageReport{
+		GeneratedAt: now,
+		MaxAgeDays:  maxAgeDays,
+		Uncovered:   []models.GuestBackupStatus{},
+		Errors:      []models.ServerError{},
+	}
+
+	servers, err := GetProxmoxServers()
+	if err != nil {
+		return report, err
+	}
+
+	cutoff := now.Add(-time.Duration(maxAgeDays) * 24 * time.Hour)
+	for _, s := range servers {
+		guests, err := serverBackupStatus(s)
+		if err != nil {
+			report.Errors = append(report.Errors, models.ServerError{
+				ServerID: s.ID, ServerName: s.Name, Error: err.Error(),
+			})
+			continue
+		}
+
+		for _, guest := range guests {
+			report.TotalGuests++
+			if guest.LastBackup != nil {
+				age := math.Round(now.Sub(*guest.LastBackup).Hours()/24*10) / 10
+				guest.AgeDays = &age
+				if guest.LastBackup.After(cutoff) {
+					report.CoveredCount++
+					continue
+				}
+			}
+			report.Uncovered = append(report.Uncovered, guest)
+		}
+	}
+
+	return report, nil
+}
+
+// serverBackupStatus returns the guests on every node of a server's cluster
+// with the time of each one's latest backup on any backup storage.
+func serverBackupStatus(s models.Server) ([]models.GuestBackupStatus, error) {
+	client := ProxmoxClientFor(s)
+
+	clusterGuests, err := client.GetClusterGuests()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := client.GetOnlineNodes()
+	if err != nil {
+		return nil, err
+	}
+	backups, err := client.GetClusterBackups(nodes, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[int]int64{}
+	for _, backup := range backups {
+		if backup.CTime > latest[backup.VMID] {
+			latest[backup.VMID] = backup.CTime
+		}
+	}
+
+	guests := []models.GuestBackupStatus{}
+	for _, g := range clusterGuests {
+		guest := models.GuestBackupStatus{
+			ServerID: s.ID, ServerName: s.Name, Node: g.Node, VMID: g.VMID, Name: g.Name, Type: g.Type,
+		}
+		if ctime, ok := latest[g.VMID]; ok {
+			t := time.Unix(ctime, 0).UTC()
+			guest.LastBackup = &t
+		}
+		guests = append(guests, guest)
+	}
+	return guests, nil
+}
+
+// BackupEvidence summarises a backup report as compliance evidence.
+func BackupEvidence(report models.BackupCoverageReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Backup coverage report generated %s: %d of %d guests have a backup newer than %d days.\n",
+		report.GeneratedAt.Format("2006-01-02 15:04 MST"), report.CoveredCount, report.TotalGuests, report.MaxAgeDays)
+
+	if len(report.Uncovered) > 0 {
+		b.WriteString("\nWithout a recent backup:\n")
+		for _, guest := range report.Uncovered {
+			last := "never backed up"
+			if guest.LastBackup != nil {
+				last = "last backup " + guest.LastBackup.Format("2006-01-02")
+			}
+			fmt.Fprintf(&b, "- %s %d %s on %s (%s): %s\n", guest.Type, guest.VMID, guest.Name, guest.ServerName, guest.Node, last)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		b.WriteString("\nServers that could not be checked:\n")
+		for _, e := range report.Errors {
+			fmt.Fprintf(&b, "- %s: %s\n", e.ServerName, e.Error)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// escalationGroupWindow is how far ahead of its schedule an alert may be
+// sent to join a group that is already due, so alerts a rule raises in one
+// check cycle are not split across ticks.
+const escalationGroupWindow = 30 * time.Second
+
+type EscalationService struct {
+	stopChan chan struct{}
+}
+
+var (
+	escalationService *EscalationService
+	escalationOnce    sync.Once
+)
+
+func GetEscalationService() *EscalationService {
+	escalationOnce.Do(func() {
+		escalationService = &EscalationService{
+			stopChan: make(chan struct{}),
+		}
+	})
+	return escalationService
+}
+
+func (s *EscalationService) Start() {
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		s.processDue()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.processDue()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Escalation service started")
+}
+
+func (s *EscalationService) Stop() {
+	close(s.stopChan)
+}
+
+// ScheduleEscalation attaches a policy to a new alert and schedules its first
+// step.
+func ScheduleEscalation(alertID, policyID int64) error {
+	step, err := nextEscalationStep(policyID, 0)
+	if err != nil {
+		return err
+	}
+	if step == nil {
+		return nil
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE alerts SET escalation_policy_id = ?, escalation_step = 0, next_escalation_at = ?
+		WHERE id = ?
+	`, policyID, time.Now().Add(time.Duration(step.DelayMinutes)*time.Minute), alertID)
+	return err
+}
+
+// StopEscalation cancels any pending escalation steps for an alert.
+func StopEscalation(alertID int64) error {
+	_, err := database.DB.Exec("UPDATE alerts SET next_escalation_at = NULL WHERE id = ?", alertID)
+	return err
+}
+
+func (s *EscalationService) processDue() {
+	now := time.Now()
+
+	rows, err := database.DB.Query(`
+		SELECT id, alert_rule_id, type, severity, message, current_value, target_id, COALESCE(target_name, ''),
+		       escalation_policy_id, escalation_step, next_escalation_at, occurrence_count, created_at
+		FROM alerts
+		WHERE status = 'active'
+		AND escalation_policy_id IS NOT NULL
+		AND next_escalation_at IS NOT NULL
+		AND next_escalation_at <= ?
+		ORDER BY created_at ASC
+	`, now.Add(escalationGroupWindow))
+	if err != nil {
+		log.Printf("[ESCALATION] Failed to fetch due alerts: %v", err)
+		return
+	}
+
+	// Alerts from the same rule waiting on the same step are sent as one
+	// notification, so a rule firing for many targets pages once.
+	type groupKey struct {
+		ruleID, policyID int64
+		step             int
+	}
+	var order []groupKey
+	groups := map[groupKey][]models.Alert{}
+	due := map[groupKey]bool{}
+
+	for rows.Next() {
+		var a models.Alert
+		var policyID int64
+		var nextAt time.Time
+		if err := rows.Scan(&a.ID, &a.AlertRuleID, &a.Type, &a.Severity, &a.Message, &a.CurrentValue, &a.TargetID, &a.TargetName,
+			&policyID, &a.EscalationStep, &nextAt, &a.OccurrenceCount, &a.CreatedAt); err != nil {
+			continue
+		}
+		a.EscalationPolicyID = &policyID
+		a.NextEscalationAt = &nextAt
+
+		key := groupKey{a.AlertRuleID, policyID, a.EscalationStep}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], a)
+		if !nextAt.After(now) {
+			due[key] = true
+		}
+	}
+	rows.Close()
+
+	for _, key := range order {
+		if due[key] {
+			s.escalate(groups[key])
+		}
+	}
+}
+
+// escalate runs the next step for a group of alerts sharing a rule, policy
+// and current step.
+func (s *EscalationService) escalate(group []models.Alert) {
+	// A silenced alert keeps its schedule so escalation resumes once the
+	// silence expires.
+	var alerts []models.Alert
+	for _, alert := range group {
+		silence, err := MatchSilence(alert.AlertRuleID, string(alert.Type), alert.Severity, alert.TargetID, alert.TargetName)
+		if err != nil {
+			log.Printf("[ESCALATION] Failed to match silences for alert %d: %v", alert.ID, err)
+		}
+		if silence != nil {
+			database.DB.Exec("UPDATE alerts SET silence_id = ? WHERE id = ?", silence.ID, alert.ID)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	policyID := *alerts[0].EscalationPolicyID
+
+	step, err := nextEscalationStep(policyID, alerts[0].EscalationStep)
+	if err != nil {
+		log.Printf("[ESCALATION] Failed to load next step for alert %d: %v", alerts[0].ID, err)
+		return
+	}
+	if step == nil {
+		for _, alert := range alerts {
+			StopEscalation(alert.ID)
+		}
+		return
+	}
+
+	deliverEscalationStep(alerts, *step)
+
+	var nextAt interface{}
+	following, err := nextEscalationStep(policyID, step.StepOrder)
+	if err != nil {
+		log.Printf("[ESCALATION] Failed to load following step for policy %d: %v", policyID, err)
+	}
+	if following != nil {
+		nextAt = time.Now().Add(time.Duration(following.DelayMinutes) * time.Minute)
+	}
+
+	for _, alert := range alerts {
+		// The status check keeps an acknowledgement that raced this step from
+		// being undone.
+		_, err = database.DB.Exec(`
+			UPDATE alerts SET escalation_step = ?, next_escalation_at = ?
+			WHERE id = ? AND status = 'active'
+		`, step.StepOrder, nextAt, alert.ID)
+		if err != nil {
+			log.Printf("[ESCALATION] Failed to advance alert %d: %v", alert.ID, err)
+		}
+	}
+}
+
+// deliverEscalationStep sends one notification for a group of alerts to a
+// step's target and records the attempt in each alert's escalation history.
+func deliverEscalationStep(alerts []models.Alert, step models.EscalationStep) {
+	n := alertNotification(alerts, step.StepOrder)
+
+	var recipient string
+	var err error
+
+	switch step.TargetType {
+	case models.EscalationTargetUser:
+		recipient, err = NotifyUser(step.TargetID, n)
+	case models.EscalationTargetChannel:
+		recipient, err = NotifyChannel(step.TargetID, n)
+	case models.EscalationTargetSchedule:
+		var userID int64
+		userID, err = CurrentOnCall(step.TargetID, time.Now())
+		if err == nil && userID == 0 {
+			err = errors.New("nobody is on call")
+		}
+		if err == nil {
+			recipient, err = NotifyUser(userID, n)
+		}
+	default:
+		err = fmt.Errorf("unknown target type: %s", step.TargetType)
+	}
+
+	status := "sent"
+	var errMsg string
+	if err != nil {
+		status = "failed"
+		errMsg = err.Error()
+		log.Printf("[ESCALATION] Step %d for alerts %v failed: %v", step.StepOrder, n.AlertIDs, err)
+	}
+
+	for _, alert := range alerts {
+		_, err = database.DB.Exec(`
+			INSERT INTO alert_escalations (alert_id, step_order, target_type, target_id, recipient, status, error, notified_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, alert.ID, step.StepOrder, string(step.TargetType), step.TargetID, recipient, status, errMsg, time.Now())
+		if err != nil {
+			log.Printf("[ESCALATION] Failed to record step %d for alert %d: %v", step.StepOrder, alert.ID, err)
+		}
+	}
+}
+
+// NotifyAlertOwner notifies the user an alert's target is assigned to, given
+// by username or email, and records it in the alert's escalation history as
+// step 0.
+func NotifyAlertOwner(alert models.Alert, owner string) {
+	var userID int64
+	err := database.DB.QueryRow(`
+		SELECT id FROM users WHERE username = ? OR email = ? LIMIT 1
+	`, owner, owner).Scan(&userID)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("owner %q is not a known user", owner)
+	}
+
+	notifyAlertUser(alert, userID, alertNotification([]models.Alert{alert}, 0), err, "Owner")
+}
+
+// NotifyAlertAssignee tells a user an alert has been assigned to them, and
+// records it in the alert's escalation history as step 0.
+func NotifyAlertAssignee(alert models.Alert, userID int64, assignedBy string) {
+	n := alertNotification([]models.Alert{alert}, 0)
+	n.Subject = "[ASSIGNED] " + n.Subject
+	n.Body = fmt.Sprintf("%s assigned this alert to you.\n\n%s", assignedBy, n.Body)
+
+	notifyAlertUser(alert, userID, n, nil, "Assignee")
+}
+
+// notifyAlertUser sends n to a user unless lookupErr is set, and records the
+// attempt as step 0. kind names the notification in logs.
+func notifyAlertUser(alert models.Alert, userID int64, n Notification, lookupErr error, kind string) {
+	var recipient string
+	err := lookupErr
+	if err == nil {
+		recipient, err = NotifyUser(userID, n)
+	}
+
+	status := "sent"
+	var errMsg string
+	if err != nil {
+		status = "failed"
+		errMsg = err.Error()
+		log.Printf("[ESCALATION] %s notification for alert %d failed: %v", kind, alert.ID, err)
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO alert_escalations (alert_id, step_order, target_type, target_id, recipient, status, error, notified_at)
+		VALUES (?, 0, ?, ?, ?, ?, ?, ?)
+	`, alert.ID, string(models.EscalationTargetUser), userID, recipient, status, errMsg, time.Now())
+	if err != nil {
+		log.Printf("[ESCALATION] Failed to record %s notification for alert %d: %v", strings.ToLower(kind), alert.ID, err)
+	}
+}
+
+// nextEscalationStep returns the step following afterOrder, or nil when the
+// policy has no more steps.
+func nextEscalationStep(policyID int64, afterOrder int) (*models.EscalationStep, error) {
+	var step models.EscalationStep
+	err := database.DB.QueryRow(`
+		SELECT id, policy_id, step_order, delay_minutes, target_type, target_id
+		FROM escalation_steps
+		WHERE policy_id = ? AND step_order > ?
+		ORDER BY step_order ASC
+		LIMIT 1
+	`, policyID, afterOrder).Scan(&step.ID, &step.PolicyID, &step.StepOrder, &step.DelayMinutes, &step.TargetType, &step.TargetID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// alertNotification describes one alert, or a group of alerts from the same
+// rule, for delivery at the given escalation step (0 for owner notifications).
+func alertNotification(alerts []models.Alert, stepOrder int) Notification {
+	first := alerts[0]
+	severity := first.Severity
+	ids := make([]int64, 0, len(alerts))
+
+	var body strings.Builder
+	if len(alerts) == 1 {
+		fmt.Fprintf(&body, "%s\n\n", first.Message)
+		fmt.Fprintf(&body, "Severity: %s\n", first.Severity)
+		if first.TargetName != "" {
+			fmt.Fprintf(&body, "Target: %s\n", first.TargetName)
+		}
+		fmt.Fprintf(&body, "Triggered: %s\n", first.CreatedAt.Format(time.RFC1123))
+		if first.OccurrenceCount > 1 {
+			fmt.Fprintf(&body, "Occurrences: %d\n", first.OccurrenceCount)
+		}
+		ids = append(ids, first.ID)
+	} else {
+		fmt.Fprintf(&body, "%d alerts are firing:\n\n", len(alerts))
+		for _, alert := range alerts {
+			fmt.Fprintf(&body, "- [%s] %s (triggered %s)\n", alert.Severity, alert.Message, alert.CreatedAt.Format(time.RFC1123))
+			if severityRank(alert.Severity) > severityRank(severity) {
+				severity = alert.Severity
+			}
+			ids = append(ids, alert.ID)
+		}
+		body.WriteString("\n")
+	}
+
+	if stepOrder > 0 {
+		fmt.Fprintf(&body, "Escalation step: %d\n", stepOrder)
+	}
+	fmt.Fprintf(&body, "\nAcknowledge the alert to stop further escalation.\n")
+
+	subject := first.Message
+	if len(alerts) > 1 {
+		subject = fmt.Sprintf("%d alerts firing, including: %s", len(alerts), first.Message)
+	}
+
+	return Notification{
+		Subject:  fmt.Sprintf("[%s] %s", strings.ToUpper(string(severity)), subject),
+		Body:     body.String(),
+		Severity: string(severity),
+		AlertIDs: ids,
+	}
+}
+
+func severityRank(severity models.AlertSeverity) int {
+	switch severity {
+	case models.AlertSeverityCritical:
+		return 4
+	case models.AlertSeverityHigh:
+		return 3
+	case models.AlertSeverityMedium:
+		return 2
+	case models.AlertSeverityLow:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"go-project/models"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type EventType string
+
+const (
+	EventAlertCreated        EventType = "alert.created"
+	EventAlertAcknowledged   EventType = "alert.acknowledged"
+	EventAlertResolved       EventType = "alert.resolved"
+	EventAlertAssigned       EventType = "alert.assigned"
+	EventAlertCommented      EventType = "alert.commented"
+	EventMonitorStatusChange EventType = "monitor.status_changed"
+	EventServerStatusChange  EventType = "server.status_changed"
+	EventTaskStarted         EventType = "task.started"
+	EventTaskFinished        EventType = "task.finished"
+	EventInventoryChanged    EventType = "inventory.changed"
+)
+
+// Event is a change pushed to dashboard clients over the event stream.
+type Event struct {
+	Type EventType   `json:"type"`
+	Data interface{} `json:"data"`
+	Time time.Time   `json:"time"`
+}
+
+// AlertStatusChange is the payload of alert.acknowledged and alert.resolved
+// events.
+type AlertStatusChange struct {
+	ID     int64              `json:"id"`
+	Status models.AlertStatus `json:"status"`
+	By     string             `json:"by,omitempty"`
+	At     time.Time          `json:"at"`
+}
+
+// AlertAssignment is the payload of alert.assigned events. AssignedTo is nil
+// when the alert was unassigned.
+type AlertAssignment struct {
+	ID                 int64  `json:"id"`
+	AssignedTo         *int64 `json:"assigned_to"`
+	AssignedToUsername string `json:"assigned_to_username,omitempty"`
+	By                 string `json:"by"`
+}
+
+// StatusChange is the payload of monitor.status_changed and
+// server.status_changed events.
+type StatusChange struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Previous string `json:"previous"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+}
+
+// eventBufferSize is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const eventBufferSize = 64
+
+// EventBus fans events out to every connected stream. Publishing never
+// blocks, so a slow client cannot hold up the monitor or alert checker.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+var (
+	eventBus     *EventBus
+	eventBusOnce sync.Once
+)
+
+func GetEventBus() *EventBus {
+	eventBusOnce.Do(func() {
+		eventBus = &EventBus{subscribers: make(map[chan Event]struct{})}
+	})
+	return eventBus
+}
+
+// Subscribe returns a channel receiving every published event and a function
+// that must be called to stop receiving them.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *EventBus) Publish(eventType EventType, data interface{}) {
+	event := Event{Type: eventType, Data: data, Time: time.Now().UTC()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// PublishServerStatusChange publishes a server.status_changed event when
+// status differs from the previous one.
+func PublishServerStatusChange(id int64, name, previous, status string) {
+	if status == previous {
+		return
+	}
+	PublishEvent(EventServerStatusChange, StatusChange{ID: id, Name: name, Previous: previous, Status: status})
+}
+
+// PublishEvent publishes on the shared event bus.
+func PublishEvent(eventType EventType, data interface{}) {
+	GetEventBus().Publish(eventType, data)
+}
+
+// eventHeartbeat is how often idle streams are pinged so proxies do not close
+// them.
+const eventHeartbeat = 30 * time.Second
+
+// StreamEvents upgrades the request to a WebSocket and writes each event
+// accepted by filter as a JSON text message until the client disconnects.
+func StreamEvents(w http.ResponseWriter, r *http.Request, filter func(EventType) bool) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[EVENTS] WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := GetEventBus().Subscribe()
+	defer unsubscribe()
+
+	// Clients only listen, but reading is needed to notice them closing.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(eventHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			if !filter(event.Type) {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,577 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// InventorySyncInterval is how often the inventory is synced. The
+	// infrastructure overview reads the inventory, so this is also how stale
+	// its usage figures may be.
+	InventorySyncInterval = time.Minute
+	// InventoryStaleAfter is how long after its last sync a node's status is
+	// no longer trusted.
+	InventoryStaleAfter = 3 * InventorySyncInterval
+)
+
+// InventorySyncer keeps the inventory tables in step with the nodes and
+// guests of every Proxmox server and records what changed.
+type InventorySyncer struct {
+	stopChan chan struct{}
+}
+
+var (
+	inventorySyncer     *InventorySyncer
+	inventorySyncerOnce sync.Once
+)
+
+func GetInventorySyncer() *InventorySyncer {
+	inventorySyncerOnce.Do(func() {
+		inventorySyncer = &InventorySyncer{
+			stopChan: make(chan struct{}),
+		}
+	})
+	return inventorySyncer
+}
+
+func (s *InventorySyncer) Start() {
+	go func() {
+		ticker := time.NewTicker(InventorySyncInterval)
+		defer ticker.Stop()
+
+		s.Sync()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.Sync()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Inventory syncer started")
+}
+
+func (s *InventorySyncer) Stop() {
+	close(s.stopChan)
+}
+
+// Sync syncs every Proxmox server. Servers that cannot be reached keep their
+// inventory as last seen.
+func (s *InventorySyncer) Sync() {
+	servers, err := GetProxmoxServers()
+	if err != nil {
+		log.Printf("[INVENTORY] Failed to fetch servers: %v", err)
+		return
+	}
+
+	for _, srv := range servers {
+		if err := SyncInventory(srv); err != nil {
+			log.Printf("[INVENTORY] Failed to sync server %d: %v", srv.ID, err)
+		}
+	}
+}
+
+// inventoryGuestConfig is what a guest's config hash covers.
+type inventoryGuestConfig struct {
+	Name     string
+	Template bool
+	MaxCPU   int
+	MaxMem   int64
+	MaxDisk  int64
+	Pool     string
+	Tags     string
+}
+
+func guestConfigOf(r ProxmoxClusterResource) inventoryGuestConfig {
+	return inventoryGuestConfig{
+		Name:     r.Name,
+		Template: r.Template == 1,
+		MaxCPU:   int(r.MaxCPU),
+		MaxMem:   int64(r.MaxMem),
+		MaxDisk:  int64(r.MaxDisk),
+		Pool:     r.Pool,
+		Tags:     r.Tags,
+	}
+}
+
+func (g inventoryGuestConfig) hash() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%t\x00%d\x00%d\x00%d\x00%s\x00%s",
+		g.Name, g.Template, g.MaxCPU, g.MaxMem, g.MaxDisk, g.Pool, g.Tags)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (g inventoryGuestConfig) size() string {
+	return fmt.Sprintf("%d CPU, %s memory, %s disk", g.MaxCPU, formatGiB(g.MaxMem), formatGiB(g.MaxDisk))
+}
+
+// settings describes what guest_reconfigured events compare.
+func (g inventoryGuestConfig) settings() string {
+	parts := []string{}
+	if g.Template {
+		parts = append(parts, "template")
+	}
+	if g.Pool != "" {
+		parts = append(parts, "pool "+g.Pool)
+	}
+	if g.Tags != "" {
+		parts = append(parts, "tags "+g.Tags)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func nodeConfigHash(maxCPU int, maxMem, maxDisk int64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%d\x00%d", maxCPU, maxMem, maxDisk)))
+	return hex.EncodeToString(sum[:])
+}
+
+func nodeSize(maxCPU int, maxMem int64) string {
+	return fmt.Sprintf("%d CPU, %s memory", maxCPU, formatGiB(maxMem))
+}
+
+func formatGiB(bytes int64) string {
+	return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+}
+
+type inventoryNodeRow struct {
+	id         int64
+	maxCPU     int
+	maxMem     int64
+	configHash string
+}
+
+type inventoryGuestRow struct {
+	id         int64
+	guestType  string
+	node       string
+	config     inventoryGuestConfig
+	configHash string
+}
+
+// SyncInventory brings a server's inventory in step with Proxmox and records
+// an event for each change. The first sync of a server only records what
+// exists. Nodes and guests Proxmox cannot report on, such as those of an
+// offline node, keep their last known size.
+func SyncInventory(srv models.Server) error {
+	resources, err := ProxmoxClientFor(srv).GetClusterResources("")
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var synced int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM inventory_nodes WHERE server_id = ?", srv.ID).Scan(&synced); err != nil {
+		return err
+	}
+	nodes, err := currentInventoryNodes(tx, srv.ID)
+	if err != nil {
+		return err
+	}
+	guests, err := currentInventoryGuests(tx, srv.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var events []models.InventoryEvent
+	record := func(event models.InventoryEventType, node, guestType string, vmid int, name, oldValue, newValue string) {
+		events = append(events, models.InventoryEvent{
+			ServerID: srv.ID, ServerName: srv.Name, Event: event, Node: node, GuestType: guestType, VMID: vmid,
+			Name: name, OldValue: oldValue, NewValue: newValue, OccurredAt: now,
+		})
+	}
+
+	seenNodes := map[string]bool{}
+	seenGuests := map[int]bool{}
+	for _, r := range resources {
+		switch r.Type {
+		case "node":
+			seenNodes[r.Node] = true
+			maxCPU, maxMem, maxDisk := int(r.MaxCPU), int64(r.MaxMem), int64(r.MaxDisk)
+			hash := nodeConfigHash(maxCPU, maxMem, maxDisk)
+
+			prev, ok := nodes[r.Node]
+			if !ok {
+				// Offline nodes report no size, so their hash is left empty
+				// until they come online.
+				if r.Status != "online" {
+					hash = ""
+				}
+				_, err := tx.Exec(`
+					INSERT INTO inventory_nodes (server_id, node, status, cpu, maxcpu, mem, maxmem, disk, maxdisk, uptime,
+						config_hash, first_seen, last_seen)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				`, srv.ID, r.Node, r.Status, r.CPU, maxCPU, r.Mem, maxMem, r.Disk, maxDisk, r.Uptime, hash, now, now)
+				if err != nil {
+					return err
+				}
+				record(models.InventoryNodeAdded, r.Node, "", 0, r.Node, "", nodeSize(maxCPU, maxMem))
+				continue
+			}
+
+			if r.Status != "online" {
+				_, err := tx.Exec("UPDATE inventory_nodes SET status = ?, last_seen = ? WHERE id = ?", r.Status, now, prev.id)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if hash != prev.configHash && prev.configHash != "" {
+				record(models.InventoryNodeResized, r.Node, "", 0, r.Node,
+					nodeSize(prev.maxCPU, prev.maxMem), nodeSize(maxCPU, maxMem))
+			}
+			_, err := tx.Exec(`
+				UPDATE inventory_nodes SET status = ?, cpu = ?, maxcpu = ?, mem = ?, maxmem = ?, disk = ?, maxdisk = ?,
+					uptime = ?, config_hash = ?, last_seen = ?
+				WHERE id = ?
+			`, r.Status, r.CPU, maxCPU, r.Mem, maxMem, r.Disk, maxDisk, r.Uptime, hash, now, prev.id)
+			if err != nil {
+				return err
+			}
+
+		case GuestQEMU, GuestLXC:
+			seenGuests[r.VMID] = true
+			config := guestConfigOf(r)
+			// Guests of unreachable nodes are listed with an unknown status
+			// and no configuration.
+			known := r.Status != "unknown"
+
+			prev, ok := guests[r.VMID]
+			if ok && prev.guestType != r.Type {
+				// The VMID was reused by a guest of the other type.
+				if err := deleteInventoryGuest(tx, prev.id, now); err != nil {
+					return err
+				}
+				record(models.InventoryGuestDeleted, prev.node, prev.guestType, r.VMID, prev.config.Name, prev.config.size(), "")
+				ok = false
+			}
+
+			if !ok {
+				hash := ""
+				if known {
+					hash = config.hash()
+				}
+				_, err := tx.Exec(`
+					INSERT INTO inventory_guests (server_id, vmid, guest_type, name, node, status, template, maxcpu, maxmem,
+						maxdisk, pool, tags, config_hash, first_seen, last_seen)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				`, srv.ID, r.VMID, r.Type, config.Name, r.Node, r.Status, config.Template, config.MaxCPU, config.MaxMem,
+					config.MaxDisk, config.Pool, config.Tags, hash, now, now)
+				if err != nil {
+					return err
+				}
+				record(models.InventoryGuestCreated, r.Node, r.Type, r.VMID, config.Name, "", config.size())
+				continue
+			}
+
+			if r.Node != prev.node {
+				record(models.InventoryGuestMigrated, r.Node, r.Type, r.VMID, prev.config.Name, prev.node, r.Node)
+			}
+			if !known {
+				_, err := tx.Exec("UPDATE inventory_guests SET node = ?, status = ?, last_seen = ? WHERE id = ?",
+					r.Node, r.Status, now, prev.id)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			hash := config.hash()
+			if hash != prev.configHash && prev.configHash != "" {
+				if config.Name != prev.config.Name {
+					record(models.InventoryGuestRenamed, r.Node, r.Type, r.VMID, config.Name, prev.config.Name, config.Name)
+				}
+				if oldSize, newSize := prev.config.size(), config.size(); oldSize != newSize {
+					record(models.InventoryGuestResized, r.Node, r.Type, r.VMID, config.Name, oldSize, newSize)
+				}
+				if oldSettings, newSettings := prev.config.settings(), config.settings(); oldSettings != newSettings {
+					record(models.InventoryGuestReconfigured, r.Node, r.Type, r.VMID, config.Name, oldSettings, newSettings)
+				}
+			}
+			_, err := tx.Exec(`
+				UPDATE inventory_guests SET name = ?, node = ?, status = ?, template = ?, maxcpu = ?, maxmem = ?,
+					maxdisk = ?, pool = ?, tags = ?, config_hash = ?, last_seen = ?
+				WHERE id = ?
+			`, config.Name, r.Node, r.Status, config.Template, config.MaxCPU, config.MaxMem, config.MaxDisk,
+				config.Pool, config.Tags, hash, now, prev.id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, prev := range nodes {
+		if seenNodes[name] {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE inventory_nodes SET removed_at = ? WHERE id = ?", now, prev.id); err != nil {
+			return err
+		}
+		record(models.InventoryNodeRemoved, name, "", 0, name, nodeSize(prev.maxCPU, prev.maxMem), "")
+	}
+	for vmid, prev := range guests {
+		if seenGuests[vmid] {
+			continue
+		}
+		if err := deleteInventoryGuest(tx, prev.id, now); err != nil {
+			return err
+		}
+		record(models.InventoryGuestDeleted, prev.node, prev.guestType, vmid, prev.config.Name, prev.config.size(), "")
+	}
+
+	// The first sync finds what already existed rather than changes.
+	if synced == 0 {
+		events = nil
+	}
+	for i, event := range events {
+		result, err := tx.Exec(`
+			INSERT INTO inventory_events (server_id, event, node, guest_type, vmid, name, old_value, new_value, occurred_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, event.ServerID, event.Event, event.Node, event.GuestType, event.VMID, event.Name, event.OldValue,
+			event.NewValue, event.OccurredAt)
+		if err != nil {
+			return err
+		}
+		events[i].ID, _ = result.LastInsertId()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		log.Printf("[INVENTORY] %s: %s %s %d %s", srv.Name, event.Event, event.Node, event.VMID, event.Name)
+		PublishEvent(EventInventoryChanged, event)
+	}
+	return nil
+}
+
+func currentInventoryNodes(tx *sql.Tx, serverID int64) (map[string]inventoryNodeRow, error) {
+	rows, err := tx.Query(`
+		SELECT id, node, maxcpu, maxmem, config_hash FROM inventory_nodes
+		WHERE server_id = ? AND removed_at IS NULL
+	`, serverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := map[string]inventoryNodeRow{}
+	for rows.Next() {
+		var node string
+		var row inventoryNodeRow
+		if err := rows.Scan(&row.id, &node, &row.maxCPU, &row.maxMem, &row.configHash); err != nil {
+			return nil, err
+		}
+		nodes[node] = row
+	}
+	return nodes, rows.Err()
+}
+
+func currentInventoryGuests(tx *sql.Tx, serverID int64) (map[int]inventoryGuestRow, error) {
+	rows, err := tx.Query(`
+		SELECT id, vmid, guest_type, node, name, template, maxcpu, maxmem, maxdisk, pool, tags, config_hash
+		FROM inventory_guests
+		WHERE server_id = ? AND deleted_at IS NULL
+	`, serverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	guests := map[int]inventoryGuestRow{}
+	for rows.Next() {
+		var vmid int
+		var row inventoryGuestRow
+		err := rows.Scan(&row.id, &vmid, &row.guestType, &row.node, &row.config.Name, &row.config.Template,
+			&row.config.MaxCPU, &row.config.MaxMem, &row.config.MaxDisk, &row.config.Pool, &row.config.Tags,
+			&row.configHash)
+		if err != nil {
+			return nil, err
+		}
+		guests[vmid] = row
+	}
+	return guests, rows.Err()
+}
+
+func deleteInventoryGuest(tx *sql.Tx, id int64, at time.Time) error {
+	_, err := tx.Exec("UPDATE inventory_guests SET deleted_at = ? WHERE id = ?", at, id)
+	return err
+}
+
+// InventoryFilter narrows ListInventoryNodes and ListInventoryGuests. Zero
+// values match everything; nodes and guests that are gone are only listed
+// with IncludeGone.
+type InventoryFilter struct {
+	ServerID    int64
+	Node        string
+	IncludeGone bool
+}
+
+func ListInventoryNodes(filter InventoryFilter) ([]models.InventoryNode, error) {
+	query := `
+		SELECT n.id, n.server_id, s.name, n.node, n.status, n.cpu, n.maxcpu, n.mem, n.maxmem, n.disk, n.maxdisk,
+			n.uptime, n.config_hash, n.first_seen, n.last_seen, n.removed_at
+		FROM inventory_nodes n
+		JOIN servers s ON s.id = n.server_id
+		WHERE 1=1`
+	var args []interface{}
+	if filter.ServerID != 0 {
+		query += " AND n.server_id = ?"
+		args = append(args, filter.ServerID)
+	}
+	if filter.Node != "" {
+		query += " AND n.node = ?"
+		args = append(args, filter.Node)
+	}
+	if !filter.IncludeGone {
+		query += " AND n.removed_at IS NULL"
+	}
+	query += " ORDER BY n.server_id, n.node, n.first_seen"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []models.InventoryNode{}
+	for rows.Next() {
+		var node models.InventoryNode
+		var removedAt sql.NullTime
+		err := rows.Scan(&node.ID, &node.ServerID, &node.ServerName, &node.Node, &node.Status, &node.CPU, &node.MaxCPU,
+			&node.Mem, &node.MaxMem, &node.Disk, &node.MaxDisk, &node.Uptime, &node.ConfigHash, &node.FirstSeen,
+			&node.LastSeen, &removedAt)
+		if err != nil {
+			continue
+		}
+		if removedAt.Valid {
+			node.RemovedAt = &removedAt.Time
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+func ListInventoryGuests(filter InventoryFilter) ([]models.InventoryGuest, error) {
+	query := `
+		SELECT g.id, g.server_id, s.name, g.vmid, g.guest_type, g.name, g.node, g.status, g.template, g.maxcpu,
+			g.maxmem, g.maxdisk, g.pool, g.tags, g.config_hash, g.first_seen, g.last_seen, g.deleted_at
+		FROM inventory_guests g
+		JOIN servers s ON s.id = g.server_id
+		WHERE 1=1`
+	var args []interface{}
+	if filter.ServerID != 0 {
+		query += " AND g.server_id = ?"
+		args = append(args, filter.ServerID)
+	}
+	if filter.Node != "" {
+		query += " AND g.node = ?"
+		args = append(args, filter.Node)
+	}
+	if !filter.IncludeGone {
+		query += " AND g.deleted_at IS NULL"
+	}
+	query += " ORDER BY g.server_id, g.vmid, g.first_seen"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	guests := []models.InventoryGuest{}
+	for rows.Next() {
+		var guest models.InventoryGuest
+		var deletedAt sql.NullTime
+		err := rows.Scan(&guest.ID, &guest.ServerID, &guest.ServerName, &guest.VMID, &guest.Type, &guest.Name,
+			&guest.Node, &guest.Status, &guest.Template, &guest.MaxCPU, &guest.MaxMem, &guest.MaxDisk, &guest.Pool,
+			&guest.Tags, &guest.ConfigHash, &guest.FirstSeen, &guest.LastSeen, &deletedAt)
+		if err != nil {
+			continue
+		}
+		if deletedAt.Valid {
+			guest.DeletedAt = &deletedAt.Time
+		}
+		guests = append(guests, guest)
+	}
+	return guests, rows.Err()
+}
+
+// InventoryEventFilter narrows ListInventoryEvents. Zero values match
+// everything.
+type InventoryEventFilter struct {
+	ServerID int64
+	Node     string
+	VMID     int
+	Event    models.InventoryEventType
+	Since    time.Time
+	Limit    int
+}
+
+// ListInventoryEvents lists inventory changes, newest first.
+func ListInventoryEvents(filter InventoryEventFilter) ([]models.InventoryEvent, error) {
+	query := `
+		SELECT e.id, e.server_id, s.name, e.event, e.node, e.guest_type, e.vmid, e.name, e.old_value, e.new_value,
+			e.occurred_at
+		FROM inventory_events e
+		JOIN servers s ON s.id = e.server_id
+		WHERE 1=1`
+	var args []interface{}
+	if filter.ServerID != 0 {
+		query += " AND e.server_id = ?"
+		args = append(args, filter.ServerID)
+	}
+	if filter.Node != "" {
+		// A migration is listed for the node the guest left too.
+		query += " AND (e.node = ? OR (e.event = ? AND e.old_value = ?))"
+		args = append(args, filter.Node, models.InventoryGuestMigrated, filter.Node)
+	}
+	if filter.VMID != 0 {
+		query += " AND e.vmid = ?"
+		args = append(args, filter.VMID)
+	}
+	if filter.Event != "" {
+		query += " AND e.event = ?"
+		args = append(args, filter.Event)
+	}
+	if !filter.Since.IsZero() {
+		query += " AND e.occurred_at >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+	query += " ORDER BY e.occurred_at DESC, e.id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.InventoryEvent{}
+	for rows.Next() {
+		var event models.InventoryEvent
+		err := rows.Scan(&event.ID, &event.ServerID, &event.ServerName, &event.Event, &event.Node, &event.GuestType,
+			&event.VMID, &event.Name, &event.OldValue, &event.NewValue, &event.OccurredAt)
+		if err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLicenseAlertThresholds are the days before a due date at which
+// license alerts fire, in ascending order, used when LICENSE_ALERT_THRESHOLDS
+// is unset.
+var defaultLicenseAlertThresholds = []int{7, 30, 60, 90}
+
+type LicenseAlertService struct {
+	thresholds []int
+	stopChan   chan struct{}
+}
+
+var (
+	licenseAlertService *LicenseAlertService
+	licenseAlertOnce    sync.Once
+)
+
+func GetLicenseAlertService() *LicenseAlertService {
+	licenseAlertOnce.Do(func() {
+		licenseAlertService = &LicenseAlertService{
+			thresholds: licenseAlertThresholds(),
+			stopChan:   make(chan struct{}),
+		}
+	})
+	return licenseAlertService
+}
+
+// licenseAlertThresholds parses LICENSE_ALERT_THRESHOLDS, a comma-separated
+// list of day counts, into ascending order.
+func licenseAlertThresholds() []int {
+	value := os.Getenv("LICENSE_ALERT_THRESHOLDS")
+	if value == "" {
+		return defaultLicenseAlertThresholds
+	}
+
+	var thresholds []int
+	for _, part := range strings.Split(value, ",") {
+		days, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || days < 0 {
+			log.Printf("[LICENSE] Ignoring invalid alert threshold %q", part)
+			continue
+		}
+		thresholds = append(thresholds, days)
+	}
+
+	if len(thresholds) == 0 {
+		return defaultLicenseAlertThresholds
+	}
+
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+func (s *LicenseAlertService) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		s.evaluate()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.evaluate()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Printf("License alert service started (thresholds: %v days)", s.thresholds)
+}
+
+func (s *LicenseAlertService) Stop() {
+	close(s.stopChan)
+}
+
+type licenseDates struct {
+	ID             int64
+	Name           string
+	ExpirationDate sql.NullTime
+	RenewalDate    sql.NullTime
+	NextAuditDate  sql.NullTime
+}
+
+func (s *LicenseAlertService) evaluate() {
+	rows, err := database.DB.Query(`
+		SELECT id, name, expiration_date, renewal_date, next_audit_date
+		FROM licenses
+		WHERE status != 'inactive'
+	`)
+	if err != nil {
+		log.Printf("[LICENSE] Failed to fetch licenses: %v", err)
+		return
+	}
+
+	var licenses []licenseDates
+	for rows.Next() {
+		var l licenseDates
+		if err := rows.Scan(&l.ID, &l.Name, &l.ExpirationDate, &l.RenewalDate, &l.NextAuditDate); err != nil {
+			continue
+		}
+		licenses = append(licenses, l)
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, l := range licenses {
+		if l.ExpirationDate.Valid {
+			days := daysUntil(l.ExpirationDate.Time, now)
+			if days < 0 {
+				s.raise(l, models.LicenseAlertExpired, 0, l.ExpirationDate.Time,
+					fmt.Sprintf("License %s expired on %s", l.Name, l.ExpirationDate.Time.Format("2006-01-02")))
+			} else if threshold, ok := s.crossedThreshold(days); ok {
+				s.raise(l, models.LicenseAlertExpiringSoon, threshold, l.ExpirationDate.Time,
+					fmt.Sprintf("License %s expires in %d days on %s", l.Name, days, l.ExpirationDate.Time.Format("2006-01-02")))
+			}
+		}
+
+		if l.RenewalDate.Valid {
+			days := daysUntil(l.RenewalDate.Time, now)
+			if threshold, ok := s.crossedThreshold(days); ok {
+				s.raise(l, models.LicenseAlertRenewalDue, threshold, l.RenewalDate.Time,
+					fmt.Sprintf("License %s is due for renewal in %d days on %s", l.Name, days, l.RenewalDate.Time.Format("2006-01-02")))
+			}
+		}
+
+		if l.NextAuditDate.Valid {
+			days := daysUntil(l.NextAuditDate.Time, now)
+			if threshold, ok := s.crossedThreshold(days); ok {
+				s.raise(l, models.LicenseAlertComplianceDue, threshold, l.NextAuditDate.Time,
+					fmt.Sprintf("License %s is due for a compliance audit in %d days on %s", l.Name, days, l.NextAuditDate.Time.Format("2006-01-02")))
+			}
+		}
+	}
+}
+
+// crossedThreshold returns the smallest threshold a date days away has
+// crossed. Only the most urgent one fires, so a license added 5 days before
+// expiry does not also raise its 90, 60 and 30 day alerts.
+func (s *LicenseAlertService) crossedThreshold(days int) (int, bool) {
+	if days < 0 {
+		return 0, false
+	}
+	for _, threshold := range s.thresholds {
+		if days <= threshold {
+			return threshold, true
+		}
+	}
+	return 0, false
+}
+
+// raise records a license alert once per license, type, threshold and due
+// date, and notifies the license owners when it is new.
+func (s *LicenseAlertService) raise(l licenseDates, alertType models.LicenseAlertType, threshold int, dueDate time.Time, message string) {
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		INSERT OR IGNORE INTO license_alerts (license_id, alert_type, threshold_days, due_date, message, triggered_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, l.ID, string(alertType), threshold, dueDate, message, now)
+	if err != nil {
+		log.Printf("[LICENSE] Failed to record %s alert for license %d: %v", alertType, l.ID, err)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return
+	}
+
+	log.Printf("[LICENSE] %s", message)
+	notifyLicenseOwners(l.ID, Notification{
+		Subject: fmt.Sprintf("[LICENSE] %s", message),
+		Body: fmt.Sprintf("%s\n\nAlert: %s\nDue: %s\n\nAcknowledge the alert in the license dashboard once it has been handled.\n",
+			message, alertType, dueDate.Format("2006-01-02")),
+	})
+}
+
+func notifyLicenseOwners(licenseID int64, n Notification) {
+	rows, err := database.DB.Query(`
+		SELECT user_id FROM license_users WHERE license_id = ? AND role = 'owner'
+	`, licenseID)
+	if err != nil {
+		log.Printf("[LICENSE] Failed to fetch owners of license %d: %v", licenseID, err)
+		return
+	}
+
+	var owners []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			continue
+		}
+		owners = append(owners, userID)
+	}
+	rows.Close()
+
+	if len(owners) == 0 {
+		log.Printf("[LICENSE] License %d has no owners to notify", licenseID)
+		return
+	}
+
+	for _, userID := range owners {
+		if _, err := NotifyUser(userID, n); err != nil {
+			log.Printf("[LICENSE] Failed to notify user %d about license %d: %v", userID, licenseID, err)
+		}
+	}
+}
+
+// daysUntil counts calendar days from now to date, negative once the date
+// has passed.
+func daysUntil(date, now time.Time) int {
+	due := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(due.Sub(today).Hours() / 24)
+}
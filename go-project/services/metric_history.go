@@ -0,0 +1,322 @@
+package services
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// metricCollectInterval is how often the hour timeframe is copied. It is
+	// shorter than the ~70 minutes Proxmox keeps, so one failed collection
+	// leaves no gap.
+	metricCollectInterval = 30 * time.Minute
+	// MetricMaxSamples bounds the samples of a series; longer ranges are
+	// averaged into coarser steps.
+	MetricMaxSamples = 1000
+)
+
+// metricTimeframes holds how often each timeframe is copied, well within
+// what Proxmox keeps of it, and how long its points are kept here.
+var metricTimeframes = map[string]struct {
+	refresh   time.Duration
+	retention time.Duration
+}{
+	RRDHour: {metricCollectInterval, 7 * 24 * time.Hour},
+	RRDDay:  {6 * time.Hour, 90 * 24 * time.Hour},
+	RRDWeek: {24 * time.Hour, 400 * 24 * time.Hour},
+}
+
+// MetricsCollector copies the RRD data of every node and guest of every
+// Proxmox server into metric_samples.
+type MetricsCollector struct {
+	stopChan  chan struct{}
+	collected map[string]time.Time // When each timeframe was last copied
+}
+
+var (
+	metricsCollector     *MetricsCollector
+	metricsCollectorOnce sync.Once
+)
+
+func GetMetricsCollector() *MetricsCollector {
+	metricsCollectorOnce.Do(func() {
+		metricsCollector = &MetricsCollector{
+			stopChan:  make(chan struct{}),
+			collected: map[string]time.Time{},
+		}
+	})
+	return metricsCollector
+}
+
+func (m *MetricsCollector) Start() {
+	go func() {
+		ticker := time.NewTicker(metricCollectInterval)
+		defer ticker.Stop()
+
+		m.Collect()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.Collect()
+			case <-m.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Metrics collector started")
+}
+
+func (m *MetricsCollector) Stop() {
+	close(m.stopChan)
+}
+
+// Collect copies the timeframes due for a refresh and prunes points past
+// their retention.
+func (m *MetricsCollector) Collect() {
+	now := time.Now()
+	var due []string
+	for _, timeframe := range RRDTimeframes {
+		// A little slack keeps ticker jitter from skipping a refresh.
+		if now.Sub(m.collected[timeframe]) >= metricTimeframes[timeframe].refresh-time.Minute {
+			due = append(due, timeframe)
+		}
+	}
+
+	servers, err := GetProxmoxServers()
+	if err != nil {
+		log.Printf("[METRICS] Failed to fetch servers: %v", err)
+		return
+	}
+
+	for _, srv := range servers {
+		client := ProxmoxClientFor(srv)
+		nodes, err := client.GetOnlineNodes()
+		if err != nil {
+			log.Printf("[METRICS] Failed to fetch nodes of server %d: %v", srv.ID, err)
+			continue
+		}
+		guests, err := client.GetClusterGuests()
+		if err != nil {
+			log.Printf("[METRICS] Failed to fetch guests of server %d: %v", srv.ID, err)
+			continue
+		}
+
+		online := map[string]bool{}
+		for _, node := range nodes {
+			online[node] = true
+		}
+
+		for _, timeframe := range due {
+			for _, node := range nodes {
+				points, err := client.GetNodeRRDData(node, timeframe)
+				if err != nil {
+					log.Printf("[METRICS] Failed to fetch metrics of node %s: %v", node, err)
+					continue
+				}
+				storeMetricPoints(srv.ID, node, "", 0, timeframe, points)
+			}
+			for _, guest := range guests {
+				if guest.Template == 1 || !online[guest.Node] {
+					continue
+				}
+				points, err := client.GetGuestRRDData(guest.Node, guest.Type, guest.VMID, timeframe)
+				if err != nil {
+					log.Printf("[METRICS] Failed to fetch metrics of %s %d: %v", guest.Type, guest.VMID, err)
+					continue
+				}
+				storeMetricPoints(srv.ID, "", guest.Type, guest.VMID, timeframe, points)
+			}
+		}
+	}
+
+	for _, timeframe := range due {
+		m.collected[timeframe] = now
+	}
+
+	for timeframe, settings := range metricTimeframes {
+		_, err := database.DB.Exec("DELETE FROM metric_samples WHERE timeframe = ? AND sampled_at < ?",
+			timeframe, now.Add(-settings.retention).UTC())
+		if err != nil {
+			log.Printf("[METRICS] Failed to prune %s samples: %v", timeframe, err)
+		}
+	}
+}
+
+// storeMetricPoints upserts a series' points, so the partial latest point of
+// one collection is completed by the next. Points without data are skipped.
+func storeMetricPoints(serverID int64, node, guestType string, vmid int, timeframe string, points []ProxmoxRRDPoint) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("[METRICS] Failed to store metrics: %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO metric_samples (server_id, node, guest_type, vmid, timeframe, cpu, maxcpu, mem, maxmem,
+			netin, netout, diskread, diskwrite, iowait, loadavg, sampled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(server_id, guest_type, vmid, node, timeframe, sampled_at) DO UPDATE SET
+			cpu = excluded.cpu, maxcpu = excluded.maxcpu, mem = excluded.mem, maxmem = excluded.maxmem,
+			netin = excluded.netin, netout = excluded.netout, diskread = excluded.diskread,
+			diskwrite = excluded.diskwrite, iowait = excluded.iowait, loadavg = excluded.loadavg
+	`)
+	if err != nil {
+		log.Printf("[METRICS] Failed to store metrics: %v", err)
+		return
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		if p.CPU == nil && p.Mem == nil && p.NetIn == nil {
+			continue
+		}
+		_, err := stmt.Exec(serverID, node, guestType, vmid, timeframe, p.CPU, p.MaxCPU, p.Mem, p.MaxMem,
+			p.NetIn, p.NetOut, p.DiskRead, p.DiskWrite, p.IOWait, p.LoadAvg, time.Unix(p.Time, 0).UTC())
+		if err != nil {
+			log.Printf("[METRICS] Failed to store metrics: %v", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("[METRICS] Failed to store metrics: %v", err)
+	}
+}
+
+// MetricTimeframeFor picks the finest timeframe whose points are still kept
+// for from.
+func MetricTimeframeFor(from time.Time) string {
+	for _, timeframe := range RRDTimeframes {
+		if time.Since(from) <= metricTimeframes[timeframe].retention {
+			return timeframe
+		}
+	}
+	return RRDWeek
+}
+
+// ValidMetricTimeframe reports whether timeframe is collected.
+func ValidMetricTimeframe(timeframe string) bool {
+	_, ok := metricTimeframes[timeframe]
+	return ok
+}
+
+// GetMetricHistory returns the history of a node, or of a guest when vmid is
+// not 0, between from and to in a timeframe, oldest first. Ranges holding
+// more than MetricMaxSamples points are averaged into coarser steps.
+func GetMetricHistory(serverID int64, node string, vmid int, timeframe string, from, to time.Time) (*models.MetricSeries, error) {
+	query := `
+		SELECT cpu, maxcpu, mem, maxmem, netin, netout, diskread, diskwrite, iowait, loadavg, sampled_at
+		FROM metric_samples
+		WHERE server_id = ? AND timeframe = ? AND sampled_at >= ? AND sampled_at <= ?`
+	args := []interface{}{serverID, timeframe, from.UTC(), to.UTC()}
+	if vmid != 0 {
+		query += " AND guest_type != '' AND vmid = ?"
+		args = append(args, vmid)
+	} else {
+		query += " AND guest_type = '' AND node = ?"
+		args = append(args, node)
+	}
+	query += " ORDER BY sampled_at"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []models.MetricSample{}
+	for rows.Next() {
+		var sample models.MetricSample
+		var cpu, maxCPU, mem, maxMem, netIn, netOut, diskRead, diskWrite, ioWait, loadAvg sql.NullFloat64
+		err := rows.Scan(&cpu, &maxCPU, &mem, &maxMem, &netIn, &netOut, &diskRead, &diskWrite, &ioWait, &loadAvg,
+			&sample.SampledAt)
+		if err != nil {
+			continue
+		}
+		sample.CPU, sample.MaxCPU = nullFloat(cpu), nullFloat(maxCPU)
+		sample.Mem, sample.MaxMem = nullFloat(mem), nullFloat(maxMem)
+		sample.NetIn, sample.NetOut = nullFloat(netIn), nullFloat(netOut)
+		sample.DiskRead, sample.DiskWrite = nullFloat(diskRead), nullFloat(diskWrite)
+		sample.IOWait, sample.LoadAvg = nullFloat(ioWait), nullFloat(loadAvg)
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	step := RRDStep(timeframe)
+	if span := to.Sub(from); span > step*MetricMaxSamples {
+		step = (span/MetricMaxSamples + step - 1) / step * step
+		samples = averageMetricSamples(samples, step)
+	}
+
+	return &models.MetricSeries{
+		ServerID:  serverID,
+		Node:      node,
+		VMID:      vmid,
+		Timeframe: timeframe,
+		Step:      int64(step / time.Second),
+		From:      from.UTC(),
+		To:        to.UTC(),
+		Samples:   samples,
+	}, nil
+}
+
+func nullFloat(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
+
+// averageMetricSamples averages ordered samples into buckets of step, each
+// dated at its start. Missing values are left out of a bucket's averages.
+func averageMetricSamples(samples []models.MetricSample, step time.Duration) []models.MetricSample {
+	averaged := []models.MetricSample{}
+	for start := 0; start < len(samples); {
+		bucket := samples[start].SampledAt.Truncate(step)
+		end := start
+		for end < len(samples) && samples[end].SampledAt.Truncate(step).Equal(bucket) {
+			end++
+		}
+
+		group := samples[start:end]
+		average := func(field func(models.MetricSample) *float64) *float64 {
+			var sum float64
+			var n int
+			for _, sample := range group {
+				if v := field(sample); v != nil {
+					sum += *v
+					n++
+				}
+			}
+			if n == 0 {
+				return nil
+			}
+			sum /= float64(n)
+			return &sum
+		}
+
+		averaged = append(averaged, models.MetricSample{
+			SampledAt: bucket,
+			CPU:       average(func(s models.MetricSample) *float64 { return s.CPU }),
+			MaxCPU:    average(func(s models.MetricSample) *float64 { return s.MaxCPU }),
+			Mem:       average(func(s models.MetricSample) *float64 { return s.Mem }),
+			MaxMem:    average(func(s models.MetricSample) *float64 { return s.MaxMem }),
+			NetIn:     average(func(s models.MetricSample) *float64 { return s.NetIn }),
+			NetOut:    average(func(s models.MetricSample) *float64 { return s.NetOut }),
+			DiskRead:  average(func(s models.MetricSample) *float64 { return s.DiskRead }),
+			DiskWrite: average(func(s models.MetricSample) *float64 { return s.DiskWrite }),
+			IOWait:    average(func(s models.MetricSample) *float64 { return s.IOWait }),
+			LoadAvg:   average(func(s models.MetricSample) *float64 { return s.LoadAvg }),
+		})
+		start = end
+	}
+	return averaged
+}
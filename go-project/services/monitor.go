@@ -11,6 +11,10 @@ import (
 	"time"
 )
 
+// nodeMetricsRetention bounds how much node history is kept for alert
+// evaluation.
+const nodeMetricsRetention = 24 * time.Hour
+
 type MonitorService struct {
 	stopChan chan struct{}
 }
@@ -55,7 +59,11 @@ func (s *MonitorService) Stop() {
 }
 
 func (s *MonitorService) CheckAllServers() {
-	rows, err := database.DB.Query("SELECT id, type, ip_address, port, username, password, realm, verify_ssl FROM servers")
+	rows, err := database.DB.Query(`
+		SELECT id, name, type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), COALESCE(api_token, ''),
+		       COALESCE(realm, ''), verify_ssl, COALESCE(status, '')
+		FROM servers
+	`)
 	if err != nil {
 		log.Printf("Failed to fetch servers for periodic check: %v", err)
 		return
@@ -65,18 +73,17 @@ func (s *MonitorService) CheckAllServers() {
 	var wg sync.WaitGroup
 	for rows.Next() {
 		var srv models.Server
-		var pwd string
-		if err := rows.Scan(&srv.ID, &srv.Type, &srv.IPAddress, &srv.Port, &srv.Username, &pwd, &srv.Realm, &srv.VerifySSL); err != nil {
+		if err := rows.Scan(&srv.ID, &srv.Name, &srv.Type, &srv.IPAddress, &srv.Port, &srv.Username, &srv.Password, &srv.APIToken,
+			&srv.Realm, &srv.VerifySSL, &srv.Status); err != nil {
 			continue
 		}
 
 		wg.Add(1)
-		go func(id int64, stype models.ServerType, ip string, port int, user, p, realm string, ssl bool) {
+		go func(srv models.Server) {
 			defer wg.Done()
 
-			if stype == models.ServerTypeProxmox {
-				baseURL := fmt.Sprintf("https://%s:%d", ip, port)
-				client := NewProxmoxClient(baseURL, user, p, realm, ssl)
+			if srv.Type == models.ServerTypeProxmox {
+				client := ProxmoxClientFor(srv)
 				connected, _ := client.TestConnection()
 
 				status := "inactive"
@@ -85,18 +92,50 @@ func (s *MonitorService) CheckAllServers() {
 				}
 
 				_, err := database.DB.Exec("UPDATE servers SET status = ?, last_sync = ? WHERE id = ?",
-					status, time.Now(), id)
+					status, time.Now(), srv.ID)
 				if err != nil {
-					log.Printf("Failed to update server %d status: %v", id, err)
+					log.Printf("Failed to update server %d status: %v", srv.ID, err)
+				} else {
+					PublishServerStatusChange(srv.ID, srv.Name, srv.Status, status)
+				}
+
+				if connected {
+					s.recordNodeMetrics(srv.ID, client)
 				}
 			}
-		}(srv.ID, srv.Type, srv.IPAddress, srv.Port, srv.Username, pwd, srv.Realm, srv.VerifySSL)
+		}(srv)
 	}
 	wg.Wait()
+
+	_, err = database.DB.Exec("DELETE FROM node_metrics WHERE sampled_at < ?", time.Now().Add(-nodeMetricsRetention))
+	if err != nil {
+		log.Printf("Failed to prune node metrics: %v", err)
+	}
+}
+
+// recordNodeMetrics stores a sample per node so alert rules can be evaluated
+// against history rather than a single snapshot.
+func (s *MonitorService) recordNodeMetrics(serverID int64, client *ProxmoxClient) {
+	nodes, err := client.GetNodes()
+	if err != nil {
+		log.Printf("Failed to fetch nodes for server %d: %v", serverID, err)
+		return
+	}
+
+	now := time.Now()
+	for _, node := range nodes {
+		_, err := database.DB.Exec(`
+			INSERT INTO node_metrics (server_id, node, status, cpu, mem, maxmem, sampled_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, serverID, node.Node, node.Status, node.CPU, node.Mem, node.Maxmem, now)
+		if err != nil {
+			log.Printf("Failed to record metrics for node %s: %v", node.Node, err)
+		}
+	}
 }
 
 func (s *MonitorService) CheckAllMonitors() {
-	rows, err := database.DB.Query("SELECT id, type, target FROM monitors")
+	rows, err := database.DB.Query("SELECT id, name, type, target, COALESCE(status, '') FROM monitors")
 	if err != nil {
 		log.Printf("Failed to fetch monitors: %v", err)
 		return
@@ -106,7 +145,7 @@ func (s *MonitorService) CheckAllMonitors() {
 	var wg sync.WaitGroup
 	for rows.Next() {
 		var m models.Monitor
-		if err := rows.Scan(&m.ID, &m.Type, &m.Target); err != nil {
+		if err := rows.Scan(&m.ID, &m.Name, &m.Type, &m.Target, &m.Status); err != nil {
 			continue
 		}
 		wg.Add(1)
@@ -193,5 +232,16 @@ func (s *MonitorService) CheckMonitor(m models.Monitor) {
 	`, status, time.Now(), latency, uptime, m.ID)
 	if err != nil {
 		log.Printf("Failed to update monitor status: %v", err)
+		return
+	}
+
+	if status != m.Status {
+		PublishEvent(EventMonitorStatusChange, StatusChange{
+			ID:       m.ID,
+			Name:     m.Name,
+			Previous: string(m.Status),
+			Status:   string(status),
+			Message:  message,
+		})
 	}
 }
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"io"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Notification is a message delivered to a user or notification channel.
+type Notification struct {
+	Subject  string  `json:"subject"`
+	Body     string  `json:"body"`
+	Severity string  `json:"severity,omitempty"`
+	AlertIDs []int64 `json:"alert_ids,omitempty"`
+}
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// NotifyUser emails a user from the users table and returns the address used.
+func NotifyUser(userID int64, n Notification) (string, error) {
+	var email string
+	err := database.DB.QueryRow("SELECT email FROM users WHERE id = ? AND status = 'active'", userID).Scan(&email)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user %d not found or inactive", userID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return email, sendEmail(email, n)
+}
+
+// NotifyChannel delivers to a notification channel and returns its target.
+func NotifyChannel(channelID int64, n Notification) (string, error) {
+	var ch models.NotificationChannel
+	err := database.DB.QueryRow(`
+		SELECT id, name, type, target, enabled FROM notification_channels WHERE id = ?
+	`, channelID).Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Target, &ch.Enabled)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("notification channel %d not found", channelID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !ch.Enabled {
+		return ch.Target, fmt.Errorf("notification channel %s is disabled", ch.Name)
+	}
+
+	return ch.Target, SendToChannel(ch, n)
+}
+
+// SendToChannel delivers a notification using the channel's transport.
+func SendToChannel(ch models.NotificationChannel, n Notification) error {
+	switch ch.Type {
+	case models.NotificationChannelEmail:
+		return sendEmail(ch.Target, n)
+	case models.NotificationChannelWebhook:
+		return postJSON(ch.Target, n)
+	case models.NotificationChannelSlack:
+		return postJSON(ch.Target, map[string]string{
+			"text": fmt.Sprintf("*%s*\n%s", n.Subject, n.Body),
+		})
+	}
+	return fmt.Errorf("unsupported channel type: %s", ch.Type)
+}
+
+func postJSON(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	resp, err := notifyClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// sendEmail sends through the SMTP server configured by the SMTP_* variables.
+func sendEmail(to string, n Notification) error {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return errors.New("SMTP_HOST is not configured")
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "alerts@localhost"
+	}
+
+	var auth smtp.Auth
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + n.Subject,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		n.Body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(host+":"+port, auth, from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+
+	log.Printf("[NOTIFY] Email sent to %s: %s", to, n.Subject)
+	return nil
+}
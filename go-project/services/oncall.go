@@ -0,0 +1,111 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"time"
+	_ "time/tzdata" // Schedules name IANA zones that may be missing on the host
+)
+
+// CurrentOnCall returns the user on call for a schedule at the given time.
+// An active override wins over the rotation. It returns 0 when nobody is on
+// call, e.g. before the rotation starts or when the schedule has no members.
+func CurrentOnCall(scheduleID int64, at time.Time) (int64, error) {
+	var overrideUser int64
+	err := database.DB.QueryRow(`
+		SELECT user_id FROM oncall_overrides
+		WHERE schedule_id = ? AND starts_at <= ? AND ends_at > ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, scheduleID, at.UTC(), at.UTC()).Scan(&overrideUser)
+	if err == nil {
+		return overrideUser, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	var schedule models.OnCallSchedule
+	err = database.DB.QueryRow(`
+		SELECT id, timezone, rotation_type, handoff_time, rotation_start
+		FROM oncall_schedules WHERE id = ?
+	`, scheduleID).Scan(&schedule.ID, &schedule.Timezone, &schedule.RotationType, &schedule.HandoffTime, &schedule.RotationStart)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("on-call schedule %d not found", scheduleID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT user_id FROM oncall_schedule_members
+		WHERE schedule_id = ?
+		ORDER BY position ASC
+	`, scheduleID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var members []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return 0, err
+		}
+		members = append(members, userID)
+	}
+
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	shift, err := RotationShift(schedule, at)
+	if err != nil {
+		return 0, err
+	}
+	if shift < 0 {
+		return 0, nil
+	}
+
+	return members[shift%len(members)], nil
+}
+
+// RotationShift returns how many handoffs have happened in a schedule's
+// rotation at the given time, or -1 before the first shift starts. Handoffs
+// are counted on the schedule's local calendar so DST changes do not shift
+// them.
+func RotationShift(schedule models.OnCallSchedule, at time.Time) (int, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+
+	start, err := time.ParseInLocation("2006-01-02 15:04", schedule.RotationStart+" "+schedule.HandoffTime, loc)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rotation start or handoff time: %w", err)
+	}
+
+	local := at.In(loc)
+	if local.Before(start) {
+		return -1, nil
+	}
+
+	// Whole calendar days between the first handoff and now, minus one if
+	// today's handoff has not happened yet.
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(today.Sub(startDay).Hours() / 24)
+
+	handoffToday := time.Date(local.Year(), local.Month(), local.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+	if local.Before(handoffToday) {
+		days--
+	}
+
+	if schedule.RotationType == models.RotationTypeWeekly {
+		return days / 7, nil
+	}
+	return days, nil
+}
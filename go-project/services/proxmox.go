@@ -2,24 +2,41 @@ package services
 
 import (
 	"crypto/tls"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"go-project/database"
+	"go-project/models"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
+// proxmoxTicketRenewAt is when a ticket is renewed. Proxmox tickets are valid
+// for two hours, so this leaves ample margin for requests in flight.
+const proxmoxTicketRenewAt = 90 * time.Minute
+
+// ProxmoxClient talks to the Proxmox VE API, authenticating either with an
+// API token or with a ticket obtained from a username and password. A client
+// is safe for concurrent use; tickets are obtained on first use and renewed
+// as they age.
 type ProxmoxClient struct {
 	BaseURL   string
-	Token     string
 	Username  string
 	Password  string
+	APIToken  string // Token secret, or the full user@realm!tokenid=secret value
 	Realm     string
 	VerifySSL bool
 	client    *http.Client
+
+	mu        sync.Mutex
+	ticket    string
+	csrfToken string
+	issuedAt  time.Time
 }
 
 type ProxmoxAuthResponse struct {
@@ -42,6 +59,7 @@ type ProxmoxNode struct {
 type ProxmoxVMInfo struct {
 	VMID   int    `json:"vmid"`
 	Name   string `json:"name"`
+	Node   string `json:"node"`
 	Status string `json:"status"`
 	Uptime int64  `json:"uptime"`
 	CPUs   int    `json:"cpus"`
@@ -64,11 +82,14 @@ type ProxmoxTermProxyResponse struct {
 	Port   int    `json:"port"`
 	UPID   string `json:"upid"`
 	Error  string `json:"error,omitempty"`
+	// Path is the API path of the session's vncwebsocket.
+	Path string `json:"-"`
 }
 
 type ProxmoxLXCInfo struct {
 	VMID   int     `json:"vmid"`
 	Name   string  `json:"name"`
+	Node   string  `json:"node"`
 	Status string  `json:"status"`
 	Uptime int64   `json:"uptime"`
 	CPUs   float64 `json:"cpus"`
@@ -94,15 +115,100 @@ func NewProxmoxClient(baseURL, username, password, realm string, verifySSL bool)
 	}
 }
 
-func (p *ProxmoxClient) Authenticate() error {
-	authURL := fmt.Sprintf("%s/api2/json/access/ticket", p.BaseURL)
+// NewProxmoxClientForServer returns a client for a stored server, using its
+// API token when one is set and its password otherwise.
+func NewProxmoxClientForServer(s models.Server) *ProxmoxClient {
+	baseURL := fmt.Sprintf("https://%s:%d", s.IPAddress, s.Port)
+	client := NewProxmoxClient(baseURL, s.Username, s.Password, s.Realm, s.VerifySSL)
+	client.APIToken = s.APIToken
+	return client
+}
 
+// UsesAPIToken reports whether requests are authenticated with an API token
+// rather than a ticket.
+func (p *ProxmoxClient) UsesAPIToken() bool {
+	return p.APIToken != ""
+}
+
+// fullUsername qualifies the username with its realm, defaulting to pam.
+func (p *ProxmoxClient) fullUsername() string {
 	username := p.Username
 	if p.Realm != "" && !strings.Contains(username, "@") {
 		username = fmt.Sprintf("%s@%s", username, p.Realm)
 	} else if !strings.Contains(username, "@") {
 		username = fmt.Sprintf("%s@pam", username)
 	}
+	return username
+}
+
+// apiTokenValue returns the user@realm!tokenid=secret value for the
+// PVEAPIToken header. APIToken may hold the whole value, or only the secret
+// with the token ID in the username (user@realm!tokenid).
+func (p *ProxmoxClient) apiTokenValue() string {
+	if strings.Contains(p.APIToken, "!") && strings.Contains(p.APIToken, "=") {
+		return p.APIToken
+	}
+	return p.fullUsername() + "=" + p.APIToken
+}
+
+// AuthHeader returns the headers authenticating a request, such as a
+// WebSocket dial, made outside the client.
+func (p *ProxmoxClient) AuthHeader() (http.Header, error) {
+	header := http.Header{}
+	if p.UsesAPIToken() {
+		header.Set("Authorization", "PVEAPIToken="+p.apiTokenValue())
+		return header, nil
+	}
+
+	ticket, _, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	header.Add("Cookie", (&http.Cookie{Name: "PVEAuthCookie", Value: ticket}).String())
+	return header, nil
+}
+
+// session returns the current ticket and CSRF token, logging in first when
+// there is no ticket yet or it is due for renewal.
+func (p *ProxmoxClient) session() (string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ticket == "" || time.Since(p.issuedAt) >= proxmoxTicketRenewAt {
+		if err := p.login(); err != nil {
+			return "", "", err
+		}
+	}
+	return p.ticket, p.csrfToken, nil
+}
+
+// Authenticate obtains a fresh ticket with the username and password. Clients
+// using an API token need no ticket, so it does nothing for them.
+func (p *ProxmoxClient) Authenticate() error {
+	if p.UsesAPIToken() {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.login()
+}
+
+// expireTicket drops ticket so the next request logs in again, unless another
+// request has already replaced it.
+func (p *ProxmoxClient) expireTicket(ticket string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ticket == ticket {
+		p.ticket = ""
+	}
+}
+
+// login requests a ticket. The caller must hold p.mu.
+func (p *ProxmoxClient) login() error {
+
+	authURL := fmt.Sprintf("%s/api2/json/access/ticket", p.BaseURL)
+	username := p.fullUsername()
 
 	log.Printf("[PROXMOX] Authenticating as %s at %s", username, authURL)
 
@@ -129,190 +235,175 @@ func (p *ProxmoxClient) Authenticate() error {
 		return fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
-	p.Token = authResp.Data.Ticket
+	p.ticket = authResp.Data.Ticket
+	p.csrfToken = authResp.Data.CSRFPreventionToken
+	p.issuedAt = time.Now()
 	log.Printf("[PROXMOX] Auth Successful for %s", username)
 	return nil
 }
 
-func (p *ProxmoxClient) TestConnection() (bool, error) {
-	if err := p.Authenticate(); err != nil {
-		log.Printf("[PROXMOX] TestConnection Auth Failure: %v", err)
-		return false, err
+// request calls an API path (relative to /api2/json) with form values, sent
+// in the query string for GET and DELETE and as the body otherwise, and
+// decodes the response's data into out, which may be nil. Ticket clients send
+// the CSRF token with writes, and log in again and retry once when the ticket
+// is rejected.
+func (p *ProxmoxClient) request(method, path string, form url.Values, out interface{}) error {
+	resp, err := p.do(method, path, form)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
-	nodes, err := p.GetNodes()
-	if err != nil {
-		log.Printf("[PROXMOX] TestConnection Nodes Error: %v", err)
-		return false, fmt.Errorf("failed to retrieve nodes: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
 	}
 
-	log.Printf("[PROXMOX] TestConnection successful, found %d nodes", len(nodes))
-	if len(nodes) == 0 {
-		return false, fmt.Errorf("connection successful but no nodes found")
+	if out == nil {
+		return nil
 	}
 
-	return true, nil
+	result := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
 }
 
-func (p *ProxmoxClient) GetNodes() ([]ProxmoxNode, error) {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return nil, err
+func (p *ProxmoxClient) do(method, path string, form url.Values) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		reqURL := p.BaseURL + "/api2/json" + path
+		var body io.Reader
+		inQuery := method == http.MethodGet || method == http.MethodDelete
+		if form != nil && inQuery {
+			if len(form) > 0 {
+				reqURL += "?" + form.Encode()
+			}
+		} else if form != nil {
+			body = strings.NewReader(form.Encode())
 		}
-	}
 
-	nodesURL := fmt.Sprintf("%s/api2/json/nodes", p.BaseURL)
-	req, err := http.NewRequest("GET", nodesURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
+		req, err := http.NewRequest(method, reqURL, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get nodes: %w", err)
-	}
-	defer resp.Body.Close()
+		var ticket string
+		if p.UsesAPIToken() {
+			req.Header.Set("Authorization", "PVEAPIToken="+p.apiTokenValue())
+		} else {
+			var csrfToken string
+			ticket, csrfToken, err = p.session()
+			if err != nil {
+				return nil, err
+			}
+			req.AddCookie(&http.Cookie{Name: "PVEAuthCookie", Value: ticket})
+			if method != http.MethodGet {
+				req.Header.Set("CSRFPreventionToken", csrfToken)
+			}
+		}
 
-	var result struct {
-		Data []ProxmoxNode `json:"data"`
-	}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode nodes response: %w", err)
+		if resp.StatusCode == http.StatusUnauthorized && !p.UsesAPIToken() && attempt == 0 {
+			resp.Body.Close()
+			log.Printf("[PROXMOX] Ticket rejected by %s, logging in again", p.BaseURL)
+			p.expireTicket(ticket)
+			continue
+		}
+		return resp, nil
 	}
-
-	return result.Data, nil
 }
 
-func (p *ProxmoxClient) GetVMs(node string) ([]ProxmoxVMInfo, error) {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return nil, err
+func (p *ProxmoxClient) TestConnection() (bool, error) {
+	if !p.UsesAPIToken() {
+		if _, _, err := p.session(); err != nil {
+			log.Printf("[PROXMOX] TestConnection Auth Failure: %v", err)
+			return false, err
 		}
 	}
 
-	vmsURL := fmt.Sprintf("%s/api2/json/nodes/%s/qemu", p.BaseURL, node)
-	req, err := http.NewRequest("GET", vmsURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
+	nodes, err := p.GetNodes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get VMs: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var result struct {
-		Data []ProxmoxVMInfo `json:"data"`
+		log.Printf("[PROXMOX] TestConnection Nodes Error: %v", err)
+		return false, fmt.Errorf("failed to retrieve nodes: %w", err)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode VMs response: %w", err)
+	log.Printf("[PROXMOX] TestConnection successful, found %d nodes", len(nodes))
+	if len(nodes) == 0 {
+		return false, fmt.Errorf("connection successful but no nodes found")
 	}
 
-	return result.Data, nil
+	return true, nil
 }
 
-func (p *ProxmoxClient) GetNodeTerminal(node string) (*ProxmoxTermProxyResponse, error) {
-	if err := p.Authenticate(); err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
+func (p *ProxmoxClient) GetNodes() ([]ProxmoxNode, error) {
+	var nodes []ProxmoxNode
+	if err := p.request("GET", "/nodes", nil, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
 	}
+	return nodes, nil
+}
 
-	termURL := fmt.Sprintf("%s/api2/json/nodes/%s/termproxy", p.BaseURL, node)
-	log.Printf("[PROXMOX] Termproxy URL: %s", termURL)
-
-	req, err := http.NewRequest("POST", termURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+func (p *ProxmoxClient) GetVMs(node string) ([]ProxmoxVMInfo, error) {
+	var vms []ProxmoxVMInfo
+	if err := p.request("GET", fmt.Sprintf("/nodes/%s/qemu", node), nil, &vms); err != nil {
+		return nil, fmt.Errorf("failed to get VMs: %w", err)
 	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initiate terminal: %w", err)
+	for i := range vms {
+		vms[i].Node = node
 	}
-	defer resp.Body.Close()
+	return vms, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("terminal initiation failed with status %d: %s", resp.StatusCode, string(body))
-	}
+// GetNodeTerminal opens a termproxy session on a node.
+func (p *ProxmoxClient) GetNodeTerminal(node string) (*ProxmoxTermProxyResponse, error) {
+	log.Printf("[PROXMOX] Termproxy on node %s", node)
+	return p.termproxy(fmt.Sprintf("/nodes/%s", node))
+}
 
-	var result struct {
-		Data    ProxmoxTermProxyResponse `json:"data"`
-		Errors  map[string]interface{}   `json:"errors,omitempty"`
-		Message string                   `json:"message,omitempty"`
-	}
+// GetGuestTerminal opens a termproxy session on the console of a container,
+// or on the serial console of a VM.
+func (p *ProxmoxClient) GetGuestTerminal(node, guestType string, vmid int) (*ProxmoxTermProxyResponse, error) {
+	log.Printf("[PROXMOX] Termproxy on %s %d on node %s", guestType, vmid, node)
+	return p.termproxy(fmt.Sprintf("/nodes/%s/%s/%d", node, guestType, vmid))
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode terminal response: %w", err)
+// termproxy opens a termproxy session on the node or guest at base.
+func (p *ProxmoxClient) termproxy(base string) (*ProxmoxTermProxyResponse, error) {
+	var term ProxmoxTermProxyResponse
+	if err := p.request("POST", base+"/termproxy", url.Values{}, &term); err != nil {
+		return nil, fmt.Errorf("terminal initiation failed: %w", err)
 	}
 
-	if result.Data.Error != "" {
-		return nil, fmt.Errorf("proxmox error: %s", result.Data.Error)
+	if term.Error != "" {
+		return nil, fmt.Errorf("proxmox error: %s", term.Error)
 	}
 
-	return &result.Data, nil
+	term.Path = base + "/vncwebsocket"
+	return &term, nil
 }
 
 func (p *ProxmoxClient) GetLXCs(node string) ([]ProxmoxLXCInfo, error) {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return nil, err
-		}
-	}
-
-	lxcsURL := fmt.Sprintf("%s/api2/json/nodes/%s/lxc", p.BaseURL, node)
-	req, err := http.NewRequest("GET", lxcsURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
-	if err != nil {
+	var lxcs []ProxmoxLXCInfo
+	if err := p.request("GET", fmt.Sprintf("/nodes/%s/lxc", node), nil, &lxcs); err != nil {
 		return nil, fmt.Errorf("failed to get LXCs: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var result struct {
-		Data []ProxmoxLXCInfo `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode LXCs response: %w", err)
+	for i := range lxcs {
+		lxcs[i].Node = node
 	}
-
-	return result.Data, nil
+	return lxcs, nil
 }
 
-func (p *ProxmoxClient) CreateLXC(node string, vmid int, ostemplate, storage string, params map[string]string) error {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return err
-		}
-	}
-
-	createURL := fmt.Sprintf("%s/api2/json/nodes/%s/lxc", p.BaseURL, node)
+func (p *ProxmoxClient) CreateLXC(node string, vmid int, ostemplate, storage string, params map[string]string) (string, error) {
 	data := url.Values{}
 	data.Set("vmid", fmt.Sprintf("%d", vmid))
 	data.Set("ostemplate", ostemplate)
@@ -321,138 +412,93 @@ func (p *ProxmoxClient) CreateLXC(node string, vmid int, ostemplate, storage str
 		data.Set(k, v)
 	}
 
-	req, err := http.NewRequest("POST", createURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to create LXC: %w", err)
+	var upid string
+	if err := p.request("POST", fmt.Sprintf("/nodes/%s/lxc", node), data, &upid); err != nil {
+		return "", fmt.Errorf("failed to create LXC: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create LXC with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	return nil
+	return upid, nil
 }
 
-func (p *ProxmoxClient) StartLXC(node string, vmid int) error {
+func (p *ProxmoxClient) StartLXC(node string, vmid int) (string, error) {
 	return p.lxcAction(node, vmid, "start")
 }
 
-func (p *ProxmoxClient) StopLXC(node string, vmid int) error {
+func (p *ProxmoxClient) StopLXC(node string, vmid int) (string, error) {
 	return p.lxcAction(node, vmid, "stop")
 }
 
-func (p *ProxmoxClient) RemoveLXC(node string, vmid int) error {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return err
-		}
-	}
-
-	removeURL := fmt.Sprintf("%s/api2/json/nodes/%s/lxc/%d", p.BaseURL, node, vmid)
-	req, err := http.NewRequest("DELETE", removeURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to remove LXC: %w", err)
+func (p *ProxmoxClient) RemoveLXC(node string, vmid int) (string, error) {
+	var upid string
+	if err := p.request("DELETE", fmt.Sprintf("/nodes/%s/lxc/%d", node, vmid), nil, &upid); err != nil {
+		return "", fmt.Errorf("failed to remove LXC: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to remove LXC with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	return nil
+	return upid, nil
 }
 
-func (p *ProxmoxClient) lxcAction(node string, vmid int, action string) error {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return err
-		}
-	}
-
-	actionURL := fmt.Sprintf("%s/api2/json/nodes/%s/lxc/%d/status/%s", p.BaseURL, node, vmid, action)
-	req, err := http.NewRequest("POST", actionURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to %s LXC: %w", action, err)
+func (p *ProxmoxClient) lxcAction(node string, vmid int, action string) (string, error) {
+	path := fmt.Sprintf("/nodes/%s/lxc/%d/status/%s", node, vmid, action)
+	var upid string
+	if err := p.request("POST", path, url.Values{}, &upid); err != nil {
+		return "", fmt.Errorf("failed to %s LXC: %w", action, err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to %s LXC with status %d: %s", action, resp.StatusCode, string(body))
-	}
-
-	return nil
+	return upid, nil
 }
 
 func (p *ProxmoxClient) GetClusterLogs() ([]ProxmoxLogEntry, error) {
-	if p.Token == "" {
-		if err := p.Authenticate(); err != nil {
-			return nil, err
-		}
+	var logs []ProxmoxLogEntry
+	if err := p.request("GET", "/cluster/log", nil, &logs); err != nil {
+		return nil, fmt.Errorf("failed to fetch logs: %w", err)
 	}
+	return logs, nil
+}
 
-	logsURL := fmt.Sprintf("%s/api2/json/cluster/log", p.BaseURL)
-	req, err := http.NewRequest("GET", logsURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// GetServerByID loads a server with its credentials, or nil if it does not
+// exist.
+func GetServerByID(id int64) (*models.Server, error) {
+	var s models.Server
+	var lastSync sql.NullTime
+	err := database.DB.QueryRow(`
+		SELECT id, name, type, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), COALESCE(api_token, ''),
+		       COALESCE(description, ''), COALESCE(status, ''), COALESCE(node, ''), COALESCE(realm, ''), verify_ssl,
+		       COALESCE(cluster_name, ''), last_sync, created_at, updated_at
+		FROM servers WHERE id = ?
+	`, id).Scan(&s.ID, &s.Name, &s.Type, &s.IPAddress, &s.Port, &s.Username, &s.Password, &s.APIToken,
+		&s.Description, &s.Status, &s.Node, &s.Realm, &s.VerifySSL,
+		&s.ClusterName, &lastSync, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "PVEAuthCookie",
-		Value: p.Token,
-	})
-
-	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster logs: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch logs with status %d: %s", resp.StatusCode, string(body))
+	if lastSync.Valid {
+		s.LastSync = &lastSync.Time
 	}
+	return &s, nil
+}
 
-	var result struct {
-		Data []ProxmoxLogEntry `json:"data"`
+// GetProxmoxServers loads every Proxmox server with its credentials.
+func GetProxmoxServers() ([]models.Server, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, ip_address, port, COALESCE(username, ''), COALESCE(password, ''), COALESCE(api_token, ''),
+		       COALESCE(status, ''), COALESCE(node, ''), COALESCE(realm, ''), verify_ssl
+		FROM servers WHERE type = 'proxmox'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode logs response: %w", err)
+	servers := []models.Server{}
+	for rows.Next() {
+		s := models.Server{Type: models.ServerTypeProxmox}
+		if err := rows.Scan(&s.ID, &s.Name, &s.IPAddress, &s.Port, &s.Username, &s.Password, &s.APIToken,
+			&s.Status, &s.Node, &s.Realm, &s.VerifySSL); err != nil {
+			return nil, err
+		}
+		servers = append(servers, s)
 	}
-
-	return result.Data, nil
+	return servers, rows.Err()
 }
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// BackupModes are the vzdump modes: snapshot keeps the guest running,
+// suspend pauses it briefly and stop shuts it down for the backup.
+var BackupModes = map[string]bool{
+	"snapshot": true,
+	"suspend":  true,
+	"stop":     true,
+}
+
+// ProxmoxStorage is a storage of a node.
+type ProxmoxStorage struct {
+	Storage string `json:"storage"`
+	Node    string `json:"node,omitempty"` // Set by GetClusterStorages, empty for shared storages
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Shared  int    `json:"shared"`
+	Active  int    `json:"active"`
+	Total   int64  `json:"total"`
+	Used    int64  `json:"used"`
+	Avail   int64  `json:"avail"`
+}
+
+// ProxmoxBackupVolume is a backup archive on a storage.
+type ProxmoxBackupVolume struct {
+	VolID     string `json:"volid"`
+	Storage   string `json:"storage"`
+	Node      string `json:"node"` // A node the archive can be restored on
+	VMID      int    `json:"vmid"`
+	Subtype   string `json:"subtype,omitempty"` // qemu or lxc
+	Format    string `json:"format"`
+	Size      int64  `json:"size"`
+	CTime     int64  `json:"ctime"`
+	Notes     string `json:"notes,omitempty"`
+	Protected int    `json:"protected,omitempty"`
+}
+
+// ProxmoxBackupJob is a scheduled backup job of the cluster.
+type ProxmoxBackupJob struct {
+	ID       string `json:"id"`
+	Schedule string `json:"schedule"`
+	Storage  string `json:"storage"`
+	Node     string `json:"node,omitempty"`
+	VMID     string `json:"vmid,omitempty"` // Comma-separated guests, when not all
+	All      int    `json:"all,omitempty"`
+	Exclude  string `json:"exclude,omitempty"`
+	Pool     string `json:"pool,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+	Enabled  *int   `json:"enabled,omitempty"` // Unset means enabled
+	Comment  string `json:"comment,omitempty"`
+	NextRun  int64  `json:"next-run,omitempty"`
+}
+
+// GetStorages lists a node's storages, only those holding content of the
+// given type (e.g. "backup") when content is set.
+func (p *ProxmoxClient) GetStorages(node, content string) ([]ProxmoxStorage, error) {
+	params := url.Values{}
+	if content != "" {
+		params.Set("content", content)
+	}
+
+	var storages []ProxmoxStorage
+	if err := p.request(http.MethodGet, fmt.Sprintf("/nodes/%s/storage", node), params, &storages); err != nil {
+		return nil, fmt.Errorf("failed to get storages: %w", err)
+	}
+	return storages, nil
+}
+
+// GetBackups lists the backup archives on a storage, only those of one guest
+// when vmid is not 0.
+func (p *ProxmoxClient) GetBackups(node, storage string, vmid int) ([]ProxmoxBackupVolume, error) {
+	params := url.Values{}
+	params.Set("content", "backup")
+	if vmid != 0 {
+		params.Set("vmid", strconv.Itoa(vmid))
+	}
+
+	var volumes []ProxmoxBackupVolume
+	path := fmt.Sprintf("/nodes/%s/storage/%s/content", node, storage)
+	if err := p.request(http.MethodGet, path, params, &volumes); err != nil {
+		return nil, fmt.Errorf("failed to list backups on %s: %w", storage, err)
+	}
+	for i := range volumes {
+		volumes[i].Storage = storage
+		volumes[i].Node = node
+	}
+	return volumes, nil
+}
+
+// GetClusterBackups lists the backup archives on the backup storages of the
+// given nodes. Shared storages are listed once, from the first node that has
+// them.
+func (p *ProxmoxClient) GetClusterBackups(nodes []string, vmid int) ([]ProxmoxBackupVolume, error) {
+	volumes := []ProxmoxBackupVolume{}
+	shared := map[string]bool{}
+
+	for _, node := range nodes {
+		storages, err := p.GetStorages(node, "backup")
+		if err != nil {
+			return nil, err
+		}
+		for _, storage := range storages {
+			if storage.Shared == 1 {
+				if shared[storage.Storage] {
+					continue
+				}
+				shared[storage.Storage] = true
+			}
+
+			found, err := p.GetBackups(node, storage.Storage, vmid)
+			if err != nil {
+				return nil, err
+			}
+			volumes = append(volumes, found...)
+		}
+	}
+	return volumes, nil
+}
+
+// Backup starts a vzdump backup of a guest to storage and returns the task's
+// UPID.
+func (p *ProxmoxClient) Backup(node string, vmid int, storage, mode, notes string) (string, error) {
+	params := url.Values{}
+	params.Set("vmid", strconv.Itoa(vmid))
+	params.Set("storage", storage)
+	params.Set("mode", mode)
+	params.Set("compress", "zstd")
+	if notes != "" {
+		params.Set("notes-template", notes)
+	}
+
+	var upid string
+	if err := p.request(http.MethodPost, fmt.Sprintf("/nodes/%s/vzdump", node), params, &upid); err != nil {
+		return "", fmt.Errorf("failed to start backup: %w", err)
+	}
+	return upid, nil
+}
+
+// BackupGuestType returns whether a backup archive holds a VM or a container,
+// from its volume ID, e.g. local:backup/vzdump-lxc-101-....tar.zst or
+// pbs:backup/vm/100/2024-01-01T00:00:00Z.
+func BackupGuestType(volid string) string {
+	switch {
+	case strings.Contains(volid, "vzdump-qemu-"), strings.Contains(volid, "backup/vm/"):
+		return GuestQEMU
+	case strings.Contains(volid, "vzdump-lxc-"), strings.Contains(volid, "backup/ct/"):
+		return GuestLXC
+	}
+	return ""
+}
+
+// RestoreBackup restores an archive as guest vmid, onto storage when set
+// and otherwise the storages it was backed up from, and returns the task's
+// UPID. force is needed to overwrite an existing guest.
+func (p *ProxmoxClient) RestoreBackup(node, guestType string, vmid int, volid, storage string, force bool) (string, error) {
+	params := url.Values{}
+	params.Set("vmid", strconv.Itoa(vmid))
+	if guestType == GuestLXC {
+		params.Set("ostemplate", volid)
+		params.Set("restore", "1")
+	} else {
+		params.Set("archive", volid)
+	}
+	if storage != "" {
+		params.Set("storage", storage)
+	}
+	if force {
+		params.Set("force", "1")
+	}
+
+	var upid string
+	if err := p.request(http.MethodPost, fmt.Sprintf("/nodes/%s/%s", node, guestType), params, &upid); err != nil {
+		return "", fmt.Errorf("failed to restore backup: %w", err)
+	}
+	return upid, nil
+}
+
+// GetBackupJobs lists the cluster's scheduled backup jobs.
+func (p *ProxmoxClient) GetBackupJobs() ([]ProxmoxBackupJob, error) {
+	var jobs []ProxmoxBackupJob
+	if err := p.request(http.MethodGet, "/cluster/backup", nil, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to get backup jobs: %w", err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// ProxmoxClusterResource is an entry of /cluster/resources, which lists the
+// nodes, guests and storages of every node in the cluster. A standalone node
+// reports itself as a one-node cluster.
+type ProxmoxClusterResource struct {
+	ID       string  `json:"id"`   // e.g. node/pve1, qemu/100 or lxc/101
+	Type     string  `json:"type"` // node, qemu, lxc, storage, ...
+	Node     string  `json:"node"`
+	VMID     int     `json:"vmid,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Status   string  `json:"status"`
+	Storage  string  `json:"storage,omitempty"`
+	Plugin   string  `json:"plugintype,omitempty"`
+	Content  string  `json:"content,omitempty"`
+	Shared   int     `json:"shared,omitempty"`
+	Template int     `json:"template,omitempty"`
+	Pool     string  `json:"pool,omitempty"`
+	Tags     string  `json:"tags,omitempty"`
+	HAState  string  `json:"hastate,omitempty"`
+	CPU      float64 `json:"cpu"`
+	MaxCPU   float64 `json:"maxcpu"`
+	Mem      uint64  `json:"mem"`
+	MaxMem   uint64  `json:"maxmem"`
+	Disk     uint64  `json:"disk"`
+	MaxDisk  uint64  `json:"maxdisk"`
+	Uptime   int64   `json:"uptime"`
+}
+
+// GetClusterResources lists the cluster's resources of a kind, "vm", "node"
+// or "storage", or all of them when kind is empty.
+func (p *ProxmoxClient) GetClusterResources(kind string) ([]ProxmoxClusterResource, error) {
+	params := url.Values{}
+	if kind != "" {
+		params.Set("type", kind)
+	}
+
+	var resources []ProxmoxClusterResource
+	if err := p.request(http.MethodGet, "/cluster/resources", params, &resources); err != nil {
+		return nil, fmt.Errorf("failed to get cluster resources: %w", err)
+	}
+	return resources, nil
+}
+
+// GetClusterGuests lists the VMs and containers on every node, ordered by
+// VMID.
+func (p *ProxmoxClient) GetClusterGuests() ([]ProxmoxClusterResource, error) {
+	resources, err := p.GetClusterResources("vm")
+	if err != nil {
+		return nil, err
+	}
+
+	guests := []ProxmoxClusterResource{}
+	for _, r := range resources {
+		if r.Type == GuestQEMU || r.Type == GuestLXC {
+			guests = append(guests, r)
+		}
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].VMID < guests[j].VMID })
+	return guests, nil
+}
+
+// GetOnlineNodes returns the names of the cluster's online nodes, sorted.
+func (p *ProxmoxClient) GetOnlineNodes() ([]string, error) {
+	resources, err := p.GetClusterResources("node")
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []string{}
+	for _, r := range resources {
+		if r.Type == "node" && r.Status == "online" {
+			nodes = append(nodes, r.Node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+// GetClusterStorages lists the storages of every node with their usage.
+// Shared storages, which every node sees, are listed once with an empty node.
+func (p *ProxmoxClient) GetClusterStorages() ([]ProxmoxStorage, error) {
+	resources, err := p.GetClusterResources("storage")
+	if err != nil {
+		return nil, err
+	}
+
+	storages := []ProxmoxStorage{}
+	shared := map[string]int{}
+	for _, r := range resources {
+		if r.Type != "storage" {
+			continue
+		}
+
+		storage := ProxmoxStorage{
+			Storage: r.Storage,
+			Node:    r.Node,
+			Type:    r.Plugin,
+			Content: r.Content,
+			Shared:  r.Shared,
+			Total:   int64(r.MaxDisk),
+			Used:    int64(r.Disk),
+		}
+		if r.Status == "available" {
+			storage.Active = 1
+		}
+		storage.Avail = storage.Total - storage.Used
+
+		if r.Shared == 1 {
+			storage.Node = ""
+			// Prefer the view of a node that can reach the storage.
+			if i, ok := shared[r.Storage]; ok {
+				if storages[i].Active == 0 && storage.Active == 1 {
+					storages[i] = storage
+				}
+				continue
+			}
+			shared[r.Storage] = len(storages)
+		}
+		storages = append(storages, storage)
+	}
+
+	sort.Slice(storages, func(i, j int) bool {
+		if storages[i].Storage != storages[j].Storage {
+			return storages[i].Storage < storages[j].Storage
+		}
+		return storages[i].Node < storages[j].Node
+	})
+	return storages, nil
+}
+
+// FindGuest returns the VM or container with the given VMID, wherever in the
+// cluster it runs, or nil if there is none.
+func (p *ProxmoxClient) FindGuest(vmid int) (*ProxmoxClusterResource, error) {
+	resources, err := p.GetClusterResources("vm")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range resources {
+		if r.VMID == vmid && (r.Type == GuestQEMU || r.Type == GuestLXC) {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
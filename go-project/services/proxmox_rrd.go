@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RRD timeframes collected from Proxmox, finest first. Proxmox keeps about
+// 70 points of each: a minute apart for an hour, 30 minutes apart for a day
+// and 3 hours apart for a week.
+const (
+	RRDHour = "hour"
+	RRDDay  = "day"
+	RRDWeek = "week"
+)
+
+// RRDTimeframes lists the collected timeframes, finest first.
+var RRDTimeframes = []string{RRDHour, RRDDay, RRDWeek}
+
+// RRDStep is the spacing of a timeframe's points.
+func RRDStep(timeframe string) time.Duration {
+	switch timeframe {
+	case RRDHour:
+		return time.Minute
+	case RRDDay:
+		return 30 * time.Minute
+	case RRDWeek:
+		return 3 * time.Hour
+	}
+	return 0
+}
+
+// ProxmoxRRDPoint is a point of a node's or guest's rrddata, averaged over
+// the timeframe's step. Fields are nil where Proxmox has no data, e.g. while
+// a guest was stopped. Nodes report memory as memused and memtotal, guests
+// as mem and maxmem; nodes report iowait and load, guests disk IO. Network
+// and disk IO are in bytes per second.
+type ProxmoxRRDPoint struct {
+	Time      int64    `json:"time"`
+	CPU       *float64 `json:"cpu"`
+	MaxCPU    *float64 `json:"maxcpu"`
+	Mem       *float64 `json:"mem"`
+	MaxMem    *float64 `json:"maxmem"`
+	MemUsed   *float64 `json:"memused"`
+	MemTotal  *float64 `json:"memtotal"`
+	NetIn     *float64 `json:"netin"`
+	NetOut    *float64 `json:"netout"`
+	DiskRead  *float64 `json:"diskread"`
+	DiskWrite *float64 `json:"diskwrite"`
+	IOWait    *float64 `json:"iowait"`
+	LoadAvg   *float64 `json:"loadavg"`
+}
+
+// GetNodeRRDData returns a node's metrics over a timeframe, oldest first.
+func (p *ProxmoxClient) GetNodeRRDData(node, timeframe string) ([]ProxmoxRRDPoint, error) {
+	points, err := p.rrddata(fmt.Sprintf("/nodes/%s", node), timeframe)
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Mem, points[i].MaxMem = points[i].MemUsed, points[i].MemTotal
+	}
+	return points, nil
+}
+
+// GetGuestRRDData returns a VM's or container's metrics over a timeframe,
+// oldest first.
+func (p *ProxmoxClient) GetGuestRRDData(node, guestType string, vmid int, timeframe string) ([]ProxmoxRRDPoint, error) {
+	return p.rrddata(fmt.Sprintf("/nodes/%s/%s/%d", node, guestType, vmid), timeframe)
+}
+
+func (p *ProxmoxClient) rrddata(base, timeframe string) ([]ProxmoxRRDPoint, error) {
+	params := url.Values{}
+	params.Set("timeframe", timeframe)
+	params.Set("cf", "AVERAGE")
+
+	var points []ProxmoxRRDPoint
+	if err := p.request(http.MethodGet, base+"/rrddata", params, &points); err != nil {
+		return nil, fmt.Errorf("failed to get metrics: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"go-project/models"
+	"sync"
+)
+
+// ProxmoxSessionPool shares one client per server so tickets are reused
+// across handlers, the monitor and the infrastructure view instead of logging
+// in on every request.
+type ProxmoxSessionPool struct {
+	mu      sync.Mutex
+	entries map[int64]proxmoxSession
+}
+
+type proxmoxSession struct {
+	key    proxmoxSessionKey
+	client *ProxmoxClient
+}
+
+// proxmoxSessionKey is everything a client is built from, so a server whose
+// address or credentials changed gets a new client.
+type proxmoxSessionKey struct {
+	IPAddress string
+	Port      int
+	Username  string
+	Password  string
+	APIToken  string
+	Realm     string
+	VerifySSL bool
+}
+
+var (
+	proxmoxSessions     *ProxmoxSessionPool
+	proxmoxSessionsOnce sync.Once
+)
+
+func GetProxmoxSessions() *ProxmoxSessionPool {
+	proxmoxSessionsOnce.Do(func() {
+		proxmoxSessions = &ProxmoxSessionPool{entries: make(map[int64]proxmoxSession)}
+	})
+	return proxmoxSessions
+}
+
+// Client returns the shared client for a stored server.
+func (sp *ProxmoxSessionPool) Client(s models.Server) *ProxmoxClient {
+	key := proxmoxSessionKey{
+		IPAddress: s.IPAddress,
+		Port:      s.Port,
+		Username:  s.Username,
+		Password:  s.Password,
+		APIToken:  s.APIToken,
+		Realm:     s.Realm,
+		VerifySSL: s.VerifySSL,
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if entry, ok := sp.entries[s.ID]; ok && entry.key == key {
+		return entry.client
+	}
+
+	client := NewProxmoxClientForServer(s)
+	sp.entries[s.ID] = proxmoxSession{key: key, client: client}
+	return client
+}
+
+// Invalidate drops a server's client, e.g. when it is updated or deleted.
+func (sp *ProxmoxSessionPool) Invalidate(serverID int64) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	delete(sp.entries, serverID)
+}
+
+// ProxmoxClientFor returns the shared client for a stored server.
+func ProxmoxClientFor(s models.Server) *ProxmoxClient {
+	return GetProxmoxSessions().Client(s)
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// Guest types, as they appear in Proxmox API paths.
+const (
+	GuestQEMU = "qemu"
+	GuestLXC  = "lxc"
+)
+
+// snapshotName matches the names Proxmox accepts for snapshots.
+var snapshotName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{1,39}$`)
+
+// ValidSnapshotName reports whether name can be used for a snapshot. The
+// name "current" is reserved by Proxmox for the running state.
+func ValidSnapshotName(name string) bool {
+	return snapshotName.MatchString(name) && name != "current"
+}
+
+type ProxmoxSnapshot struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SnapTime    int64  `json:"snaptime,omitempty"`
+	Parent      string `json:"parent,omitempty"`
+	VMState     int    `json:"vmstate,omitempty"` // 1 if RAM was saved with a VM snapshot
+}
+
+func snapshotPath(node, guestType string, vmid int) string {
+	return fmt.Sprintf("/nodes/%s/%s/%d/snapshot", node, guestType, vmid)
+}
+
+// GetSnapshots lists a guest's snapshots, oldest first as Proxmox returns
+// them, leaving out the "current" entry for its running state.
+func (p *ProxmoxClient) GetSnapshots(node, guestType string, vmid int) ([]ProxmoxSnapshot, error) {
+	var all []ProxmoxSnapshot
+	if err := p.request(http.MethodGet, snapshotPath(node, guestType, vmid), nil, &all); err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+
+	snapshots := []ProxmoxSnapshot{}
+	for _, snapshot := range all {
+		if snapshot.Name != "current" {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+// CreateSnapshot snapshots a guest and returns the task's UPID. vmstate also
+// saves the RAM of a running VM; it is ignored for containers.
+func (p *ProxmoxClient) CreateSnapshot(node, guestType string, vmid int, name, description string, vmstate bool) (string, error) {
+	params := url.Values{}
+	params.Set("snapname", name)
+	if description != "" {
+		params.Set("description", description)
+	}
+	if vmstate && guestType == GuestQEMU {
+		params.Set("vmstate", "1")
+	}
+
+	var upid string
+	if err := p.request(http.MethodPost, snapshotPath(node, guestType, vmid), params, &upid); err != nil {
+		return "", fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	return upid, nil
+}
+
+// RollbackSnapshot returns a guest to a snapshot and returns the task's UPID.
+func (p *ProxmoxClient) RollbackSnapshot(node, guestType string, vmid int, name string) (string, error) {
+	var upid string
+	path := fmt.Sprintf("%s/%s/rollback", snapshotPath(node, guestType, vmid), name)
+	if err := p.request(http.MethodPost, path, url.Values{}, &upid); err != nil {
+		return "", fmt.Errorf("failed to roll back snapshot: %w", err)
+	}
+	return upid, nil
+}
+
+// DeleteSnapshot removes a snapshot and returns the task's UPID.
+func (p *ProxmoxClient) DeleteSnapshot(node, guestType string, vmid int, name string) (string, error) {
+	var upid string
+	path := fmt.Sprintf("%s/%s", snapshotPath(node, guestType, vmid), name)
+	if err := p.request(http.MethodDelete, path, nil, &upid); err != nil {
+		return "", fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return upid, nil
+}
+
+// UpdateSnapshotDescription replaces a snapshot's description.
+func (p *ProxmoxClient) UpdateSnapshotDescription(node, guestType string, vmid int, name, description string) error {
+	params := url.Values{}
+	params.Set("description", description)
+
+	path := fmt.Sprintf("%s/%s/config", snapshotPath(node, guestType, vmid), name)
+	if err := p.request(http.MethodPut, path, params, nil); err != nil {
+		return fmt.Errorf("failed to update snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxmoxUPID is a parsed task ID, which Proxmox formats as
+// UPID:node:pid:pstart:starttime:type:id:user@realm:
+type ProxmoxUPID struct {
+	Node      string
+	Type      string
+	ID        string // Usually the guest's VMID, empty for node tasks
+	User      string
+	StartTime time.Time
+}
+
+func ParseUPID(upid string) (ProxmoxUPID, error) {
+	parts := strings.Split(upid, ":")
+	if len(parts) < 9 || parts[0] != "UPID" || parts[1] == "" {
+		return ProxmoxUPID{}, fmt.Errorf("invalid UPID %q", upid)
+	}
+
+	parsed := ProxmoxUPID{Node: parts[1], Type: parts[5], ID: parts[6], User: parts[7]}
+	if start, err := strconv.ParseInt(parts[4], 16, 64); err == nil {
+		parsed.StartTime = time.Unix(start, 0).UTC()
+	}
+	return parsed, nil
+}
+
+// ProxmoxTaskState is a task's state as reported by its node. Status is
+// "running" or "stopped"; ExitStatus is set once stopped.
+type ProxmoxTaskState struct {
+	Status     string `json:"status"`
+	ExitStatus string `json:"exitstatus"`
+}
+
+type ProxmoxTaskLogLine struct {
+	N int    `json:"n"`
+	T string `json:"t"`
+}
+
+func taskPath(node, upid string) string {
+	return fmt.Sprintf("/nodes/%s/tasks/%s", node, url.PathEscape(upid))
+}
+
+func (p *ProxmoxClient) GetTaskStatus(node, upid string) (*ProxmoxTaskState, error) {
+	var state ProxmoxTaskState
+	if err := p.request(http.MethodGet, taskPath(node, upid)+"/status", nil, &state); err != nil {
+		return nil, fmt.Errorf("failed to get task status: %w", err)
+	}
+	return &state, nil
+}
+
+// GetTaskLog returns up to limit lines of a task's log from line start.
+func (p *ProxmoxClient) GetTaskLog(node, upid string, start, limit int) ([]ProxmoxTaskLogLine, error) {
+	params := url.Values{}
+	params.Set("start", strconv.Itoa(start))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var lines []ProxmoxTaskLogLine
+	if err := p.request(http.MethodGet, taskPath(node, upid)+"/log", params, &lines); err != nil {
+		return nil, fmt.Errorf("failed to get task log: %w", err)
+	}
+	return lines, nil
+}
+
+// TaskResult maps a stopped task's exit status to a tracked status. Tasks
+// that finished with warnings still succeeded.
+func TaskResult(exitStatus string) models.ProxmoxTaskStatus {
+	if exitStatus == "OK" || strings.HasPrefix(exitStatus, "WARNINGS") {
+		return models.TaskStatusOK
+	}
+	return models.TaskStatusFailed
+}
+
+// TrackTask records a task started on a server by a user (nil for the
+// system) so its outcome is polled, and returns the tracked task's ID.
+func TrackTask(serverID int64, upid string, userID *int64, description string) (int64, error) {
+	parsed, err := ParseUPID(upid)
+	if err != nil {
+		return 0, err
+	}
+
+	startedAt := parsed.StartTime
+	if startedAt.IsZero() {
+		startedAt = time.Now().UTC()
+	}
+	var vmid *int
+	if id, err := strconv.Atoi(parsed.ID); err == nil {
+		vmid = &id
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO proxmox_tasks (server_id, upid, node, type, vmid, description, user_id, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(server_id, upid) DO NOTHING
+	`, serverID, upid, parsed.Node, parsed.Type, vmid, description, userID, startedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	id, _ := result.LastInsertId()
+	if n, _ := result.RowsAffected(); n == 0 {
+		database.DB.QueryRow("SELECT id FROM proxmox_tasks WHERE server_id = ? AND upid = ?", serverID, upid).Scan(&id)
+		return id, nil
+	}
+
+	if task, err := GetTask(id); err == nil && task != nil {
+		PublishEvent(EventTaskStarted, task)
+	}
+	return id, nil
+}
+
+// taskColumns selects a models.ProxmoxTask, scanned by scanTask.
+const taskColumns = `
+	t.id, t.server_id, COALESCE(s.name, ''), t.upid, t.node, t.type, t.vmid, t.description,
+	t.user_id, COALESCE(u.username, ''), t.status, t.exit_status, t.started_at, t.finished_at
+	FROM proxmox_tasks t
+	LEFT JOIN servers s ON t.server_id = s.id
+	LEFT JOIN users u ON t.user_id = u.id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row rowScanner) (*models.ProxmoxTask, error) {
+	var task models.ProxmoxTask
+	var vmid sql.NullInt64
+	var userID sql.NullInt64
+	var finishedAt sql.NullTime
+	err := row.Scan(&task.ID, &task.ServerID, &task.ServerName, &task.UPID, &task.Node, &task.Type, &vmid,
+		&task.Description, &userID, &task.Username, &task.Status, &task.ExitStatus, &task.StartedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if vmid.Valid {
+		v := int(vmid.Int64)
+		task.VMID = &v
+	}
+	if userID.Valid {
+		task.UserID = &userID.Int64
+	}
+	if finishedAt.Valid {
+		task.FinishedAt = &finishedAt.Time
+	}
+	return &task, nil
+}
+
+// GetTask loads a tracked task, or nil if it does not exist.
+func GetTask(id int64) (*models.ProxmoxTask, error) {
+	task, err := scanTask(database.DB.QueryRow("SELECT "+taskColumns+" WHERE t.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return task, err
+}
+
+// TaskFilter narrows ListTasks. Zero values match everything.
+type TaskFilter struct {
+	ServerID int64
+	UserID   int64
+	VMID     int
+	Status   string
+	Limit    int
+}
+
+func ListTasks(filter TaskFilter) ([]models.ProxmoxTask, error) {
+	query := "SELECT " + taskColumns + " WHERE 1=1"
+	var args []interface{}
+	if filter.ServerID != 0 {
+		query += " AND t.server_id = ?"
+		args = append(args, filter.ServerID)
+	}
+	if filter.UserID != 0 {
+		query += " AND t.user_id = ?"
+		args = append(args, filter.UserID)
+	}
+	if filter.VMID != 0 {
+		query += " AND t.vmid = ?"
+		args = append(args, filter.VMID)
+	}
+	if filter.Status != "" {
+		query += " AND t.status = ?"
+		args = append(args, filter.Status)
+	}
+	query += " ORDER BY t.started_at DESC, t.id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.ProxmoxTask{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+const (
+	// taskPollInterval is how often running tasks are checked.
+	taskPollInterval = 3 * time.Second
+	// taskGiveUpAfter is how long a task whose status cannot be read is
+	// retried before it is marked unknown.
+	taskGiveUpAfter = 24 * time.Hour
+)
+
+// TaskPoller polls the status of running tasks until they finish.
+type TaskPoller struct {
+	stopChan chan struct{}
+}
+
+var (
+	taskPoller     *TaskPoller
+	taskPollerOnce sync.Once
+)
+
+func GetTaskPoller() *TaskPoller {
+	taskPollerOnce.Do(func() {
+		taskPoller = &TaskPoller{
+			stopChan: make(chan struct{}),
+		}
+	})
+	return taskPoller
+}
+
+func (s *TaskPoller) Start() {
+	go func() {
+		ticker := time.NewTicker(taskPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.pollRunning()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Task poller started")
+}
+
+func (s *TaskPoller) Stop() {
+	close(s.stopChan)
+}
+
+type runningTask struct {
+	id        int64
+	serverID  int64
+	upid      string
+	node      string
+	startedAt time.Time
+}
+
+func (s *TaskPoller) pollRunning() {
+	rows, err := database.DB.Query(`
+		SELECT id, server_id, upid, node, started_at FROM proxmox_tasks WHERE status = 'running'
+	`)
+	if err != nil {
+		log.Printf("[TASKS] Failed to fetch running tasks: %v", err)
+		return
+	}
+
+	byServer := map[int64][]runningTask{}
+	for rows.Next() {
+		var t runningTask
+		if err := rows.Scan(&t.id, &t.serverID, &t.upid, &t.node, &t.startedAt); err != nil {
+			continue
+		}
+		byServer[t.serverID] = append(byServer[t.serverID], t)
+	}
+	rows.Close()
+
+	for serverID, tasks := range byServer {
+		server, err := GetServerByID(serverID)
+		if err != nil || server == nil {
+			continue
+		}
+		client := ProxmoxClientFor(*server)
+
+		for _, t := range tasks {
+			state, err := client.GetTaskStatus(t.node, t.upid)
+			if err != nil {
+				if time.Since(t.startedAt) > taskGiveUpAfter {
+					finishTask(t.id, models.TaskStatusUnknown, err.Error())
+				}
+				continue
+			}
+			if state.Status != "running" {
+				finishTask(t.id, TaskResult(state.ExitStatus), state.ExitStatus)
+			}
+		}
+	}
+}
+
+// finishTask records a task's outcome and publishes it.
+func finishTask(id int64, status models.ProxmoxTaskStatus, exitStatus string) {
+	result, err := database.DB.Exec(`
+		UPDATE proxmox_tasks SET status = ?, exit_status = ?, finished_at = ?
+		WHERE id = ? AND status = 'running'
+	`, status, exitStatus, time.Now().UTC(), id)
+	if err != nil {
+		log.Printf("[TASKS] Failed to update task %d: %v", id, err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return
+	}
+
+	if task, err := GetTask(id); err == nil && task != nil {
+		PublishEvent(EventTaskFinished, task)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VMActions are the power actions that can be run on a QEMU VM.
+var VMActions = map[string]bool{
+	"start":    true,
+	"shutdown": true,
+	"stop":     true,
+	"reboot":   true,
+	"suspend":  true,
+	"resume":   true,
+}
+
+// vmDiskKey matches the configuration keys of a VM's drives.
+var vmDiskKey = regexp.MustCompile(`^((ide|sata|scsi|virtio)\d+|efidisk0|tpmstate0)$`)
+
+// IsVMDiskKey reports whether key names a VM drive, such as scsi0.
+func IsVMDiskKey(key string) bool {
+	return vmDiskKey.MatchString(key)
+}
+
+// ProxmoxVMConfig is the CPU, memory and disk configuration of a VM. Disks
+// maps drive keys such as scsi0 to their volume, leaving out CD-ROM drives.
+// Digest identifies this version of the configuration and can be sent with
+// an update so it fails if the VM was changed in the meantime.
+type ProxmoxVMConfig struct {
+	Name    string            `json:"name,omitempty"`
+	Cores   int               `json:"cores"`
+	Sockets int               `json:"sockets"`
+	CPU     string            `json:"cpu,omitempty"`
+	Memory  int               `json:"memory"`
+	Balloon *int              `json:"balloon,omitempty"`
+	Disks   map[string]string `json:"disks"`
+	Digest  string            `json:"digest"`
+}
+
+// VMAction runs a power action on a VM and returns the task's UPID.
+func (p *ProxmoxClient) VMAction(node string, vmid int, action string) (string, error) {
+	if !VMActions[action] {
+		return "", fmt.Errorf("invalid VM action %q", action)
+	}
+
+	var upid string
+	path := fmt.Sprintf("/nodes/%s/qemu/%d/status/%s", node, vmid, action)
+	if err := p.request(http.MethodPost, path, url.Values{}, &upid); err != nil {
+		return "", fmt.Errorf("failed to %s VM: %w", action, err)
+	}
+	return upid, nil
+}
+
+// DeleteVM destroys a VM, and with purge also removes it from backup jobs,
+// replication and HA. It returns the task's UPID.
+func (p *ProxmoxClient) DeleteVM(node string, vmid int, purge bool) (string, error) {
+	params := url.Values{}
+	if purge {
+		params.Set("purge", "1")
+	}
+
+	var upid string
+	if err := p.request(http.MethodDelete, fmt.Sprintf("/nodes/%s/qemu/%d", node, vmid), params, &upid); err != nil {
+		return "", fmt.Errorf("failed to delete VM: %w", err)
+	}
+	return upid, nil
+}
+
+func (p *ProxmoxClient) GetVMConfig(node string, vmid int) (*ProxmoxVMConfig, error) {
+	var raw map[string]interface{}
+	if err := p.request(http.MethodGet, fmt.Sprintf("/nodes/%s/qemu/%d/config", node, vmid), nil, &raw); err != nil {
+		return nil, fmt.Errorf("failed to get VM config: %w", err)
+	}
+
+	config := &ProxmoxVMConfig{
+		Name:    configString(raw["name"]),
+		Cores:   configInt(raw["cores"], 1),
+		Sockets: configInt(raw["sockets"], 1),
+		CPU:     configString(raw["cpu"]),
+		Memory:  configInt(raw["memory"], 0),
+		Disks:   map[string]string{},
+		Digest:  configString(raw["digest"]),
+	}
+	if _, ok := raw["balloon"]; ok {
+		balloon := configInt(raw["balloon"], 0)
+		config.Balloon = &balloon
+	}
+	for key, value := range raw {
+		volume := configString(value)
+		if IsVMDiskKey(key) && !strings.Contains(volume, "media=cdrom") {
+			config.Disks[key] = volume
+		}
+	}
+
+	return config, nil
+}
+
+// UpdateVMConfig sets configuration options, e.g. cores=4 or
+// scsi1=local-lvm:32 to add a 32 GiB disk, and returns the task's UPID.
+func (p *ProxmoxClient) UpdateVMConfig(node string, vmid int, params url.Values) (string, error) {
+	var upid string
+	if err := p.request(http.MethodPost, fmt.Sprintf("/nodes/%s/qemu/%d/config", node, vmid), params, &upid); err != nil {
+		return "", fmt.Errorf("failed to update VM config: %w", err)
+	}
+	return upid, nil
+}
+
+// ResizeVMDisk grows a disk to size, or by size when it starts with "+"
+// (e.g. "+10G"). Proxmox versions that resize synchronously return no UPID.
+func (p *ProxmoxClient) ResizeVMDisk(node string, vmid int, disk, size string) (string, error) {
+	params := url.Values{}
+	params.Set("disk", disk)
+	params.Set("size", size)
+
+	var upid string
+	if err := p.request(http.MethodPut, fmt.Sprintf("/nodes/%s/qemu/%d/resize", node, vmid), params, &upid); err != nil {
+		return "", fmt.Errorf("failed to resize disk %s: %w", disk, err)
+	}
+	return upid, nil
+}
+
+// configString returns a configuration value as text. Proxmox returns most
+// options as strings but numbers for some.
+func configString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// configInt returns a numeric configuration value, or def when it is unset.
+// Newer Proxmox versions give memory as a property string
+// ("current=2048"), whose current size is used.
+func configInt(value interface{}, def int) int {
+	text := strings.TrimPrefix(configString(value), "current=")
+	if i := strings.IndexByte(text, ','); i >= 0 {
+		text = text[:i]
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return def
+	}
+	return n
+}
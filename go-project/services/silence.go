@@ -0,0 +1,36 @@
+package services
+
+import (
+	"database/sql"
+	"go-project/database"
+	"go-project/models"
+	"time"
+)
+
+// MatchSilence returns the silence active now that matches an alert, or nil
+// when none does. A silence that suppresses creation wins over one that only
+// mutes notifications.
+func MatchSilence(ruleID int64, alertType string, severity models.AlertSeverity, targetID int64, targetName string) (*models.AlertSilence, error) {
+	now := time.Now().UTC()
+
+	var silence models.AlertSilence
+	err := database.DB.QueryRow(`
+		SELECT id, suppress_creation, comment
+		FROM alert_silences
+		WHERE starts_at <= ? AND ends_at > ?
+		AND (alert_rule_id IS NULL OR alert_rule_id = ?)
+		AND (target_id IS NULL OR target_id = ?)
+		AND (target_name IS NULL OR target_name = ?)
+		AND (type IS NULL OR type = ?)
+		AND (severity IS NULL OR severity = ?)
+		ORDER BY suppress_creation DESC, ends_at DESC
+		LIMIT 1
+	`, now, now, ruleID, targetID, targetName, alertType, string(severity)).Scan(&silence.ID, &silence.SuppressCreation, &silence.Comment)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
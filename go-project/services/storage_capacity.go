@@ -0,0 +1,264 @@
+package services
+
+import (
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// storageSampleInterval is how often storage usage is sampled. Storage
+	// fills over days, so this is much coarser than node metrics.
+	storageSampleInterval = 5 * time.Minute
+	// storageSampleRetention bounds the history kept for forecasts.
+	storageSampleRetention = 30 * 24 * time.Hour
+	// storageForecastMinSpan is the least history a forecast is made from.
+	storageForecastMinSpan = time.Hour
+	// storageForecastHorizon is how far ahead a fill date is predicted;
+	// slower growth is reported without one.
+	storageForecastHorizon = 5 * 365 * 24 * time.Hour
+	// StorageForecastDays is the default window forecasts are fitted over.
+	StorageForecastDays = 7
+)
+
+// StorageCollector samples the usage of every storage of every Proxmox
+// server.
+type StorageCollector struct {
+	stopChan chan struct{}
+}
+
+var (
+	storageCollector     *StorageCollector
+	storageCollectorOnce sync.Once
+)
+
+func GetStorageCollector() *StorageCollector {
+	storageCollectorOnce.Do(func() {
+		storageCollector = &StorageCollector{
+			stopChan: make(chan struct{}),
+		}
+	})
+	return storageCollector
+}
+
+func (s *StorageCollector) Start() {
+	go func() {
+		ticker := time.NewTicker(storageSampleInterval)
+		defer ticker.Stop()
+
+		s.Collect()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.Collect()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Storage collector started")
+}
+
+func (s *StorageCollector) Stop() {
+	close(s.stopChan)
+}
+
+// Collect records a usage sample of every active storage.
+func (s *StorageCollector) Collect() {
+	servers, err := GetProxmoxServers()
+	if err != nil {
+		log.Printf("[STORAGE] Failed to fetch servers: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, srv := range servers {
+		storages, err := ProxmoxClientFor(srv).GetClusterStorages()
+		if err != nil {
+			log.Printf("[STORAGE] Failed to fetch storages of server %d: %v", srv.ID, err)
+			continue
+		}
+
+		for _, st := range storages {
+			if st.Active == 0 || st.Total <= 0 {
+				continue
+			}
+			_, err := database.DB.Exec(`
+				INSERT INTO storage_samples (server_id, node, storage, type, content, shared, used, total, sampled_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, srv.ID, st.Node, st.Storage, st.Type, st.Content, st.Shared, st.Used, st.Total, now)
+			if err != nil {
+				log.Printf("[STORAGE] Failed to record sample of %s: %v", st.Storage, err)
+			}
+		}
+	}
+
+	_, err = database.DB.Exec("DELETE FROM storage_samples WHERE sampled_at < ?", now.Add(-storageSampleRetention))
+	if err != nil {
+		log.Printf("[STORAGE] Failed to prune samples: %v", err)
+	}
+}
+
+// GetStorageHistory returns a storage's samples since the given time, oldest
+// first.
+func GetStorageHistory(serverID int64, node, storage string, since time.Time) ([]models.StorageSample, error) {
+	rows, err := database.DB.Query(`
+		SELECT used, total, sampled_at FROM storage_samples
+		WHERE server_id = ? AND node = ? AND storage = ? AND sampled_at >= ?
+		ORDER BY sampled_at
+	`, serverID, node, storage, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []models.StorageSample{}
+	for rows.Next() {
+		var sample models.StorageSample
+		if err := rows.Scan(&sample.Used, &sample.Total, &sample.SampledAt); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+// ForecastStorage fits a line through a storage's usage over the last
+// windowDays and extrapolates when the latest sample's free space runs out.
+// It returns nil when there is less than storageForecastMinSpan of history.
+func ForecastStorage(serverID int64, node, storage string, windowDays int) (*models.StorageForecast, error) {
+	samples, err := GetStorageHistory(serverID, node, storage, time.Now().AddDate(0, 0, -windowDays))
+	if err != nil {
+		return nil, err
+	}
+	return forecastFromSamples(samples, windowDays), nil
+}
+
+func forecastFromSamples(samples []models.StorageSample, windowDays int) *models.StorageForecast {
+	if len(samples) < 2 {
+		return nil
+	}
+	first, latest := samples[0], samples[len(samples)-1]
+	if latest.SampledAt.Sub(first.SampledAt) < storageForecastMinSpan {
+		return nil
+	}
+
+	// Least squares slope of used bytes over seconds since the first sample.
+	var meanX, meanY float64
+	for _, sample := range samples {
+		meanX += sample.SampledAt.Sub(first.SampledAt).Seconds()
+		meanY += float64(sample.Used)
+	}
+	n := float64(len(samples))
+	meanX /= n
+	meanY /= n
+
+	var cov, variance float64
+	for _, sample := range samples {
+		dx := sample.SampledAt.Sub(first.SampledAt).Seconds() - meanX
+		cov += dx * (float64(sample.Used) - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return nil
+	}
+	slope := cov / variance
+
+	forecast := &models.StorageForecast{
+		WindowDays:   windowDays,
+		Samples:      len(samples),
+		GrowthPerDay: math.Round(slope * 86400),
+	}
+	if slope > 0 {
+		free := math.Max(float64(latest.Total-latest.Used), 0)
+		seconds := free / slope
+		if seconds > storageForecastHorizon.Seconds() {
+			return forecast
+		}
+		fullAt := latest.SampledAt.Add(time.Duration(seconds * float64(time.Second))).UTC()
+		days := math.Round(time.Until(fullAt).Hours()/24*10) / 10
+		if days < 0 {
+			days = 0
+		}
+		forecast.FullAt = &fullAt
+		forecast.DaysUntilFull = &days
+	}
+	return forecast
+}
+
+// BuildStorageOverview lists the current usage of every storage of every
+// Proxmox server, fullest first, each with a forecast over windowDays when
+// enough history has been sampled. Servers that cannot be reached are listed
+// as errors.
+func BuildStorageOverview(serverID int64, windowDays int) (models.StorageOverview, error) {
+	overview := models.StorageOverview{
+		GeneratedAt: time.Now().UTC(),
+		Storages:    []models.StorageStatus{},
+		Errors:      []models.ServerError{},
+	}
+
+	servers, err := GetProxmoxServers()
+	if err != nil {
+		return overview, err
+	}
+
+	for _, srv := range servers {
+		if serverID != 0 && srv.ID != serverID {
+			continue
+		}
+
+		storages, err := ProxmoxClientFor(srv).GetClusterStorages()
+		if err != nil {
+			overview.Errors = append(overview.Errors, models.ServerError{
+				ServerID: srv.ID, ServerName: srv.Name, Error: err.Error(),
+			})
+			continue
+		}
+
+		for _, st := range storages {
+			status := models.StorageStatus{
+				ServerID:   srv.ID,
+				ServerName: srv.Name,
+				Node:       st.Node,
+				Storage:    st.Storage,
+				Type:       st.Type,
+				Content:    st.Content,
+				Shared:     st.Shared == 1,
+				Active:     st.Active == 1,
+				Used:       st.Used,
+				Total:      st.Total,
+				Avail:      st.Avail,
+			}
+			if st.Total > 0 {
+				status.UsagePercent = math.Round(float64(st.Used)/float64(st.Total)*1000) / 10
+			}
+
+			forecast, err := ForecastStorage(srv.ID, st.Node, st.Storage, windowDays)
+			if err != nil {
+				log.Printf("[STORAGE] Failed to forecast %s: %v", st.Storage, err)
+			}
+			status.Forecast = forecast
+
+			overview.Storages = append(overview.Storages, status)
+		}
+	}
+
+	sort.SliceStable(overview.Storages, func(i, j int) bool {
+		return overview.Storages[i].UsagePercent > overview.Storages[j].UsagePercent
+	})
+	return overview, nil
+}
+
+// StorageTargetName names a storage in alerts: node/storage, or just the
+// storage when it is shared.
+func StorageTargetName(node, storage string) string {
+	if node == "" {
+		return storage
+	}
+	return node + "/" + storage
+}
@@ -1,9 +1,11 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
+	"go-project/models"
 	"io"
 	"log"
 	"net/http"
@@ -21,8 +23,13 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user, authTicket, terminalTicket string, port int, verifySSL bool) {
-	log.Printf("[TERMINAL] Starting proxy: baseURL=%s, node=%s, port=%d", baseURL, node, port)
+// ProxyTerminal upgrades the request to a WebSocket and relays it to the
+// termproxy session term, authenticating with the client's credentials.
+// Messages are relayed unchanged in both directions, so the browser speaks
+// the xterm.js protocol of Proxmox itself. The session is recorded, and is
+// refused or ended when it cannot be.
+func ProxyTerminal(w http.ResponseWriter, r *http.Request, client *ProxmoxClient, term *ProxmoxTermProxyResponse, session models.TerminalSession) {
+	log.Printf("[TERMINAL] Starting proxy: path=%s, port=%d", term.Path, term.Port)
 
 	clientConn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -38,10 +45,17 @@ func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user,
 			clientConn.WriteMessage(websocket.TextMessage, []byte("\r\n\x1b[1;31m// Session Error: "+fmt.Sprint(err)+"\x1b[0m\r\n"))
 		}
 		clientConn.Close()
-		log.Printf("[TERMINAL] Session terminated for node %s", node)
+		log.Printf("[TERMINAL] Session terminated for %s", term.Path)
 	}
 
-	u, err := url.Parse(baseURL)
+	recorder, err := StartTerminalRecording(&session)
+	if err != nil {
+		finishSession(err)
+		return
+	}
+	defer recorder.Close()
+
+	u, err := url.Parse(client.BaseURL)
 	if err != nil {
 		log.Printf("[TERMINAL] URL parse failed: %v", err)
 		finishSession(nil)
@@ -54,21 +68,24 @@ func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user,
 		scheme = "ws"
 	}
 
-	proxmoxWSURL := fmt.Sprintf("%s://%s/api2/json/nodes/%s/vncwebsocket?port=%d&vncticket=%s&websocket=1",
-		scheme, host, node, port, url.QueryEscape(terminalTicket))
+	proxmoxWSURL := fmt.Sprintf("%s://%s/api2/json%s?port=%d&vncticket=%s&websocket=1",
+		scheme, host, term.Path, term.Port, url.QueryEscape(term.Ticket))
 
-	log.Printf("[TERMINAL] Connecting to Proxmox: %s", proxmoxWSURL)
+	log.Printf("[TERMINAL] Connecting to Proxmox: %s", term.Path)
 
 	dialer := websocket.Dialer{
-		TLSClientConfig:  &tls.Config{InsecureSkipVerify: !verifySSL},
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: !client.VerifySSL},
 		ReadBufferSize:   8192,
 		WriteBufferSize:  8192,
 		HandshakeTimeout: 10 * time.Second,
 	}
 
-	pveHeader := http.Header{}
-	pveHeader.Add("Cookie", "PVEAuthCookie="+authTicket)
-	pveHeader.Add("Origin", baseURL)
+	pveHeader, err := client.AuthHeader()
+	if err != nil {
+		finishSession(err)
+		return
+	}
+	pveHeader.Set("Origin", client.BaseURL)
 
 	pveConn, resp, err := dialer.Dial(proxmoxWSURL, pveHeader)
 	if err != nil {
@@ -85,7 +102,7 @@ func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user,
 
 	log.Printf("[TERMINAL] Proxmox WebSocket connection established")
 
-	handshakeMessage := fmt.Sprintf("%s:%s\n", user, terminalTicket)
+	handshakeMessage := fmt.Sprintf("%s:%s\n", term.User, term.Ticket)
 	if err := pveConn.WriteMessage(websocket.BinaryMessage, []byte(handshakeMessage)); err != nil {
 		log.Printf("[TERMINAL] Handshake write failed: %v", err)
 		finishSession(err)
@@ -97,11 +114,13 @@ func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user,
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	errChan := make(chan error, 1)
-	done := make(chan struct{}, 1)
+	// Both goroutines may fail, so the channels have room for both.
+	errChan := make(chan error, 2)
+	done := make(chan struct{}, 2)
 
 	proxyFromProxmox := func() {
 		defer func() { done <- struct{}{} }()
+		acknowledged := false
 		for {
 			select {
 			case <-ctx.Done():
@@ -112,6 +131,19 @@ func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user,
 					errChan <- fmt.Errorf("proxmox read: %w", err)
 					return
 				}
+				output := message
+				// Proxmox acknowledges the handshake with "OK", which is not
+				// terminal output.
+				if !acknowledged && bytes.HasPrefix(message, []byte("OK")) {
+					acknowledged = true
+					output = message[2:]
+				}
+				if len(output) > 0 {
+					if err := recorder.Output(output); err != nil {
+						errChan <- err
+						return
+					}
+				}
 				if err := clientConn.WriteMessage(messageType, message); err != nil {
 					errChan <- fmt.Errorf("client write: %w", err)
 					return
@@ -132,6 +164,10 @@ func ProxyTerminal(w http.ResponseWriter, r *http.Request, baseURL, node, user,
 					errChan <- fmt.Errorf("client read: %w", err)
 					return
 				}
+				if err := recorder.ClientMessage(message); err != nil {
+					errChan <- err
+					return
+				}
 				if err := pveConn.WriteMessage(messageType, message); err != nil {
 					errChan <- fmt.Errorf("proxmox write: %w", err)
 					return
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-project/database"
+	"go-project/models"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultTerminalWidth and defaultTerminalHeight size recordings whose
+	// terminal was not resized before its first output.
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+// terminalRecordingsDir is where recordings are written, TERMINAL_RECORDINGS_DIR
+// or ./recordings.
+func terminalRecordingsDir() string {
+	if dir := os.Getenv("TERMINAL_RECORDINGS_DIR"); dir != "" {
+		return dir
+	}
+	return "./recordings"
+}
+
+// TerminalRecorder writes a terminal session's output to an asciicast v2
+// file. The header is written with the first event, so a resize sent before
+// any output sets the recorded terminal size. It is safe for concurrent use.
+type TerminalRecorder struct {
+	sessionID int64
+	file      *os.File
+	startedAt time.Time
+
+	mu          sync.Mutex
+	header      bool
+	width       int
+	height      int
+	title       string
+	size        int64
+	lastEventAt float64
+	pending     []byte // Start of a UTF-8 sequence split across messages
+}
+
+// StartTerminalRecording records a new session and creates its recording.
+// The session's ID and start time are set.
+func StartTerminalRecording(session *models.TerminalSession) (*TerminalRecorder, error) {
+	dir := terminalRecordingsDir()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+
+	session.StartedAt = time.Now().UTC()
+	result, err := database.DB.Exec(`
+		INSERT INTO terminal_sessions (server_id, server_name, user_id, username, node, guest_type, vmid, client_ip, started_at)
+		VALUES (?, ?, ?, COALESCE((SELECT username FROM users WHERE id = ?), ''), ?, ?, ?, ?, ?)
+	`, session.ServerID, session.ServerName, session.UserID, session.UserID, session.Node, session.GuestType,
+		session.VMID, session.ClientIP, session.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session: %w", err)
+	}
+	session.ID, _ = result.LastInsertId()
+
+	name := fmt.Sprintf("%d-%s.cast", session.ID, session.StartedAt.Format("20060102T150405Z"))
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	if _, err := database.DB.Exec("UPDATE terminal_sessions SET recording = ? WHERE id = ?", name, session.ID); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to record session: %w", err)
+	}
+
+	title := fmt.Sprintf("%s: shell on %s", session.ServerName, session.Node)
+	if session.VMID != 0 {
+		title = fmt.Sprintf("%s: %s %d on %s", session.ServerName, session.GuestType, session.VMID, session.Node)
+	}
+
+	log.Printf("[TERMINAL] Recording session %d to %s", session.ID, name)
+	return &TerminalRecorder{
+		sessionID: session.ID,
+		file:      file,
+		startedAt: time.Now(),
+		width:     defaultTerminalWidth,
+		height:    defaultTerminalHeight,
+		title:     title,
+	}, nil
+}
+
+// Output records terminal output.
+func (t *TerminalRecorder) Output(data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Events are JSON strings, so a multi-byte character split across
+	// messages is held back until it is complete.
+	if len(t.pending) > 0 {
+		data = append(t.pending, data...)
+		t.pending = nil
+	}
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	t.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return nil
+	}
+
+	return t.event("o", string(data[:cut]))
+}
+
+// Resize records a change of the terminal size.
+func (t *TerminalRecorder) Resize(cols, rows int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.header {
+		t.width, t.height = cols, rows
+		return nil
+	}
+	return t.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// ClientMessage records what a termproxy client message changes about the
+// terminal. Only resizes, "1:<cols>:<rows>:", are recorded; the input itself
+// is echoed in the output.
+func (t *TerminalRecorder) ClientMessage(message []byte) error {
+	parts := strings.Split(string(message), ":")
+	if len(parts) < 3 || parts[0] != "1" {
+		return nil
+	}
+	cols, err := strconv.Atoi(parts[1])
+	if err != nil || cols <= 0 {
+		return nil
+	}
+	rows, err := strconv.Atoi(parts[2])
+	if err != nil || rows <= 0 {
+		return nil
+	}
+	return t.Resize(cols, rows)
+}
+
+// event writes an event, and the header before the first. The caller must
+// hold t.mu.
+func (t *TerminalRecorder) event(code, data string) error {
+	if !t.header {
+		if err := t.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	elapsed := math.Round(time.Since(t.startedAt).Seconds()*1e6) / 1e6
+	event, _ := json.Marshal([]interface{}{elapsed, code, data})
+	if err := t.write(event); err != nil {
+		return err
+	}
+	t.lastEventAt = elapsed
+	return nil
+}
+
+// writeHeader writes the asciicast header with the current terminal size. The
+// caller must hold t.mu.
+func (t *TerminalRecorder) writeHeader() error {
+	header, _ := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     t.width,
+		"height":    t.height,
+		"timestamp": t.startedAt.Unix(),
+		"title":     t.title,
+		"env":       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err := t.write(header); err != nil {
+		return err
+	}
+	t.header = true
+	return nil
+}
+
+func (t *TerminalRecorder) write(line []byte) error {
+	n, err := t.file.Write(append(line, '\n'))
+	t.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// Close finishes the recording and marks the session ended. A session that
+// ended before any output still gets a header, so its recording is a valid
+// asciicast.
+func (t *TerminalRecorder) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var err error
+	if len(t.pending) > 0 {
+		err = t.event("o", string(t.pending))
+		t.pending = nil
+	} else if !t.header {
+		err = t.writeHeader()
+	}
+	if err != nil {
+		log.Printf("[TERMINAL] Failed to finish recording of session %d: %v", t.sessionID, err)
+	}
+	if err := t.file.Close(); err != nil {
+		log.Printf("[TERMINAL] Failed to close recording of session %d: %v", t.sessionID, err)
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE terminal_sessions SET ended_at = ?, size = ?, duration = ? WHERE id = ?
+	`, time.Now().UTC(), t.size, t.lastEventAt, t.sessionID)
+	if err != nil {
+		log.Printf("[TERMINAL] Failed to end session %d: %v", t.sessionID, err)
+	}
+}
+
+// terminalSessionColumns selects a models.TerminalSession, scanned by
+// scanTerminalSession, followed by its recording's file name.
+const terminalSessionColumns = `
+	id, server_id, server_name, user_id, username, node, guest_type, vmid, client_ip,
+	size, duration, started_at, ended_at, recording
+	FROM terminal_sessions`
+
+func scanTerminalSession(row rowScanner) (*models.TerminalSession, string, error) {
+	var session models.TerminalSession
+	var serverID, userID sql.NullInt64
+	var endedAt sql.NullTime
+	var recording string
+	err := row.Scan(&session.ID, &serverID, &session.ServerName, &userID, &session.Username, &session.Node,
+		&session.GuestType, &session.VMID, &session.ClientIP, &session.Size, &session.Duration,
+		&session.StartedAt, &endedAt, &recording)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if serverID.Valid {
+		session.ServerID = &serverID.Int64
+	}
+	if userID.Valid {
+		session.UserID = &userID.Int64
+	}
+	if endedAt.Valid {
+		session.EndedAt = &endedAt.Time
+	}
+	return &session, recording, nil
+}
+
+// GetTerminalSession returns a session and the path of its recording, or nil
+// if there is no such session.
+func GetTerminalSession(id int64) (*models.TerminalSession, string, error) {
+	session, recording, err := scanTerminalSession(database.DB.QueryRow("SELECT "+terminalSessionColumns+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if recording == "" {
+		return session, "", nil
+	}
+	return session, filepath.Join(terminalRecordingsDir(), recording), nil
+}
+
+// TerminalSessionFilter narrows ListTerminalSessions. Zero values match
+// everything.
+type TerminalSessionFilter struct {
+	ServerID int64
+	UserID   int64
+	VMID     int
+	Limit    int
+}
+
+func ListTerminalSessions(filter TerminalSessionFilter) ([]models.TerminalSession, error) {
+	query := "SELECT " + terminalSessionColumns + " WHERE 1=1"
+	var args []interface{}
+	if filter.ServerID != 0 {
+		query += " AND server_id = ?"
+		args = append(args, filter.ServerID)
+	}
+	if filter.UserID != 0 {
+		query += " AND user_id = ?"
+		args = append(args, filter.UserID)
+	}
+	if filter.VMID != 0 {
+		query += " AND vmid = ?"
+		args = append(args, filter.VMID)
+	}
+	query += " ORDER BY started_at DESC, id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.TerminalSession{}
+	for rows.Next() {
+		session, _, err := scanTerminalSession(rows)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
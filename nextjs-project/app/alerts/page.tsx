@@ -1,7 +1,7 @@
 'use client';
 
 import React, { useEffect, useState } from 'react';
-import { alertsAPI, Alert, AlertRule, serversAPI, Monitor, Server } from '@/lib/api';
+import { alertsAPI, Alert, AlertRule, serversAPI, Monitor, Server, subscribeEvents } from '@/lib/api';
 import { Bell, AlertTriangle, CheckCircle, XCircle, Plus } from 'lucide-react';
 import { cn } from '@/lib/utils';
 import Modal from '@/components/Modal';
@@ -18,8 +18,14 @@ export default function AlertsPage() {
 
   useEffect(() => {
     loadData();
-    const interval = setInterval(loadData, 15000);
-    return () => clearInterval(interval);
+    // Alert events trigger an immediate reload; the slow poll only covers
+    // changes missed while the stream reconnects.
+    const unsubscribe = subscribeEvents(['alert.created', 'alert.acknowledged', 'alert.resolved'], loadData);
+    const interval = setInterval(loadData, 60000);
+    return () => {
+      unsubscribe();
+      clearInterval(interval);
+    };
   }, []);
 
   const loadData = async () => {
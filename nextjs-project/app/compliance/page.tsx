@@ -5,7 +5,8 @@ import { Compliance, complianceAPI } from '@/lib/api';
 import DataTable from '@/components/DataTable';
 import Modal from '@/components/Modal';
 import ComplianceForm from '@/components/ComplianceForm';
-import { Plus, ShieldCheck, ShieldAlert, AlertTriangle, Info, RefreshCw, Filter } from 'lucide-react';
+import BackupCoverage from '@/components/BackupCoverage';
+import { Plus, ShieldCheck, ShieldAlert, AlertTriangle, Info, RefreshCw, Filter, Archive } from 'lucide-react';
 import { cn, formatDate } from '@/lib/utils';
 
 export default function CompliancePage() {
@@ -14,6 +15,7 @@ export default function CompliancePage() {
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [editingItem, setEditingItem] = useState<Compliance | null>(null);
   const [submitting, setSubmitting] = useState(false);
+  const [showBackups, setShowBackups] = useState(false);
   const [filters, setFilters] = useState<{ status?: string; severity?: string }>({});
 
   const loadCompliance = async () => {
@@ -138,6 +140,13 @@ export default function CompliancePage() {
           >
             <RefreshCw size={20} className={cn(loading && "animate-spin")} />
           </button>
+          <button
+            onClick={() => setShowBackups(true)}
+            className="flex items-center gap-2 rounded-xl border border-slate-800 px-4 py-2.5 text-sm font-bold text-slate-300 hover:bg-slate-800 hover:text-cyan-400 transition-all"
+          >
+            <Archive size={18} />
+            Backup Coverage
+          </button>
           <button
             onClick={() => setIsModalOpen(true)}
             className="flex items-center gap-2 rounded-xl bg-cyan-500 px-5 py-2.5 text-sm font-bold text-slate-950 shadow-lg shadow-cyan-500/20 transition-all hover:scale-105 hover:bg-cyan-400"
@@ -180,6 +189,15 @@ export default function CompliancePage() {
         <ComplianceForm onSubmit={handleCreate} isLoading={submitting} />
       </Modal>
 
+      <Modal
+        isOpen={showBackups}
+        onClose={() => setShowBackups(false)}
+        title="Backup Coverage"
+        className="max-w-3xl"
+      >
+        {showBackups && <BackupCoverage records={compliance} onEvidenceAttached={loadCompliance} />}
+      </Modal>
+
       <Modal
         isOpen={!!editingItem}
         onClose={() => setEditingItem(null)}
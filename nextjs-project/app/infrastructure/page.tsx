@@ -2,12 +2,17 @@
 
 import React, { useEffect, useState } from 'react';
 import { infrastructureAPI, InfrastructureNode } from '@/lib/api';
-import { HardDrive, Cpu, Monitor, Box, RefreshCw, Activity } from 'lucide-react';
+import { HardDrive, Cpu, Monitor, Box, RefreshCw, Activity, LineChart } from 'lucide-react';
 import { cn, formatBytes, formatUptime } from '@/lib/utils';
+import StorageCapacity from '@/components/StorageCapacity';
+import InventoryChanges from '@/components/InventoryChanges';
+import Modal from '@/components/Modal';
+import MetricHistory from '@/components/MetricHistory';
 
 export default function InfrastructurePage() {
   const [nodes, setNodes] = useState<InfrastructureNode[]>([]);
   const [loading, setLoading] = useState(true);
+  const [history, setHistory] = useState<InfrastructureNode | null>(null);
 
   const loadNodes = async () => {
     setLoading(true);
@@ -163,6 +168,13 @@ export default function InfrastructurePage() {
                   <span className="font-mono">{node.ip_address}</span>
                   <span className="text-slate-700">•</span>
                   <span className="uppercase">{node.server_type}</span>
+                  <button
+                    onClick={() => setHistory(node)}
+                    className="ml-auto flex items-center gap-1 rounded-lg px-2 py-1 font-bold uppercase tracking-wider text-slate-400 transition-all hover:bg-cyan-500/10 hover:text-cyan-400"
+                  >
+                    <LineChart size={12} />
+                    History
+                  </button>
                 </div>
               </div>
 
@@ -174,6 +186,19 @@ export default function InfrastructurePage() {
           ))}
         </div>
       )}
+
+      <StorageCapacity />
+
+      <InventoryChanges />
+
+      <Modal
+        isOpen={!!history}
+        onClose={() => setHistory(null)}
+        title={history ? `Node History // ${history.server_name} // ${history.node_name}` : 'Node History'}
+        className="max-w-5xl"
+      >
+        {history && <MetricHistory serverId={history.id} node={history.node_name} />}
+      </Modal>
     </div>
   );
 }
\ No newline at end of file
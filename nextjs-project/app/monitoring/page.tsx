@@ -1,7 +1,7 @@
 'use client';
 
 import React, { useEffect, useState } from 'react';
-import { Monitor, monitorsAPI } from '@/lib/api';
+import { Monitor, monitorsAPI, subscribeEvents } from '@/lib/api';
 import MonitorForm from '@/components/MonitorForm';
 import MonitorDetails from '@/components/MonitorDetails';
 import Modal from '@/components/Modal';
@@ -30,9 +30,14 @@ export default function MonitoringPage() {
 
   useEffect(() => {
     loadMonitors();
-    // Auto-refresh every 30 seconds
+    // Reload as soon as a monitor changes state, and every 30 seconds for
+    // latency and uptime
+    const unsubscribe = subscribeEvents(['monitor.status_changed'], loadMonitors);
     const interval = setInterval(loadMonitors, 30000);
-    return () => clearInterval(interval);
+    return () => {
+      unsubscribe();
+      clearInterval(interval);
+    };
   }, []);
 
   const handleCreate = async (data: Partial<Monitor>) => {
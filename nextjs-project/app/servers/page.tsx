@@ -7,8 +7,9 @@ import Modal from '@/components/Modal';
 import ServerForm from '@/components/ServerForm';
 import LogViewer from '@/components/LogViewer';
 import LXCManager from '@/components/LXCManager';
+import VMManager from '@/components/VMManager';
 import ShellTerminal from '@/components/ShellTerminal';
-import { Plus, Server as ServerIcon, Globe, Shield, RefreshCw, Terminal, Box, Command } from 'lucide-react';
+import { Plus, Server as ServerIcon, Globe, Shield, RefreshCw, Terminal, Box, Command, Monitor } from 'lucide-react';
 import { cn, formatDate } from '@/lib/utils';
 
 export default function ServersPage() {
@@ -18,6 +19,7 @@ export default function ServersPage() {
   const [editingServer, setEditingServer] = useState<Server | null>(null);
   const [loggingServer, setLoggingServer] = useState<Server | null>(null);
   const [managingLXC, setManagingLXC] = useState<Server | null>(null);
+  const [managingVMs, setManagingVMs] = useState<Server | null>(null);
   const [shellServer, setShellServer] = useState<Server | null>(null);
   const [submitting, setSubmitting] = useState(false);
 
@@ -121,6 +123,13 @@ export default function ServersPage() {
               >
                 <Box size={14} />
               </button>
+              <button 
+                onClick={() => setManagingVMs(s)}
+                className="p-1 text-violet-500 hover:bg-violet-500/10 rounded transition-colors"
+                title="Manage Virtual Machines"
+              >
+                <Monitor size={14} />
+              </button>
               <button 
                 onClick={() => setShellServer(s)}
                 className="p-1 text-amber-500 hover:bg-amber-500/10 rounded transition-colors"
@@ -237,6 +246,15 @@ export default function ServersPage() {
         {managingLXC && <LXCManager server={managingLXC} />}
       </Modal>
 
+      <Modal
+        isOpen={!!managingVMs}
+        onClose={() => setManagingVMs(null)}
+        title={managingVMs ? `Virtual Machines // ${managingVMs.name}` : "VM Management"}
+        className="max-w-3xl"
+      >
+        {managingVMs && <VMManager server={managingVMs} />}
+      </Modal>
+
       <Modal
         isOpen={!!shellServer}
         onClose={() => setShellServer(null)}
@@ -0,0 +1,140 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { terminalSessionsAPI, TerminalSession } from '@/lib/api';
+import { cn, formatBytes } from '@/lib/utils';
+import Modal from '@/components/Modal';
+import SessionPlayer from '@/components/SessionPlayer';
+import { Terminal, Play, Download, RefreshCw, Activity } from 'lucide-react';
+
+// Describes what a session was opened on.
+function sessionTarget(session: TerminalSession): string {
+  if (session.guest_type === 'lxc') return `Container ${session.vmid} on ${session.node}`;
+  if (session.guest_type === 'qemu') return `VM ${session.vmid} on ${session.node}`;
+  return `Shell on ${session.node}`;
+}
+
+function formatDuration(seconds: number): string {
+  if (seconds < 60) return `${Math.round(seconds)}s`;
+  const minutes = Math.floor(seconds / 60);
+  if (minutes < 60) return `${minutes}m ${Math.round(seconds % 60)}s`;
+  return `${Math.floor(minutes / 60)}h ${minutes % 60}m`;
+}
+
+export default function SessionsPage() {
+  const [sessions, setSessions] = useState<TerminalSession[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+  const [replaying, setReplaying] = useState<TerminalSession | null>(null);
+
+  const loadSessions = async () => {
+    setLoading(true);
+    try {
+      const data = await terminalSessionsAPI.getAll({ limit: 200 });
+      setSessions(data || []);
+      setError(null);
+    } catch (err: any) {
+      console.error('Failed to load terminal sessions:', err);
+      setError(err.message || 'Failed to load terminal sessions');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    loadSessions();
+  }, []);
+
+  return (
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <div className="flex flex-col gap-1">
+          <h2 className="text-2xl font-bold text-slate-100">Terminal Sessions</h2>
+          <p className="text-sm text-slate-400">Recordings of every node shell and guest console session.</p>
+        </div>
+        <button
+          onClick={loadSessions}
+          className="rounded-xl border border-slate-800 p-2.5 text-slate-400 hover:bg-slate-800 hover:text-cyan-400 transition-all"
+        >
+          <RefreshCw size={20} className={cn(loading && "animate-spin")} />
+        </button>
+      </div>
+
+      {error && (
+        <div className="rounded-xl border border-rose-500/20 bg-rose-500/10 px-4 py-3 text-sm text-rose-400">{error}</div>
+      )}
+
+      {loading && sessions.length === 0 ? (
+        <div className="flex h-64 items-center justify-center">
+          <Activity className="animate-spin text-cyan-400" size={32} />
+        </div>
+      ) : sessions.length === 0 ? (
+        <div className="flex flex-col items-center justify-center rounded-2xl border border-dashed border-slate-800 py-12 text-center">
+          <Terminal size={48} className="mb-4 text-slate-700" />
+          <h3 className="text-lg font-bold text-slate-300">No Terminal Sessions</h3>
+          <p className="text-slate-500">Shell and console sessions are recorded here.</p>
+        </div>
+      ) : (
+        <div className="overflow-hidden rounded-2xl border border-slate-800 bg-slate-900/50">
+          <table className="w-full text-left text-sm">
+            <thead className="border-b border-slate-800 text-[10px] uppercase tracking-wider text-slate-500">
+              <tr>
+                <th className="px-6 py-3">Started</th>
+                <th className="px-6 py-3">User</th>
+                <th className="px-6 py-3">Server</th>
+                <th className="px-6 py-3">Target</th>
+                <th className="px-6 py-3">Duration</th>
+                <th className="px-6 py-3">Size</th>
+                <th className="px-6 py-3" />
+              </tr>
+            </thead>
+            <tbody className="divide-y divide-slate-800">
+              {sessions.map(session => (
+                <tr key={session.id} className="text-slate-300 hover:bg-slate-800/30">
+                  <td className="px-6 py-3 font-mono text-xs">{new Date(session.started_at).toLocaleString()}</td>
+                  <td className="px-6 py-3">
+                    <div>{session.username || 'Deleted user'}</div>
+                    <div className="font-mono text-[10px] text-slate-500">{session.client_ip}</div>
+                  </td>
+                  <td className="px-6 py-3">{session.server_name}</td>
+                  <td className="px-6 py-3">{sessionTarget(session)}</td>
+                  <td className="px-6 py-3 font-mono text-xs">
+                    {session.ended_at ? formatDuration(session.duration) : <span className="text-emerald-400">Active</span>}
+                  </td>
+                  <td className="px-6 py-3 font-mono text-xs">{formatBytes(session.size)}</td>
+                  <td className="px-6 py-3">
+                    <div className="flex items-center justify-end gap-1">
+                      <button
+                        onClick={() => setReplaying(session)}
+                        className="rounded-lg p-2 text-slate-400 transition-all hover:bg-cyan-500/10 hover:text-cyan-400"
+                        title="Replay"
+                      >
+                        <Play size={16} />
+                      </button>
+                      <button
+                        onClick={() => terminalSessionsAPI.downloadRecording(session.id)}
+                        className="rounded-lg p-2 text-slate-400 transition-all hover:bg-cyan-500/10 hover:text-cyan-400"
+                        title="Download recording"
+                      >
+                        <Download size={16} />
+                      </button>
+                    </div>
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      )}
+
+      <Modal
+        isOpen={!!replaying}
+        onClose={() => setReplaying(null)}
+        title={replaying ? `Session Replay // ${replaying.server_name} // ${sessionTarget(replaying)}` : 'Session Replay'}
+        className="max-w-5xl"
+      >
+        {replaying && <SessionPlayer sessionId={replaying.id} />}
+      </Modal>
+    </div>
+  );
+}
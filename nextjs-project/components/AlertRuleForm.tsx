@@ -35,6 +35,7 @@ const conditionTypes: Record<string, ConditionType> = {
   'uptime_low': { value: 'uptime_low', label: 'Low Uptime', needsThreshold: true },
   'cpu_high': { value: 'cpu_high', label: 'High CPU Usage', needsThreshold: true },
   'memory_high': { value: 'memory_high', label: 'High Memory Usage', needsThreshold: true },
+  'storage_high': { value: 'storage_high', label: 'Storage Nearly Full', needsThreshold: true },
 };
 
 const conditionGroups: Record<string, ConditionType[]> = {
@@ -46,6 +47,7 @@ const conditionGroups: Record<string, ConditionType[]> = {
   infrastructure: [
     conditionTypes['cpu_high'],
     conditionTypes['memory_high'],
+    conditionTypes['storage_high'],
   ],
 };
 
@@ -60,6 +62,7 @@ const thresholdUnits: Record<string, ThresholdUnit> = {
   'uptime_low': { label: '%', unit: '%', decimalPlaces: 1 },
   'cpu_high': { label: '%', unit: '%', decimalPlaces: 1 },
   'memory_high': { label: 'MB', unit: 'MB', decimalPlaces: 0 },
+  'storage_high': { label: '%', unit: '%', decimalPlaces: 1 },
 };
 
 export default function AlertRuleForm({ onSubmit, onCancel, initialData }: AlertRuleFormProps) {
@@ -0,0 +1,144 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { Compliance, BackupCoverageReport, backupsAPI } from '@/lib/api';
+import { cn, formatDate } from '@/lib/utils';
+import { Archive, RefreshCw, AlertTriangle, FileCheck } from 'lucide-react';
+
+interface BackupCoverageProps {
+  records: Compliance[];
+  onEvidenceAttached: () => void;
+}
+
+export default function BackupCoverage({ records, onEvidenceAttached }: BackupCoverageProps) {
+  const [days, setDays] = useState(7);
+  const [report, setReport] = useState<BackupCoverageReport | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [complianceId, setComplianceId] = useState<number | ''>('');
+  const [setStatus, setSetStatus] = useState(true);
+  const [attaching, setAttaching] = useState(false);
+
+  const loadReport = async () => {
+    setLoading(true);
+    try {
+      setReport(await backupsAPI.getReport(days));
+    } catch (error) {
+      console.error('Failed to load backup report:', error);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    loadReport();
+  }, [days]);
+
+  const handleAttach = async () => {
+    if (!complianceId) return;
+    setAttaching(true);
+    try {
+      setReport(await backupsAPI.attachEvidence({ compliance_id: complianceId, days, set_status: setStatus }));
+      onEvidenceAttached();
+    } catch (err: any) {
+      alert(`Failed to attach evidence: ${err.message}`);
+    } finally {
+      setAttaching(false);
+    }
+  };
+
+  const inputClasses = "bg-slate-950 border border-slate-800 rounded-lg px-3 py-2 text-slate-200 text-sm focus:border-cyan-500/50 outline-none";
+
+  return (
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <div className="flex items-center gap-2">
+          <Archive className="text-cyan-400" size={20} />
+          <h3 className="font-bold text-slate-100 uppercase tracking-wider text-sm">Guests Without a Recent Backup</h3>
+        </div>
+        <div className="flex items-center gap-2">
+          <label className="text-[10px] font-bold text-slate-500 uppercase">Max age (days)</label>
+          <input
+            type="number"
+            min={1}
+            max={365}
+            className={cn(inputClasses, "w-20")}
+            value={days}
+            onChange={e => setDays(parseInt(e.target.value) || 7)}
+          />
+          <button onClick={loadReport} className="p-2 text-slate-400 hover:text-cyan-400 transition-colors">
+            <RefreshCw size={18} className={cn(loading && "animate-spin")} />
+          </button>
+        </div>
+      </div>
+
+      {report && (
+        <>
+          <div className="grid grid-cols-3 gap-4">
+            <div className="rounded-xl border border-slate-800 bg-slate-900/30 p-4">
+              <p className="text-[10px] font-bold text-slate-500 uppercase">Guests</p>
+              <p className="text-2xl font-bold text-slate-100">{report.total_guests}</p>
+            </div>
+            <div className="rounded-xl border border-emerald-500/20 bg-emerald-500/5 p-4">
+              <p className="text-[10px] font-bold text-slate-500 uppercase">Covered</p>
+              <p className="text-2xl font-bold text-emerald-400">{report.covered_count}</p>
+            </div>
+            <div className="rounded-xl border border-rose-500/20 bg-rose-500/5 p-4">
+              <p className="text-[10px] font-bold text-slate-500 uppercase">Uncovered</p>
+              <p className="text-2xl font-bold text-rose-400">{report.uncovered.length}</p>
+            </div>
+          </div>
+
+          <div className="space-y-2">
+            {report.uncovered.map(guest => (
+              <div key={`${guest.server_id}-${guest.vmid}`} className="flex items-center justify-between rounded-lg border border-slate-800 px-3 py-2">
+                <div>
+                  <p className="text-sm text-slate-200">
+                    <span className="font-mono text-[10px] text-slate-500 mr-2">[{guest.vmid}]</span>
+                    {guest.name}
+                    <span className="ml-2 text-[10px] uppercase text-slate-500">{guest.type}</span>
+                  </p>
+                  <p className="text-[10px] text-slate-500">{guest.server_name} // {guest.node}</p>
+                </div>
+                <span className={cn("text-xs", guest.last_backup ? "text-amber-400" : "text-rose-400")}>
+                  {guest.last_backup ? `Last backup ${formatDate(guest.last_backup)} (${guest.age_days} days)` : 'Never backed up'}
+                </span>
+              </div>
+            ))}
+            {report.uncovered.length === 0 && (
+              <p className="py-4 text-center text-xs font-mono text-emerald-400">ALL_GUESTS_COVERED</p>
+            )}
+            {report.errors.map(e => (
+              <div key={e.server_id} className="flex items-center gap-2 rounded-lg border border-amber-500/20 bg-amber-500/5 px-3 py-2 text-xs text-amber-400">
+                <AlertTriangle size={14} /> {e.server_name}: {e.error}
+              </div>
+            ))}
+          </div>
+
+          <div className="flex flex-wrap items-center gap-3 border-t border-slate-800 pt-4">
+            <select
+              className={cn(inputClasses, "flex-1")}
+              value={complianceId}
+              onChange={e => setComplianceId(e.target.value ? parseInt(e.target.value) : '')}
+            >
+              <option value="">Attach as evidence to...</option>
+              {records.map(r => (
+                <option key={r.id} value={r.id}>{r.title}</option>
+              ))}
+            </select>
+            <label className="flex items-center gap-2 text-[10px] font-bold text-slate-500 uppercase">
+              <input type="checkbox" checked={setStatus} onChange={e => setSetStatus(e.target.checked)} />
+              Update status
+            </label>
+            <button
+              onClick={handleAttach}
+              disabled={!complianceId || attaching}
+              className="flex items-center gap-2 px-4 py-2 bg-cyan-500 text-slate-950 rounded-lg text-xs font-bold uppercase hover:bg-cyan-400 transition-all disabled:opacity-50"
+            >
+              <FileCheck size={14} /> Attach Evidence
+            </button>
+          </div>
+        </>
+      )}
+    </div>
+  );
+}
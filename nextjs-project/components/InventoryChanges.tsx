@@ -0,0 +1,106 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { infrastructureAPI, subscribeEvents, InventoryEvent, InventoryEventType } from '@/lib/api';
+import { cn } from '@/lib/utils';
+import { Plus, Minus, Maximize2, ArrowRightLeft, PencilLine, Settings, History } from 'lucide-react';
+
+const EVENTS: Record<InventoryEventType, { label: string; icon: typeof Plus; className: string }> = {
+  node_added: { label: 'Node added', icon: Plus, className: 'text-emerald-400' },
+  node_removed: { label: 'Node removed', icon: Minus, className: 'text-rose-400' },
+  node_resized: { label: 'Node hardware changed', icon: Maximize2, className: 'text-amber-400' },
+  guest_created: { label: 'Created', icon: Plus, className: 'text-emerald-400' },
+  guest_deleted: { label: 'Deleted', icon: Minus, className: 'text-rose-400' },
+  guest_resized: { label: 'Resized', icon: Maximize2, className: 'text-amber-400' },
+  guest_migrated: { label: 'Migrated', icon: ArrowRightLeft, className: 'text-cyan-400' },
+  guest_renamed: { label: 'Renamed', icon: PencilLine, className: 'text-indigo-400' },
+  guest_reconfigured: { label: 'Reconfigured', icon: Settings, className: 'text-slate-400' },
+};
+
+// Names what an inventory event is about.
+function eventTarget(event: InventoryEvent): string {
+  if (!event.vmid) return event.node;
+  const kind = event.guest_type === 'lxc' ? 'Container' : 'VM';
+  return `${kind} ${event.vmid}${event.name ? ` (${event.name})` : ''}`;
+}
+
+// Lists the latest changes to the nodes and guests of every Proxmox server,
+// refreshed as the inventory sync notices them.
+export default function InventoryChanges() {
+  const [events, setEvents] = useState<InventoryEvent[]>([]);
+  const [loading, setLoading] = useState(true);
+
+  useEffect(() => {
+    const load = async () => {
+      try {
+        setEvents(await infrastructureAPI.getInventoryEvents({ limit: 25 }));
+      } catch (error) {
+        console.error('Failed to load inventory changes:', error);
+      } finally {
+        setLoading(false);
+      }
+    };
+
+    load();
+    return subscribeEvents(['inventory.changed'], load);
+  }, []);
+
+  if (loading && events.length === 0) return null;
+
+  return (
+    <div className="space-y-4">
+      <div className="flex flex-col gap-1">
+        <h3 className="text-lg font-bold text-slate-100">Recent Changes</h3>
+        <p className="text-sm text-slate-400">Nodes and guests created, deleted, resized, migrated or renamed, as noticed by the inventory sync.</p>
+      </div>
+
+      {events.length === 0 ? (
+        <div className="flex flex-col items-center justify-center rounded-2xl border border-dashed border-slate-800 py-8 text-center">
+          <History size={32} className="mb-2 text-slate-700" />
+          <p className="text-sm text-slate-500">No changes recorded yet.</p>
+        </div>
+      ) : (
+        <div className="overflow-hidden rounded-2xl border border-slate-800 bg-slate-900/50">
+          {events.map(event => {
+            const meta = EVENTS[event.event] ?? { label: event.event, icon: Settings, className: 'text-slate-400' };
+            const Icon = meta.icon;
+            return (
+              <div
+                key={event.id}
+                className="grid grid-cols-1 items-center gap-3 border-b border-slate-800 px-6 py-3 last:border-b-0 md:grid-cols-[2fr_3fr_1fr]"
+              >
+                <div className="flex items-center gap-3">
+                  <Icon size={16} className={meta.className} />
+                  <div>
+                    <div className="font-bold text-slate-100">{eventTarget(event)}</div>
+                    <div className="flex items-center gap-1.5 text-[10px] uppercase text-slate-500">
+                      <span className={meta.className}>{meta.label}</span>
+                      <span className="text-slate-700">•</span>
+                      <span>{event.server_name}</span>
+                      {event.vmid > 0 && (
+                        <>
+                          <span className="text-slate-700">•</span>
+                          <span>{event.node}</span>
+                        </>
+                      )}
+                    </div>
+                  </div>
+                </div>
+
+                <div className="font-mono text-xs text-slate-400">
+                  {event.old_value && <span className={cn(event.new_value && "text-slate-500 line-through")}>{event.old_value}</span>}
+                  {event.old_value && event.new_value && <span className="mx-2 text-slate-600">→</span>}
+                  {event.new_value && <span className="text-slate-200">{event.new_value}</span>}
+                </div>
+
+                <div className="text-right font-mono text-[10px] text-slate-500">
+                  {new Date(event.occurred_at).toLocaleString()}
+                </div>
+              </div>
+            );
+          })}
+        </div>
+      )}
+    </div>
+  );
+}
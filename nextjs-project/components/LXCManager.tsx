@@ -3,7 +3,12 @@
 import React, { useEffect, useState } from 'react';
 import { Server, LXC, serversAPI } from '@/lib/api';
 import { cn } from '@/lib/utils';
-import { Play, Square, Trash2, Plus, RefreshCw, Box, Cpu, HardDrive } from 'lucide-react';
+import SnapshotManager from '@/components/SnapshotManager';
+import TaskLog from '@/components/TaskLog';
+import ShellTerminal from '@/components/ShellTerminal';
+import Modal from '@/components/Modal';
+import MetricHistory from '@/components/MetricHistory';
+import { Play, Square, Trash2, Plus, RefreshCw, Box, Cpu, HardDrive, Camera, Network, Terminal, LineChart } from 'lucide-react';
 
 interface LXCManagerProps {
   server: Server;
@@ -14,6 +19,10 @@ export default function LXCManager({ server }: LXCManagerProps) {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
   const [isCreating, setIsCreating] = useState(false);
+  const [snapshotting, setSnapshotting] = useState<number | null>(null);
+  const [consoleVmid, setConsoleVmid] = useState<number | null>(null);
+  const [historyVmid, setHistoryVmid] = useState<number | null>(null);
+  const [task, setTask] = useState<{ id: number; description: string } | null>(null);
   const [newContainer, setNewContainer] = useState({
     vmid: 100,
     ostemplate: 'local:vztmpl/ubuntu-22.04-standard_22.04-1_amd64.tar.zst',
@@ -44,7 +53,8 @@ export default function LXCManager({ server }: LXCManagerProps) {
 
   const handleAction = async (vmid: number, action: 'start' | 'stop' | 'remove') => {
     try {
-      await serversAPI.manageLXC(server.id, vmid, action);
+      const result = await serversAPI.manageLXC(server.id, vmid, action);
+      if (result.task_id) setTask({ id: result.task_id, description: `Container ${vmid}: ${action}` });
       await fetchLXCs();
     } catch (err: any) {
       alert(`Action failed: ${err.message}`);
@@ -54,7 +64,7 @@ export default function LXCManager({ server }: LXCManagerProps) {
   const handleCreate = async (e: React.FormEvent) => {
     e.preventDefault();
     try {
-      await serversAPI.createLXC(server.id, {
+      const result = await serversAPI.createLXC(server.id, {
         vmid: newContainer.vmid,
         ostemplate: newContainer.ostemplate,
         storage: newContainer.storage,
@@ -67,6 +77,7 @@ export default function LXCManager({ server }: LXCManagerProps) {
         }
       });
       setIsCreating(false);
+      if (result.task_id) setTask({ id: result.task_id, description: `Container ${newContainer.vmid}: create` });
       await fetchLXCs();
     } catch (err: any) {
       alert(`Creation failed: ${err.message}`);
@@ -140,6 +151,27 @@ export default function LXCManager({ server }: LXCManagerProps) {
         </form>
       )}
 
+      {task && (
+        <TaskLog taskId={task.id} description={task.description} onFinished={fetchLXCs} onClose={() => setTask(null)} />
+      )}
+
+      {snapshotting !== null && (
+        <SnapshotManager server={server} guest="lxcs" vmid={snapshotting} onClose={() => setSnapshotting(null)} />
+      )}
+
+      {consoleVmid !== null && (
+        <ShellTerminal server={server} vmid={consoleVmid} onClose={() => setConsoleVmid(null)} />
+      )}
+
+      <Modal
+        isOpen={historyVmid !== null}
+        onClose={() => setHistoryVmid(null)}
+        title={`Container History // ${historyVmid ?? ''}`}
+        className="max-w-5xl"
+      >
+        {historyVmid !== null && <MetricHistory serverId={server.id} vmid={historyVmid} />}
+      </Modal>
+
       <div className="grid grid-cols-1 gap-4">
         {lxcs.map(lxc => (
           <div key={lxc.vmid} className="bg-slate-900/30 border border-slate-800 rounded-xl p-4 flex items-center justify-between group hover:border-slate-700 transition-all">
@@ -156,6 +188,9 @@ export default function LXCManager({ server }: LXCManagerProps) {
                   <h4 className="font-bold text-slate-100">{lxc.name}</h4>
                 </div>
                 <div className="flex items-center gap-4 mt-1">
+                  <div className="flex items-center gap-1 text-[10px] text-slate-500">
+                    <Network size={10} /> {lxc.node}
+                  </div>
                   <div className="flex items-center gap-1 text-[10px] text-slate-500">
                     <Cpu size={10} /> {lxc.cpus} CORES
                   </div>
@@ -196,6 +231,29 @@ export default function LXCManager({ server }: LXCManagerProps) {
                 </button>
               )}
               
+              {lxc.status === 'running' && (
+                <button
+                  onClick={() => setConsoleVmid(lxc.vmid)}
+                  className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                  title="Console"
+                >
+                  <Terminal size={18} />
+                </button>
+              )}
+              <button 
+                onClick={() => setSnapshotting(lxc.vmid)}
+                className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                title="Snapshots"
+              >
+                <Camera size={18} />
+              </button>
+              <button
+                onClick={() => setHistoryVmid(lxc.vmid)}
+                className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                title="History"
+              >
+                <LineChart size={18} />
+              </button>
               <button 
                 onClick={() => handleAction(lxc.vmid, 'remove')}
                 className="p-2 text-slate-600 hover:text-rose-500 hover:bg-rose-500/10 rounded-lg transition-all"
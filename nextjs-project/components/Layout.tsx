@@ -21,7 +21,8 @@ import {
   BarChart3,
   LogOut,
   User as UserIcon,
-  ChevronDown
+  ChevronDown,
+  Terminal
 } from 'lucide-react';
 import { motion, AnimatePresence } from 'framer-motion';
 import { cn } from '@/lib/utils';
@@ -101,6 +102,7 @@ export default function AppLayout({ children }: { children: React.ReactNode }) {
     { href: '/infrastructure', icon: HardDrive, label: 'Infrastructure' },
     { href: '/monitoring', icon: Activity, label: 'Monitoring' },
     { href: '/alerts', icon: AlertTriangle, label: 'Alerts' },
+    ...(user?.role === 'admin' ? [{ href: '/sessions', icon: Terminal, label: 'Sessions' }] : []),
   ];
 
   // Show login page without sidebar
@@ -0,0 +1,223 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { infrastructureAPI, MetricSeries } from '@/lib/api';
+import { LineChart, Line, XAxis, YAxis, Tooltip, ResponsiveContainer } from 'recharts';
+import { cn, formatBytes } from '@/lib/utils';
+import { Activity, AlertCircle } from 'lucide-react';
+
+interface MetricHistoryProps {
+  serverId: number;
+  node?: string;
+  // vmid shows the guest's history instead of the node's.
+  vmid?: number;
+}
+
+const RANGES = [
+  { label: '1H', hours: 1 },
+  { label: '6H', hours: 6 },
+  { label: '24H', hours: 24 },
+  { label: '7D', hours: 24 * 7 },
+  { label: '30D', hours: 24 * 30 },
+];
+
+interface ChartLine {
+  key: string;
+  name: string;
+  color: string;
+}
+
+const formatRate = (value: number) => `${formatBytes(Math.max(value, 0))}/s`;
+const formatPercent = (value: number) => `${value.toFixed(1)}%`;
+
+function MetricChart({ title, data, lines, format, spansDays }: {
+  title: string;
+  data: Record<string, number | string | null>[];
+  lines: ChartLine[];
+  format: (value: number) => string;
+  spansDays: boolean;
+}) {
+  return (
+    <div className="rounded-xl border border-slate-800 bg-slate-900/50 p-4">
+      <h4 className="mb-3 text-[10px] font-bold uppercase tracking-widest text-slate-500">{title}</h4>
+      <div className="h-40">
+        <ResponsiveContainer width="100%" height="100%">
+          <LineChart data={data}>
+            <XAxis
+              dataKey="time"
+              stroke="#64748b"
+              fontSize={10}
+              tickLine={false}
+              axisLine={false}
+              minTickGap={40}
+              tickFormatter={(time: string) => {
+                const date = new Date(time);
+                return spansDays ? date.toLocaleDateString() : date.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+              }}
+            />
+            <YAxis
+              stroke="#64748b"
+              fontSize={10}
+              tickLine={false}
+              axisLine={false}
+              width={70}
+              tickFormatter={format}
+            />
+            <Tooltip
+              contentStyle={{ backgroundColor: '#0f172a', borderColor: '#1e293b', borderRadius: '0.5rem' }}
+              itemStyle={{ color: '#e2e8f0' }}
+              labelStyle={{ color: '#94a3b8' }}
+              labelFormatter={(time) => new Date(String(time)).toLocaleString()}
+              formatter={(value) => format(Number(value))}
+            />
+            {lines.map(line => (
+              <Line
+                key={line.key}
+                type="monotone"
+                dataKey={line.key}
+                name={line.name}
+                stroke={line.color}
+                strokeWidth={2}
+                dot={false}
+                connectNulls={false}
+                isAnimationActive={false}
+              />
+            ))}
+          </LineChart>
+        </ResponsiveContainer>
+      </div>
+    </div>
+  );
+}
+
+// Charts the collected CPU, memory, network and disk history of a node or
+// guest.
+export default function MetricHistory({ serverId, node, vmid }: MetricHistoryProps) {
+  const [hours, setHours] = useState(24);
+  const [series, setSeries] = useState<MetricSeries | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+
+    async function load() {
+      setLoading(true);
+      try {
+        const from = new Date(Date.now() - hours * 3600 * 1000).toISOString();
+        const data = await infrastructureAPI.getMetrics(serverId, { node, vmid }, { from });
+        if (cancelled) return;
+        setSeries(data);
+        setError(null);
+      } catch (err) {
+        if (cancelled) return;
+        setError(err instanceof Error ? err.message : 'Failed to load metrics');
+      } finally {
+        if (!cancelled) setLoading(false);
+      }
+    }
+
+    load();
+    return () => {
+      cancelled = true;
+    };
+  }, [serverId, node, vmid, hours]);
+
+  const data = (series?.samples ?? []).map(sample => ({
+    time: sample.sampled_at,
+    cpu: sample.cpu === null ? null : sample.cpu * 100,
+    mem: sample.mem === null || !sample.maxmem ? null : (sample.mem / sample.maxmem) * 100,
+    netin: sample.netin,
+    netout: sample.netout,
+    diskread: sample.diskread,
+    diskwrite: sample.diskwrite,
+    iowait: sample.iowait === null ? null : sample.iowait * 100,
+  }));
+  const spansDays = hours > 24;
+
+  return (
+    <div className="space-y-4">
+      <div className="flex items-center justify-between">
+        <span className="font-mono text-[10px] text-slate-500">
+          {series ? `${series.timeframe.toUpperCase()} RRD // ${series.step}s STEP // ${series.samples.length} SAMPLES` : ''}
+        </span>
+        <div className="flex items-center gap-1">
+          {RANGES.map(range => (
+            <button
+              key={range.label}
+              onClick={() => setHours(range.hours)}
+              className={cn(
+                "rounded px-2 py-1 font-mono text-[10px]",
+                hours === range.hours ? "bg-cyan-500/20 text-cyan-400" : "text-slate-500 hover:text-slate-300"
+              )}
+            >
+              {range.label}
+            </button>
+          ))}
+        </div>
+      </div>
+
+      {error ? (
+        <div className="flex flex-col items-center justify-center gap-3 py-12 text-center">
+          <AlertCircle size={32} className="text-rose-400" />
+          <p className="font-mono text-sm text-slate-400">{error}</p>
+        </div>
+      ) : loading && !series ? (
+        <div className="flex h-64 items-center justify-center">
+          <Activity className="animate-spin text-cyan-400" size={32} />
+        </div>
+      ) : data.length === 0 ? (
+        <div className="rounded-xl border border-dashed border-slate-800 py-12 text-center text-sm text-slate-500">
+          No history collected for this range yet.
+        </div>
+      ) : (
+        <div className={cn("grid grid-cols-1 gap-4 md:grid-cols-2", loading && "opacity-60")}>
+          <MetricChart
+            title="CPU"
+            data={data}
+            lines={[{ key: 'cpu', name: 'CPU', color: '#06b6d4' }]}
+            format={formatPercent}
+            spansDays={spansDays}
+          />
+          <MetricChart
+            title="Memory"
+            data={data}
+            lines={[{ key: 'mem', name: 'Memory', color: '#6366f1' }]}
+            format={formatPercent}
+            spansDays={spansDays}
+          />
+          <MetricChart
+            title="Network"
+            data={data}
+            lines={[
+              { key: 'netin', name: 'In', color: '#10b981' },
+              { key: 'netout', name: 'Out', color: '#f59e0b' },
+            ]}
+            format={formatRate}
+            spansDays={spansDays}
+          />
+          {vmid ? (
+            <MetricChart
+              title="Disk IO"
+              data={data}
+              lines={[
+                { key: 'diskread', name: 'Read', color: '#10b981' },
+                { key: 'diskwrite', name: 'Write', color: '#f43f5e' },
+              ]}
+              format={formatRate}
+              spansDays={spansDays}
+            />
+          ) : (
+            <MetricChart
+              title="IO Wait"
+              data={data}
+              lines={[{ key: 'iowait', name: 'IO Wait', color: '#f43f5e' }]}
+              format={formatPercent}
+              spansDays={spansDays}
+            />
+          )}
+        </div>
+      )}
+    </div>
+  );
+}
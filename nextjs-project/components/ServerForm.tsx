@@ -121,7 +121,7 @@ export default function ServerForm({ initialData, onSubmit, isLoading }: ServerF
                 <input
                   name="password"
                   type="password"
-                  required
+                  required={!formData.api_token}
                   className={cn(inputClasses, "pl-10")}
                   placeholder="••••••••"
                   value={formData.password || ''}
@@ -129,6 +129,21 @@ export default function ServerForm({ initialData, onSubmit, isLoading }: ServerF
                 />
               </div>
             </div>
+            <div className="space-y-1 sm:col-span-2">
+              <label className={labelClasses}>API Token</label>
+              <div className="relative">
+                <Lock className="absolute left-3 top-3 text-slate-500" size={18} />
+                <input
+                  name="api_token"
+                  type="password"
+                  className={cn(inputClasses, "pl-10")}
+                  placeholder="user@realm!tokenid=secret"
+                  value={formData.api_token || ''}
+                  onChange={handleChange}
+                />
+              </div>
+              <p className="text-xs text-slate-500">Used instead of the password when set.</p>
+            </div>
             <div className="space-y-1">
               <label className={labelClasses}>Realm</label>
               <input
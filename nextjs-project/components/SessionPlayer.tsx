@@ -0,0 +1,218 @@
+'use client';
+
+import React, { useEffect, useRef, useState } from 'react';
+import { terminalSessionsAPI } from '@/lib/api';
+import { cn } from '@/lib/utils';
+import { Play, Pause, RotateCcw, Download, AlertCircle, RefreshCw } from 'lucide-react';
+import type { Terminal } from '@xterm/xterm';
+import '@xterm/xterm/css/xterm.css';
+
+interface SessionPlayerProps {
+  sessionId: number;
+}
+
+// A recorded event: seconds since the start, "o" for output or "r" for a
+// resize to "COLSxROWS", and its data.
+type CastEvent = [number, string, string];
+
+// Pauses longer than this are shortened on replay.
+const IDLE_LIMIT = 2;
+const SPEEDS = [1, 2, 4, 8];
+
+// Parses an asciicast v2 recording, shortening idle pauses.
+function parseCast(text: string): { width: number; height: number; events: CastEvent[] } {
+  const [headerLine, ...lines] = text.split('\n').filter(line => line.trim() !== '');
+  const header = JSON.parse(headerLine);
+  const events: CastEvent[] = [];
+  let last = 0;
+  let shift = 0;
+  for (const line of lines) {
+    const [time, code, data] = JSON.parse(line) as CastEvent;
+    shift += Math.max(time - last - IDLE_LIMIT, 0);
+    last = time;
+    events.push([time - shift, code, data]);
+  }
+  return { width: header.width, height: header.height, events };
+}
+
+const formatTime = (seconds: number) =>
+  `${Math.floor(seconds / 60)}:${Math.floor(seconds % 60).toString().padStart(2, '0')}`;
+
+// Replays a terminal session recording.
+export default function SessionPlayer({ sessionId }: SessionPlayerProps) {
+  const containerRef = useRef<HTMLDivElement>(null);
+  const termRef = useRef<Terminal | null>(null);
+  const castRef = useRef<ReturnType<typeof parseCast> | null>(null);
+  const indexRef = useRef(0);
+  const timerRef = useRef<ReturnType<typeof setTimeout> | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+  const [playing, setPlaying] = useState(false);
+  const [position, setPosition] = useState(0);
+  const [speed, setSpeed] = useState(1);
+
+  useEffect(() => {
+    let disposed = false;
+
+    async function load() {
+      setLoading(true);
+      setError(null);
+      try {
+        const [{ Terminal }, text] = await Promise.all([
+          import('@xterm/xterm'),
+          terminalSessionsAPI.getRecording(sessionId),
+        ]);
+        if (disposed || !containerRef.current) return;
+
+        const cast = parseCast(text);
+        castRef.current = cast;
+        indexRef.current = 0;
+        const term = new Terminal({
+          cols: cast.width,
+          rows: cast.height,
+          disableStdin: true,
+          fontFamily: 'ui-monospace, SFMono-Regular, Menlo, monospace',
+          fontSize: 13,
+          theme: { background: '#0a0a0f' },
+        });
+        term.open(containerRef.current);
+        termRef.current = term;
+        setPosition(0);
+        setPlaying(true);
+      } catch (err) {
+        if (disposed) return;
+        setError(err instanceof Error ? err.message : 'Failed to load recording');
+      } finally {
+        if (!disposed) setLoading(false);
+      }
+    }
+
+    load();
+    return () => {
+      disposed = true;
+      if (timerRef.current) clearTimeout(timerRef.current);
+      termRef.current?.dispose();
+      termRef.current = null;
+    };
+  }, [sessionId]);
+
+  // Plays events from the current one, each after its delay from the last.
+  useEffect(() => {
+    const cast = castRef.current;
+    const term = termRef.current;
+    if (!playing || !cast || !term) return;
+
+    const next = () => {
+      const event = cast.events[indexRef.current];
+      if (!event) {
+        setPlaying(false);
+        return;
+      }
+      const previous = indexRef.current > 0 ? cast.events[indexRef.current - 1][0] : 0;
+      timerRef.current = setTimeout(() => {
+        const [time, code, data] = event;
+        if (code === 'o') {
+          term.write(data);
+        } else if (code === 'r') {
+          const [cols, rows] = data.split('x').map(Number);
+          if (cols > 0 && rows > 0) term.resize(cols, rows);
+        }
+        indexRef.current++;
+        setPosition(time);
+        next();
+      }, ((event[0] - previous) * 1000) / speed);
+    };
+
+    next();
+    return () => {
+      if (timerRef.current) clearTimeout(timerRef.current);
+    };
+  }, [playing, speed]);
+
+  const restart = () => {
+    const cast = castRef.current;
+    if (!cast || !termRef.current) return;
+    if (timerRef.current) clearTimeout(timerRef.current);
+    termRef.current.reset();
+    termRef.current.resize(cast.width, cast.height);
+    indexRef.current = 0;
+    setPosition(0);
+    setPlaying(false);
+    setTimeout(() => setPlaying(true));
+  };
+
+  const events = castRef.current?.events ?? [];
+  const total = events.length > 0 ? events[events.length - 1][0] : 0;
+  const finished = !!castRef.current && indexRef.current >= castRef.current.events.length;
+
+  return (
+    <div className="flex flex-col gap-3">
+      <div className="relative h-[480px] overflow-auto rounded-xl border border-slate-800 bg-[#0a0a0f] p-2">
+        <div ref={containerRef} />
+
+        {loading && (
+          <div className="absolute inset-0 flex items-center justify-center">
+            <RefreshCw className="animate-spin text-cyan-400" size={28} />
+          </div>
+        )}
+
+        {error && (
+          <div className="absolute inset-0 flex flex-col items-center justify-center gap-3 text-center">
+            <AlertCircle size={32} className="text-rose-400" />
+            <p className="font-mono text-sm text-slate-400">{error}</p>
+          </div>
+        )}
+      </div>
+
+      <div className="flex items-center gap-3">
+        <button
+          onClick={() => (finished ? restart() : setPlaying(p => !p))}
+          disabled={loading || !!error}
+          className="rounded-lg p-2 text-slate-400 transition-all hover:bg-cyan-500/10 hover:text-cyan-400 disabled:opacity-50"
+          title={playing ? 'Pause' : 'Play'}
+        >
+          {playing ? <Pause size={18} /> : <Play size={18} />}
+        </button>
+        <button
+          onClick={restart}
+          disabled={loading || !!error}
+          className="rounded-lg p-2 text-slate-400 transition-all hover:bg-cyan-500/10 hover:text-cyan-400 disabled:opacity-50"
+          title="Restart"
+        >
+          <RotateCcw size={18} />
+        </button>
+
+        <div className="h-1.5 flex-1 overflow-hidden rounded-full bg-slate-800">
+          <div
+            className="h-full rounded-full bg-cyan-500 transition-all"
+            style={{ width: `${total > 0 ? Math.min((position / total) * 100, 100) : 0}%` }}
+          />
+        </div>
+        <span className="font-mono text-xs text-slate-500">{formatTime(position)} / {formatTime(total)}</span>
+
+        <div className="flex items-center gap-1">
+          {SPEEDS.map(s => (
+            <button
+              key={s}
+              onClick={() => setSpeed(s)}
+              className={cn(
+                "rounded px-2 py-1 font-mono text-[10px]",
+                speed === s ? "bg-cyan-500/20 text-cyan-400" : "text-slate-500 hover:text-slate-300"
+              )}
+            >
+              {s}x
+            </button>
+          ))}
+        </div>
+
+        <button
+          onClick={() => terminalSessionsAPI.downloadRecording(sessionId)}
+          className="rounded-lg p-2 text-slate-400 transition-all hover:bg-cyan-500/10 hover:text-cyan-400"
+          title="Download recording"
+        >
+          <Download size={18} />
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -1,59 +1,148 @@
 'use client';
 
 import React, { useEffect, useRef, useState } from 'react';
-import { Server, serversAPI } from '@/lib/api';
-import { cn } from '@/lib/utils';
-import { Terminal as TerminalIcon, AlertCircle, RefreshCw, ExternalLink } from 'lucide-react';
+import { Server, serversAPI, openTerminal } from '@/lib/api';
+import { Terminal as TerminalIcon, AlertCircle, RefreshCw, X } from 'lucide-react';
+import '@xterm/xterm/css/xterm.css';
 
 interface ShellTerminalProps {
   server: Server;
+  node?: string;
+  // vmid opens the guest's console instead of a shell on the node.
+  vmid?: number;
+  onClose?: () => void;
 }
 
-export default function ShellTerminal({ server }: ShellTerminalProps) {
+// Proxmox termproxy messages: input is "0:<bytes>:<data>", a resize is
+// "1:<cols>:<rows>:" and "2" keeps the session alive.
+const encoder = new TextEncoder();
+const inputMessage = (data: string) => `0:${encoder.encode(data).length}:${data}`;
+const resizeMessage = (cols: number, rows: number) => `1:${cols}:${rows}:`;
+const PING_INTERVAL = 30000;
+
+export default function ShellTerminal({ server, node, vmid, onClose }: ShellTerminalProps) {
+  const containerRef = useRef<HTMLDivElement>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [connectedNode, setConnectedNode] = useState<string | null>(null);
+  const [attempt, setAttempt] = useState(0);
 
   useEffect(() => {
-    async function checkTerminalAccess() {
+    let disposed = false;
+    let cleanup = () => {};
+
+    async function connect() {
+      setLoading(true);
+      setError(null);
+
       try {
-        const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api/v1'}/servers/${server.id}/shell`);
-        if (!response.ok) {
-          throw new Error('Failed to check terminal access');
-        }
-        const data = await response.json();
-        
-        if (data.type === 'redirect') {
-          setError(null);
-        } else if (data.error) {
-          setError(data.error);
-        }
+        const [{ Terminal }, { FitAddon }, ticket] = await Promise.all([
+          import('@xterm/xterm'),
+          import('@xterm/addon-fit'),
+          vmid ? serversAPI.createConsoleTicket(server.id, vmid) : serversAPI.createShellTicket(server.id, node),
+        ]);
+        if (disposed || !containerRef.current) return;
+
+        const term = new Terminal({
+          cursorBlink: true,
+          fontFamily: 'ui-monospace, SFMono-Regular, Menlo, monospace',
+          fontSize: 13,
+          theme: { background: '#0a0a0f' },
+        });
+        const fit = new FitAddon();
+        term.loadAddon(fit);
+        term.open(containerRef.current);
+        fit.fit();
+        setConnectedNode(ticket.node);
+
+        const socket = openTerminal(server.id, ticket, vmid);
+        const send = (message: string) => {
+          if (socket.readyState === WebSocket.OPEN) socket.send(message);
+        };
+
+        let ready = false;
+        socket.onmessage = (e: MessageEvent) => {
+          const data = typeof e.data === 'string' ? e.data : new Uint8Array(e.data);
+          // Proxmox acknowledges the session with "OK" before any output.
+          if (!ready) {
+            ready = true;
+            const text = typeof data === 'string' ? data : new TextDecoder().decode(data);
+            if (text.startsWith('OK')) {
+              setLoading(false);
+              send(resizeMessage(term.cols, term.rows));
+              term.focus();
+              const rest = text.slice(2);
+              if (rest) term.write(rest);
+              return;
+            }
+            setLoading(false);
+          }
+          term.write(data);
+        };
+        socket.onerror = () => {
+          if (!ready) setError(`Failed to connect to the ${vmid ? 'console' : 'node shell'}`);
+        };
+        socket.onclose = () => {
+          setLoading(false);
+          if (ready) term.write('\r\n\x1b[2m// Session closed\x1b[0m\r\n');
+          else setError(prev => prev ?? `The ${vmid ? 'console' : 'shell'} session could not be opened`);
+        };
+
+        const input = term.onData(data => send(inputMessage(data)));
+        const resize = term.onResize(({ cols, rows }) => send(resizeMessage(cols, rows)));
+        const observer = new ResizeObserver(() => fit.fit());
+        observer.observe(containerRef.current);
+        const ping = setInterval(() => send('2'), PING_INTERVAL);
+
+        cleanup = () => {
+          clearInterval(ping);
+          observer.disconnect();
+          input.dispose();
+          resize.dispose();
+          socket.onclose = null;
+          socket.close();
+          term.dispose();
+        };
       } catch (err) {
-        console.error('Failed to check terminal access:', err);
-        setError('Failed to check terminal access');
-      } finally {
+        if (disposed) return;
+        console.error('Failed to open shell:', err);
+        setError(err instanceof Error ? err.message : 'Failed to open shell');
         setLoading(false);
       }
     }
 
-    checkTerminalAccess();
-  }, [server.id]);
+    connect();
+    return () => {
+      disposed = true;
+      cleanup();
+    };
+  }, [server.id, node, vmid, attempt]);
 
   return (
     <div className="flex flex-col h-[600px] bg-[#0a0a0f] border border-border cyber-chamfer relative overflow-hidden group">
       <div className="flex items-center justify-between px-4 py-3 border-b border-primary/30 bg-muted/20">
         <div className="flex items-center gap-3">
           <div className="w-2 h-2 bg-primary rounded-full animate-pulse shadow-[0_0_5px_#00ff88]" />
-          <span className="font-accent font-bold text-xs tracking-[0.2em] text-primary">PROXMOX_SHELL // {server.name}</span>
+          <span className="font-accent font-bold text-xs tracking-[0.2em] text-primary">
+            {vmid ? `PROXMOX_CONSOLE // ${server.name} // ${vmid}` : `PROXMOX_SHELL // ${server.name}`}
+          </span>
         </div>
         <div className="flex items-center gap-4 text-[10px] font-mono text-muted-foreground">
-          <span className="flex items-center gap-1"><TerminalIcon size={12} /> NOVNC</span>
+          <span className="flex items-center gap-1"><TerminalIcon size={12} /> XTERM</span>
           <span className="opacity-50">|</span>
-          <span className="text-primary/70">{server.ip_address}:{server.port}</span>
+          <span className="text-primary/70">{connectedNode ?? node ?? server.node}</span>
+          {onClose && (
+            <button onClick={onClose} className="text-slate-500 hover:text-slate-300">
+              <X size={14} />
+            </button>
+          )}
         </div>
       </div>
 
       <div className="flex-1 relative overflow-hidden">
-        {loading && (
+        <div ref={containerRef} className="h-full w-full p-2" />
+
+        {loading && !error && (
           <div className="absolute inset-0 flex flex-col items-center justify-center bg-[#0a0a0f]/50 gap-4">
             <RefreshCw className="animate-spin text-primary" size={32} />
             <div className="text-center space-y-2">
@@ -68,40 +157,14 @@ export default function ShellTerminal({ server }: ShellTerminalProps) {
             <AlertCircle size={48} className="text-destructive mb-4" />
             <h4 className="font-heading text-xl text-destructive tracking-widest mb-2 uppercase">Connection Failure</h4>
             <p className="font-mono text-sm text-muted-foreground mb-6 max-w-md">{error}</p>
-            <button 
-              onClick={() => window.location.reload()}
+            <button
+              onClick={() => setAttempt(a => a + 1)}
               className="px-6 py-2 bg-destructive/10 border border-destructive text-destructive font-accent uppercase tracking-widest hover:bg-destructive hover:text-foreground transition-all cyber-chamfer-sm"
             >
               Retry Connection
             </button>
           </div>
         )}
-
-        {!loading && !error && (
-          <div className="h-full flex items-center justify-center bg-[#0a0a0f]/50 p-8">
-            <div className="text-center space-y-6 max-w-lg">
-              <ExternalLink className="w-16 h-16 text-cyan-400 mx-auto opacity-50" size={64} />
-              <h3 className="text-2xl font-bold text-slate-100">Access Proxmox Web Interface</h3>
-              <p className="text-sm text-slate-400">
-                The terminal can be accessed directly through Proxmox's built-in web interface
-              </p>
-              <div className="flex flex-col gap-3">
-                <button
-                  onClick={() => window.open(`https://${server.ip_address}:${server.port}`, '_blank')}
-                  className="flex items-center justify-center gap-2 px-8 py-3 bg-cyan-500/10 border border-cyan-500/50 text-cyan-400 font-bold uppercase tracking-widest hover:bg-cyan-500/20 transition-all cyber-chamfer"
-                >
-                  <ExternalLink size={20} />
-                  Open Proxmox Web UI
-                </button>
-                <div className="text-[10px] font-mono text-slate-500 space-y-1">
-                  <p>Server: {server.ip_address}:{server.port}</p>
-                  <p>Node: {server.node || 'pve'}</p>
-                  <p>Username: {server.username || 'root@pam'}</p>
-                </div>
-              </div>
-            </div>
-          </div>
-        )}
       </div>
 
       <div className="absolute inset-0 pointer-events-none opacity-5 bg-[linear-gradient(rgba(0,255,136,0.1)_1px,transparent_1px),linear-gradient(90deg,rgba(0,255,136,0.1)_1px,transparent_1px)] bg-[length:20px_20px]" />
@@ -0,0 +1,154 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { Server, GuestType, Snapshot, serversAPI } from '@/lib/api';
+import { cn } from '@/lib/utils';
+import { Camera, History, Trash2, Plus, RefreshCw, X } from 'lucide-react';
+
+interface SnapshotManagerProps {
+  server: Server;
+  guest: GuestType;
+  vmid: number;
+  onClose: () => void;
+}
+
+export default function SnapshotManager({ server, guest, vmid, onClose }: SnapshotManagerProps) {
+  const [snapshots, setSnapshots] = useState<Snapshot[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [newSnapshot, setNewSnapshot] = useState({ name: '', description: '', vmstate: false });
+
+  const fetchSnapshots = async () => {
+    setLoading(true);
+    try {
+      const data = await serversAPI.getSnapshots(server.id, guest, vmid);
+      setSnapshots(data || []);
+    } catch (err: any) {
+      console.error('Failed to fetch snapshots:', err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    fetchSnapshots();
+  }, [server.id, guest, vmid]);
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    try {
+      await serversAPI.createSnapshot(server.id, guest, vmid, newSnapshot);
+      setNewSnapshot({ name: '', description: '', vmstate: false });
+      await fetchSnapshots();
+    } catch (err: any) {
+      alert(`Snapshot failed: ${err.message}`);
+    }
+  };
+
+  const handleRollback = async (name: string) => {
+    if (!confirm(`Roll back ${vmid} to snapshot "${name}"? Changes made since will be lost.`)) return;
+    try {
+      await serversAPI.rollbackSnapshot(server.id, guest, vmid, name);
+    } catch (err: any) {
+      alert(`Rollback failed: ${err.message}`);
+    }
+  };
+
+  const handleDelete = async (name: string) => {
+    if (!confirm(`Delete snapshot "${name}"?`)) return;
+    try {
+      await serversAPI.deleteSnapshot(server.id, guest, vmid, name);
+      await fetchSnapshots();
+    } catch (err: any) {
+      alert(`Delete failed: ${err.message}`);
+    }
+  };
+
+  const inputClasses = "w-full bg-slate-950 border border-slate-800 rounded-lg px-3 py-2 text-slate-200 text-sm focus:border-cyan-500/50 outline-none";
+
+  return (
+    <div className="bg-slate-900/50 border border-slate-800 rounded-xl p-6 space-y-4">
+      <div className="flex items-center justify-between">
+        <h4 className="flex items-center gap-2 text-xs font-bold text-slate-400 uppercase tracking-widest">
+          <Camera size={14} /> Snapshots // {vmid}
+        </h4>
+        <div className="flex gap-1">
+          <button onClick={fetchSnapshots} className="p-1.5 text-slate-400 hover:text-cyan-400 transition-colors">
+            <RefreshCw size={14} className={cn(loading && "animate-spin")} />
+          </button>
+          <button onClick={onClose} className="p-1.5 text-slate-400 hover:text-slate-200 transition-colors">
+            <X size={14} />
+          </button>
+        </div>
+      </div>
+
+      <form onSubmit={handleCreate} className="flex items-end gap-3">
+        <div className="space-y-1">
+          <label className="text-[10px] font-bold text-slate-500 uppercase">Name</label>
+          <input
+            required
+            className={inputClasses}
+            placeholder="pre-upgrade"
+            value={newSnapshot.name}
+            onChange={e => setNewSnapshot({ ...newSnapshot, name: e.target.value })}
+          />
+        </div>
+        <div className="flex-1 space-y-1">
+          <label className="text-[10px] font-bold text-slate-500 uppercase">Description</label>
+          <input
+            className={inputClasses}
+            placeholder="Before kernel upgrade"
+            value={newSnapshot.description}
+            onChange={e => setNewSnapshot({ ...newSnapshot, description: e.target.value })}
+          />
+        </div>
+        {guest === 'vms' && (
+          <label className="flex items-center gap-2 pb-2 text-[10px] font-bold text-slate-500 uppercase" title="Include RAM so the VM resumes running after a rollback">
+            <input
+              type="checkbox"
+              checked={newSnapshot.vmstate}
+              onChange={e => setNewSnapshot({ ...newSnapshot, vmstate: e.target.checked })}
+            />
+            RAM
+          </label>
+        )}
+        <button type="submit" className="flex items-center gap-1 px-3 py-2 bg-cyan-500 text-slate-950 rounded-lg text-xs font-bold uppercase hover:bg-cyan-400 transition-all">
+          <Plus size={14} /> Take
+        </button>
+      </form>
+
+      <div className="space-y-2">
+        {snapshots.map(snapshot => (
+          <div key={snapshot.name} className="flex items-center justify-between rounded-lg border border-slate-800 px-3 py-2">
+            <div>
+              <p className="font-mono text-sm text-slate-200">{snapshot.name}</p>
+              <p className="text-[10px] text-slate-500">
+                {snapshot.snaptime ? new Date(snapshot.snaptime * 1000).toLocaleString() : ''}
+                {snapshot.description ? ` — ${snapshot.description}` : ''}
+                {snapshot.vmstate ? ' — with RAM' : ''}
+              </p>
+            </div>
+            <div className="flex gap-1">
+              <button
+                onClick={() => handleRollback(snapshot.name)}
+                className="p-1.5 text-slate-400 hover:text-amber-400 hover:bg-amber-500/10 rounded-lg transition-all"
+                title="Roll Back"
+              >
+                <History size={16} />
+              </button>
+              <button
+                onClick={() => handleDelete(snapshot.name)}
+                className="p-1.5 text-slate-600 hover:text-rose-500 hover:bg-rose-500/10 rounded-lg transition-all"
+                title="Delete Snapshot"
+              >
+                <Trash2 size={16} />
+              </button>
+            </div>
+          </div>
+        ))}
+        {snapshots.length === 0 && !loading && (
+          <p className="py-4 text-center text-xs font-mono text-slate-500">NO_SNAPSHOTS</p>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,118 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { infrastructureAPI, StorageOverview, StorageStatus } from '@/lib/api';
+import { cn, formatBytes } from '@/lib/utils';
+import { Database, Share2, TrendingUp, AlertTriangle } from 'lucide-react';
+
+// Describes when a storage is forecast to fill up.
+function fillLabel(storage: StorageStatus): string {
+  const forecast = storage.forecast;
+  if (!forecast) return 'Collecting history...';
+  if (forecast.days_until_full === null || !forecast.full_at) return 'Not filling up';
+  if (forecast.days_until_full < 1) return 'Full within a day';
+  return `Full in ~${Math.round(forecast.days_until_full)} days (${new Date(forecast.full_at).toLocaleDateString()})`;
+}
+
+// Lists the usage of every Proxmox storage, fullest first, with the date it
+// is forecast to fill up.
+export default function StorageCapacity() {
+  const [overview, setOverview] = useState<StorageOverview | null>(null);
+  const [loading, setLoading] = useState(true);
+
+  useEffect(() => {
+    const load = async () => {
+      try {
+        setOverview(await infrastructureAPI.getStorage());
+      } catch (error) {
+        console.error('Failed to load storage capacity:', error);
+      } finally {
+        setLoading(false);
+      }
+    };
+
+    load();
+    const interval = setInterval(load, 60000);
+    return () => clearInterval(interval);
+  }, []);
+
+  if (loading && !overview) return null;
+
+  const storages = overview?.storages ?? [];
+
+  return (
+    <div className="space-y-4">
+      <div className="flex flex-col gap-1">
+        <h3 className="text-lg font-bold text-slate-100">Storage Capacity</h3>
+        <p className="text-sm text-slate-400">Usage of every storage, with fill forecasts from the last {storages[0]?.forecast?.window_days ?? 7} days.</p>
+      </div>
+
+      {overview?.errors.map(err => (
+        <div key={err.server_id} className="flex items-center gap-2 rounded-xl border border-rose-500/20 bg-rose-500/10 px-4 py-2 text-xs text-rose-400">
+          <AlertTriangle size={14} />
+          <span className="font-bold">{err.server_name}:</span> {err.error}
+        </div>
+      ))}
+
+      {storages.length === 0 ? (
+        <div className="rounded-2xl border border-dashed border-slate-800 py-8 text-center text-sm text-slate-500">
+          No storages found.
+        </div>
+      ) : (
+        <div className="overflow-hidden rounded-2xl border border-slate-800 bg-slate-900/50">
+          {storages.map(storage => (
+            <div
+              key={`${storage.server_id}-${storage.node}-${storage.storage}`}
+              className="grid grid-cols-1 items-center gap-3 border-b border-slate-800 px-6 py-4 last:border-b-0 md:grid-cols-[1fr_2fr_1fr]"
+            >
+              <div className="flex items-center gap-3">
+                <Database size={18} className={storage.active ? "text-cyan-400" : "text-slate-600"} />
+                <div>
+                  <div className="font-bold text-slate-100">{storage.storage}</div>
+                  <div className="flex items-center gap-1.5 text-[10px] uppercase text-slate-500">
+                    <span>{storage.server_name}</span>
+                    <span className="text-slate-700">•</span>
+                    {storage.shared ? (
+                      <span className="flex items-center gap-1"><Share2 size={10} />Shared</span>
+                    ) : (
+                      <span>{storage.node}</span>
+                    )}
+                    <span className="text-slate-700">•</span>
+                    <span>{storage.type}</span>
+                  </div>
+                </div>
+              </div>
+
+              <div>
+                <div className="mb-1 flex items-center justify-between text-xs">
+                  <span className="text-slate-500">{formatBytes(storage.used)} / {formatBytes(storage.total)}</span>
+                  <span className="font-mono text-slate-100">{storage.usage_percent.toFixed(1)}%</span>
+                </div>
+                <div className="h-2 w-full overflow-hidden rounded-full bg-slate-800">
+                  <div
+                    className={cn(
+                      "h-full rounded-full transition-all duration-500",
+                      storage.usage_percent < 70 ? "bg-emerald-500" : storage.usage_percent < 90 ? "bg-amber-500" : "bg-rose-500"
+                    )}
+                    style={{ width: `${Math.min(storage.usage_percent, 100)}%` }}
+                  />
+                </div>
+              </div>
+
+              <div className="flex items-center gap-1.5 text-xs md:justify-end">
+                <TrendingUp size={14} className="text-slate-500" />
+                <span className={cn(
+                  storage.forecast?.days_until_full != null && storage.forecast.days_until_full < 14
+                    ? "text-rose-400"
+                    : "text-slate-400"
+                )}>
+                  {fillLabel(storage)}
+                </span>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,57 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { ProxmoxTask, TaskLogLine, followTaskLog } from '@/lib/api';
+import { cn } from '@/lib/utils';
+import { Loader2, CheckCircle2, XCircle, HelpCircle, X } from 'lucide-react';
+
+interface TaskLogProps {
+  taskId: number;
+  description: string;
+  onFinished?: (task: ProxmoxTask) => void;
+  onClose: () => void;
+}
+
+// Shows a Proxmox task's log as it runs and whether it succeeded.
+export default function TaskLog({ taskId, description, onFinished, onClose }: TaskLogProps) {
+  const [lines, setLines] = useState<TaskLogLine[]>([]);
+  const [task, setTask] = useState<ProxmoxTask | null>(null);
+
+  useEffect(() => {
+    setLines([]);
+    setTask(null);
+    return followTaskLog(
+      taskId,
+      line => setLines(prev => [...prev, line]),
+      finished => {
+        setTask(finished);
+        onFinished?.(finished);
+      }
+    );
+  }, [taskId]);
+
+  const status = task?.status ?? 'running';
+
+  return (
+    <div className="rounded-xl border border-slate-800 bg-slate-950/50">
+      <div className="flex items-center justify-between border-b border-slate-800 px-4 py-2">
+        <div className="flex items-center gap-2 text-xs">
+          {status === 'running' && <Loader2 size={14} className="animate-spin text-cyan-400" />}
+          {status === 'ok' && <CheckCircle2 size={14} className="text-emerald-400" />}
+          {status === 'failed' && <XCircle size={14} className="text-rose-400" />}
+          {status === 'unknown' && <HelpCircle size={14} className="text-amber-400" />}
+          <span className="font-bold text-slate-200">{description}</span>
+          {task && task.status !== 'ok' && (
+            <span className={cn(task.status === 'failed' ? "text-rose-400" : "text-amber-400")}>{task.exit_status}</span>
+          )}
+        </div>
+        <button onClick={onClose} className="text-slate-500 hover:text-slate-300">
+          <X size={14} />
+        </button>
+      </div>
+      <pre className="max-h-48 overflow-y-auto px-4 py-2 font-mono text-[11px] text-slate-400">
+        {lines.length > 0 ? lines.map(line => line.t).join('\n') : 'Waiting for output...'}
+      </pre>
+    </div>
+  );
+}
@@ -0,0 +1,307 @@
+'use client';
+
+import React, { useEffect, useState } from 'react';
+import { Server, VM, VMAction, VMConfig, serversAPI } from '@/lib/api';
+import { cn } from '@/lib/utils';
+import SnapshotManager from '@/components/SnapshotManager';
+import ShellTerminal from '@/components/ShellTerminal';
+import Modal from '@/components/Modal';
+import MetricHistory from '@/components/MetricHistory';
+import { Play, Square, Power, RotateCw, Pause, Trash2, RefreshCw, Monitor, Cpu, HardDrive, Settings, Camera, Network, Terminal, LineChart } from 'lucide-react';
+
+interface VMManagerProps {
+  server: Server;
+}
+
+export default function VMManager({ server }: VMManagerProps) {
+  const [vms, setVms] = useState<VM[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+  const [editing, setEditing] = useState<{ vmid: number; config: VMConfig } | null>(null);
+  const [resize, setResize] = useState<Record<string, string>>({});
+  const [snapshotting, setSnapshotting] = useState<number | null>(null);
+  const [consoleVmid, setConsoleVmid] = useState<number | null>(null);
+  const [historyVmid, setHistoryVmid] = useState<number | null>(null);
+
+  const fetchVMs = async () => {
+    setLoading(true);
+    try {
+      const data = await serversAPI.getVMs(server.id);
+      setVms(data || []);
+      setError(null);
+    } catch (err: any) {
+      console.error('Failed to fetch VMs:', err);
+      setError(err.message || 'Failed to connect to node');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    fetchVMs();
+  }, [server.id]);
+
+  const handleAction = async (vmid: number, action: VMAction) => {
+    try {
+      await serversAPI.manageVM(server.id, vmid, action);
+      await fetchVMs();
+    } catch (err: any) {
+      alert(`Action failed: ${err.message}`);
+    }
+  };
+
+  const handleDelete = async (vm: VM) => {
+    if (!confirm(`Destroy VM ${vm.vmid} (${vm.name}) and all of its disks?`)) return;
+    try {
+      await serversAPI.deleteVM(server.id, vm.vmid, true);
+      await fetchVMs();
+    } catch (err: any) {
+      alert(`Delete failed: ${err.message}`);
+    }
+  };
+
+  const openConfig = async (vmid: number) => {
+    try {
+      const config = await serversAPI.getVMConfig(server.id, vmid);
+      setEditing({ vmid, config });
+      setResize({});
+    } catch (err: any) {
+      alert(`Failed to load configuration: ${err.message}`);
+    }
+  };
+
+  const handleSaveConfig = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!editing) return;
+    const growBy = Object.fromEntries(
+      Object.entries(resize).filter(([, size]) => size.trim() !== '').map(([disk, size]) => [disk, `+${size.trim()}`])
+    );
+    try {
+      await serversAPI.updateVMConfig(server.id, editing.vmid, {
+        cores: editing.config.cores,
+        sockets: editing.config.sockets,
+        memory: editing.config.memory,
+        resize: growBy,
+        digest: editing.config.digest,
+      });
+      setEditing(null);
+      await fetchVMs();
+    } catch (err: any) {
+      alert(`Update failed: ${err.message}`);
+    }
+  };
+
+  const formatSize = (bytes: number) => {
+    const gb = bytes / (1024 * 1024 * 1024);
+    if (gb >= 1) return `${gb.toFixed(1)} GB`;
+    return `${(bytes / (1024 * 1024)).toFixed(0)} MB`;
+  };
+
+  const inputClasses = "w-full bg-slate-950 border border-slate-800 rounded-lg px-3 py-2 text-slate-200 text-sm focus:border-cyan-500/50 outline-none";
+
+  const actionButton = (vmid: number, action: VMAction, title: string, icon: React.ReactNode, hover: string) => (
+    <button
+      onClick={() => handleAction(vmid, action)}
+      className={cn("p-2 text-slate-400 rounded-lg transition-all", hover)}
+      title={title}
+    >
+      {icon}
+    </button>
+  );
+
+  return (
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <div className="flex items-center gap-2">
+          <Monitor className="text-cyan-400" size={20} />
+          <h3 className="font-bold text-slate-100 uppercase tracking-wider text-sm">Virtual Machine Management</h3>
+        </div>
+        <button
+          onClick={fetchVMs}
+          className="p-2 text-slate-400 hover:text-cyan-400 transition-colors"
+        >
+          <RefreshCw size={18} className={cn(loading && "animate-spin")} />
+        </button>
+      </div>
+
+      {error && (
+        <div className="rounded-xl border border-rose-500/20 bg-rose-500/5 p-3 text-xs text-rose-400">{error}</div>
+      )}
+
+      {editing && (
+        <form onSubmit={handleSaveConfig} className="bg-slate-900/50 border border-slate-800 rounded-xl p-6 space-y-4">
+          <h4 className="text-xs font-bold text-slate-400 uppercase tracking-widest">
+            Configuration // VM {editing.vmid}{editing.config.name ? ` (${editing.config.name})` : ''}
+          </h4>
+          <div className="grid grid-cols-3 gap-4">
+            <div className="space-y-1">
+              <label className="text-[10px] font-bold text-slate-500 uppercase">Sockets</label>
+              <input
+                type="number"
+                min={1}
+                className={inputClasses}
+                value={editing.config.sockets}
+                onChange={e => setEditing({ ...editing, config: { ...editing.config, sockets: parseInt(e.target.value) } })}
+              />
+            </div>
+            <div className="space-y-1">
+              <label className="text-[10px] font-bold text-slate-500 uppercase">Cores</label>
+              <input
+                type="number"
+                min={1}
+                className={inputClasses}
+                value={editing.config.cores}
+                onChange={e => setEditing({ ...editing, config: { ...editing.config, cores: parseInt(e.target.value) } })}
+              />
+            </div>
+            <div className="space-y-1">
+              <label className="text-[10px] font-bold text-slate-500 uppercase">Memory (MiB)</label>
+              <input
+                type="number"
+                min={16}
+                className={inputClasses}
+                value={editing.config.memory}
+                onChange={e => setEditing({ ...editing, config: { ...editing.config, memory: parseInt(e.target.value) } })}
+              />
+            </div>
+          </div>
+          {Object.keys(editing.config.disks).length > 0 && (
+            <div className="space-y-2">
+              <label className="text-[10px] font-bold text-slate-500 uppercase">Disks</label>
+              {Object.entries(editing.config.disks).sort().map(([disk, volume]) => (
+                <div key={disk} className="flex items-center gap-3">
+                  <span className="w-16 font-mono text-xs text-slate-400">{disk}</span>
+                  <span className="flex-1 truncate font-mono text-xs text-slate-500" title={volume}>{volume}</span>
+                  <input
+                    className={cn(inputClasses, "w-28")}
+                    placeholder="Grow by, e.g. 10G"
+                    value={resize[disk] || ''}
+                    onChange={e => setResize({ ...resize, [disk]: e.target.value })}
+                  />
+                </div>
+              ))}
+            </div>
+          )}
+          <div className="flex justify-end gap-3 pt-2">
+            <button type="button" onClick={() => setEditing(null)} className="text-xs text-slate-500 font-bold uppercase hover:text-slate-300">Cancel</button>
+            <button type="submit" className="px-4 py-2 bg-cyan-500 text-slate-950 rounded-lg text-xs font-bold uppercase hover:bg-cyan-400 transition-all">Apply Changes</button>
+          </div>
+        </form>
+      )}
+
+      {snapshotting !== null && (
+        <SnapshotManager server={server} guest="vms" vmid={snapshotting} onClose={() => setSnapshotting(null)} />
+      )}
+
+      {consoleVmid !== null && (
+        <ShellTerminal server={server} vmid={consoleVmid} onClose={() => setConsoleVmid(null)} />
+      )}
+
+      <Modal
+        isOpen={historyVmid !== null}
+        onClose={() => setHistoryVmid(null)}
+        title={`VM History // ${historyVmid ?? ''}`}
+        className="max-w-5xl"
+      >
+        {historyVmid !== null && <MetricHistory serverId={server.id} vmid={historyVmid} />}
+      </Modal>
+
+      <div className="grid grid-cols-1 gap-4">
+        {vms.map(vm => (
+          <div key={vm.vmid} className="bg-slate-900/30 border border-slate-800 rounded-xl p-4 flex items-center justify-between group hover:border-slate-700 transition-all">
+            <div className="flex items-center gap-4">
+              <div className={cn(
+                "h-10 w-10 rounded-lg flex items-center justify-center border",
+                vm.status === 'running' ? "border-emerald-500/20 bg-emerald-500/10 text-emerald-400" :
+                vm.status === 'paused' ? "border-amber-500/20 bg-amber-500/10 text-amber-400" :
+                "border-slate-700 bg-slate-800 text-slate-500"
+              )}>
+                <Monitor size={20} />
+              </div>
+              <div>
+                <div className="flex items-center gap-2">
+                  <span className="font-mono text-[10px] text-slate-500">[{vm.vmid}]</span>
+                  <h4 className="font-bold text-slate-100">{vm.name}</h4>
+                </div>
+                <div className="flex items-center gap-4 mt-1">
+                  <div className="flex items-center gap-1 text-[10px] text-slate-500">
+                    <Network size={10} /> {vm.node}
+                  </div>
+                  <div className="flex items-center gap-1 text-[10px] text-slate-500">
+                    <Cpu size={10} /> {vm.cpus} CORES
+                  </div>
+                  <div className="flex items-center gap-1 text-[10px] text-slate-500">
+                    <HardDrive size={10} /> {formatSize(vm.maxmem)} RAM
+                  </div>
+                </div>
+              </div>
+            </div>
+
+            <div className="flex items-center gap-1">
+              <span className={cn(
+                "text-[10px] font-bold uppercase tracking-widest mr-3",
+                vm.status === 'running' ? "text-emerald-400" : vm.status === 'paused' ? "text-amber-400" : "text-slate-500"
+              )}>{vm.status}</span>
+
+              {vm.status === 'stopped' && actionButton(vm.vmid, 'start', 'Start VM', <Play size={18} />, "hover:text-emerald-400 hover:bg-emerald-500/10")}
+              {vm.status === 'paused' && actionButton(vm.vmid, 'resume', 'Resume VM', <Play size={18} />, "hover:text-emerald-400 hover:bg-emerald-500/10")}
+              {vm.status === 'running' && (
+                <>
+                  {actionButton(vm.vmid, 'shutdown', 'Shut Down VM', <Power size={18} />, "hover:text-amber-400 hover:bg-amber-500/10")}
+                  {actionButton(vm.vmid, 'reboot', 'Reboot VM', <RotateCw size={18} />, "hover:text-cyan-400 hover:bg-cyan-500/10")}
+                  {actionButton(vm.vmid, 'suspend', 'Suspend VM', <Pause size={18} />, "hover:text-amber-400 hover:bg-amber-500/10")}
+                </>
+              )}
+              {vm.status !== 'stopped' && actionButton(vm.vmid, 'stop', 'Stop VM (hard)', <Square size={18} />, "hover:text-rose-400 hover:bg-rose-500/10")}
+
+              {vm.status === 'running' && (
+                <button
+                  onClick={() => setConsoleVmid(vm.vmid)}
+                  className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                  title="Serial Console"
+                >
+                  <Terminal size={18} />
+                </button>
+              )}
+              <button
+                onClick={() => setSnapshotting(vm.vmid)}
+                className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                title="Snapshots"
+              >
+                <Camera size={18} />
+              </button>
+              <button
+                onClick={() => setHistoryVmid(vm.vmid)}
+                className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                title="History"
+              >
+                <LineChart size={18} />
+              </button>
+              <button
+                onClick={() => openConfig(vm.vmid)}
+                className="p-2 text-slate-400 hover:text-cyan-400 hover:bg-cyan-500/10 rounded-lg transition-all"
+                title="Edit Configuration"
+              >
+                <Settings size={18} />
+              </button>
+              <button
+                onClick={() => handleDelete(vm)}
+                className="p-2 text-slate-600 hover:text-rose-500 hover:bg-rose-500/10 rounded-lg transition-all"
+                title="Delete VM"
+              >
+                <Trash2 size={18} />
+              </button>
+            </div>
+          </div>
+        ))}
+
+        {vms.length === 0 && !loading && !error && (
+          <div className="py-12 text-center border border-dashed border-slate-800 rounded-2xl">
+            <Monitor className="mx-auto text-slate-800 mb-3" size={32} />
+            <p className="text-slate-500 text-sm font-mono">NO_VIRTUAL_MACHINES_DETECTED</p>
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -12,6 +12,7 @@ export interface Server {
   port: number;
   username?: string;
   password?: string;
+  api_token?: string;
   description?: string;
   status: 'active' | 'inactive' | 'error';
   node?: string;
@@ -261,6 +262,7 @@ export interface ServerLogEntry {
 export interface LXC {
   vmid: number;
   name: string;
+  node: string;
   status: 'running' | 'stopped';
   uptime: number;
   cpus: number;
@@ -268,12 +270,343 @@ export interface LXC {
   maxmem: number;
 }
 
+export interface VM {
+  vmid: number;
+  name: string;
+  node: string;
+  status: 'running' | 'stopped' | 'paused';
+  uptime: number;
+  cpus: number;
+  mem: number;
+  maxmem: number;
+}
+
+// ClusterGuest is a VM or container on any node of a Proxmox cluster.
+export interface ClusterGuest {
+  id: string;
+  type: 'qemu' | 'lxc';
+  node: string;
+  vmid: number;
+  name: string;
+  status: string;
+  template?: number;
+  pool?: string;
+  hastate?: string;
+  cpu: number;
+  maxcpu: number;
+  mem: number;
+  maxmem: number;
+  disk: number;
+  maxdisk: number;
+  uptime: number;
+}
+
+export type VMAction = 'start' | 'shutdown' | 'stop' | 'reboot' | 'suspend' | 'resume';
+
+export interface VMConfig {
+  name?: string;
+  cores: number;
+  sockets: number;
+  cpu?: string;
+  memory: number;
+  balloon?: number;
+  disks: Record<string, string>;
+  digest: string;
+}
+
+export interface VMConfigUpdate {
+  cores?: number;
+  sockets?: number;
+  cpu?: string;
+  memory?: number;
+  balloon?: number;
+  disks?: Record<string, string>;
+  delete?: string[];
+  resize?: Record<string, string>;
+  digest?: string;
+}
+
+export type GuestType = 'vms' | 'lxcs';
+
+export interface Snapshot {
+  name: string;
+  description: string;
+  snaptime?: number;
+  parent?: string;
+  vmstate?: number;
+}
+
+export interface BackupStorage {
+  storage: string;
+  type: string;
+  content: string;
+  active: number;
+  total: number;
+  used: number;
+  avail: number;
+}
+
+export interface BackupVolume {
+  volid: string;
+  storage: string;
+  node: string;
+  vmid: number;
+  subtype?: 'qemu' | 'lxc';
+  format: string;
+  size: number;
+  ctime: number;
+  notes?: string;
+  protected?: number;
+}
+
+export interface BackupJob {
+  id: string;
+  schedule: string;
+  storage: string;
+  node?: string;
+  vmid?: string;
+  all?: number;
+  exclude?: string;
+  pool?: string;
+  mode?: string;
+  enabled?: number;
+  comment?: string;
+  'next-run'?: number;
+}
+
+export interface GuestBackupStatus {
+  server_id: number;
+  server_name: string;
+  node: string;
+  vmid: number;
+  name: string;
+  type: 'qemu' | 'lxc';
+  last_backup: string | null;
+  age_days: number | null;
+}
+
+export interface BackupCoverageReport {
+  generated_at: string;
+  max_age_days: number;
+  total_guests: number;
+  covered_count: number;
+  uncovered: GuestBackupStatus[];
+  errors: { server_id: number; server_name: string; error: string }[];
+}
+
+// StorageForecast extrapolates a storage's growth over the last window_days.
+// full_at is null when usage is flat, shrinking or not filling within years.
+export interface StorageForecast {
+  window_days: number;
+  samples: number;
+  growth_per_day: number;
+  full_at: string | null;
+  days_until_full: number | null;
+}
+
+// StorageStatus is a storage's current usage. Shared storages have an empty
+// node.
+export interface StorageStatus {
+  server_id: number;
+  server_name: string;
+  node: string;
+  storage: string;
+  type: string;
+  content: string;
+  shared: boolean;
+  active: boolean;
+  used: number;
+  total: number;
+  avail: number;
+  usage_percent: number;
+  forecast: StorageForecast | null;
+}
+
+export interface StorageOverview {
+  generated_at: string;
+  storages: StorageStatus[];
+  errors: { server_id: number; server_name: string; error: string }[];
+}
+
+export interface StorageSample {
+  used: number;
+  total: number;
+  sampled_at: string;
+}
+
+export interface StorageHistory {
+  samples: StorageSample[];
+  forecast: StorageForecast | null;
+}
+
+export interface InventoryNode {
+  id: number;
+  server_id: number;
+  server_name: string;
+  node: string;
+  status: string;
+  cpu: number;
+  maxcpu: number;
+  mem: number;
+  maxmem: number;
+  disk: number;
+  maxdisk: number;
+  uptime: number;
+  config_hash: string;
+  first_seen: string;
+  last_seen: string;
+  removed_at: string | null;
+}
+
+export interface InventoryGuest {
+  id: number;
+  server_id: number;
+  server_name: string;
+  vmid: number;
+  type: 'qemu' | 'lxc';
+  name: string;
+  node: string;
+  status: string;
+  template: boolean;
+  maxcpu: number;
+  maxmem: number;
+  maxdisk: number;
+  pool: string;
+  tags: string;
+  config_hash: string;
+  first_seen: string;
+  last_seen: string;
+  deleted_at: string | null;
+}
+
+export type InventoryEventType =
+  | 'node_added'
+  | 'node_removed'
+  | 'node_resized'
+  | 'guest_created'
+  | 'guest_deleted'
+  | 'guest_resized'
+  | 'guest_migrated'
+  | 'guest_renamed'
+  | 'guest_reconfigured';
+
+// A change noticed between two inventory syncs; occurred_at is when it was
+// noticed.
+export interface InventoryEvent {
+  id: number;
+  server_id: number;
+  server_name: string;
+  event: InventoryEventType;
+  node: string;
+  guest_type: string;
+  vmid: number;
+  name: string;
+  old_value: string;
+  new_value: string;
+  occurred_at: string;
+}
+
+export type MetricTimeframe = 'hour' | 'day' | 'week';
+
+// Averages over a series' step. cpu is the used fraction of maxcpu cores;
+// network and disk IO are bytes per second. Nodes have iowait and loadavg but
+// no disk IO.
+export interface MetricSample {
+  sampled_at: string;
+  cpu: number | null;
+  maxcpu: number | null;
+  mem: number | null;
+  maxmem: number | null;
+  netin: number | null;
+  netout: number | null;
+  diskread: number | null;
+  diskwrite: number | null;
+  iowait: number | null;
+  loadavg: number | null;
+}
+
+export interface MetricSeries {
+  server_id: number;
+  node: string;
+  vmid: number;
+  timeframe: MetricTimeframe;
+  step: number;
+  from: string;
+  to: string;
+  samples: MetricSample[];
+}
+
+export type TaskStatus = 'running' | 'ok' | 'failed' | 'unknown';
+
+export interface ProxmoxTask {
+  id: number;
+  server_id: number;
+  server_name: string;
+  upid: string;
+  node: string;
+  type: string;
+  vmid: number | null;
+  description: string;
+  user_id: number | null;
+  username?: string;
+  status: TaskStatus;
+  exit_status: string;
+  started_at: string;
+  finished_at: string | null;
+}
+
+export interface TaskLogLine {
+  n: number;
+  t: string;
+}
+
+// TaskStarted is the response of actions that start a Proxmox task. task_id
+// is null when the task could not be tracked.
+export interface TaskStarted {
+  message: string;
+  upid: string;
+  task_id: number | null;
+}
+
+// TerminalTicket opens one node shell or guest console with openTerminal; it
+// expires after a few seconds.
+export interface TerminalTicket {
+  ticket: string;
+  node: string;
+  expires_at: string;
+}
+
+// StreamTicket opens one event stream or task log stream; it expires after a
+// few seconds.
+export interface StreamTicket {
+  ticket: string;
+  expires_at: string;
+}
+
+// TerminalSession is a recorded node shell or guest console. Its recording is
+// an asciicast v2 file; ended_at is null while the session is open.
+export interface TerminalSession {
+  id: number;
+  server_id: number | null;
+  server_name: string;
+  user_id: number | null;
+  username: string;
+  node: string;
+  guest_type: '' | 'qemu' | 'lxc';
+  vmid?: number;
+  client_ip: string;
+  size: number;
+  duration: number;
+  started_at: string;
+  ended_at: string | null;
+}
+
 export interface AlertRule {
   id: number;
   name: string;
   type: 'monitor' | 'infrastructure';
   target_id: number;
-  condition_type: 'status_down' | 'cpu_high' | 'memory_high' | 'latency_high' | 'uptime_low';
+  condition_type: 'status_down' | 'cpu_high' | 'memory_high' | 'latency_high' | 'uptime_low' | 'storage_high';
   threshold: number;
   comparison: '>' | '>=' | '<' | '<=' | '=';
   enabled: boolean;
@@ -513,6 +846,9 @@ export const authAPI = {
     return fetchAPI('/auth/me');
   },
 
+  createStreamTicket: (): Promise<StreamTicket> =>
+    fetchAPI('/auth/stream-ticket', { method: 'POST' }),
+
   changePassword: async (oldPassword: string, newPassword: string): Promise<void> => {
     await fetchAPI('/auth/change-password', {
       method: 'POST',
@@ -550,14 +886,111 @@ export const serversAPI = {
     fetchAPI(`/servers/${id}`, { method: 'DELETE' }),
   testConnection: (id: number): Promise<{ connected: boolean; status?: string; error?: string }> =>
     fetchAPI(`/servers/${id}/test`, { method: 'POST' }),
+  createShellTicket: (id: number, node?: string): Promise<TerminalTicket> =>
+    fetchAPI(`/servers/${id}/shell/ticket${node ? `?node=${encodeURIComponent(node)}` : ''}`, { method: 'POST' }),
+  createConsoleTicket: (id: number, vmid: number): Promise<TerminalTicket> =>
+    fetchAPI(`/servers/${id}/guests/${vmid}/console/ticket`, { method: 'POST' }),
   getLogs: (id: number): Promise<ServerLogEntry[]> =>
     fetchAPI(`/servers/${id}/logs`),
   getLXCs: (id: number): Promise<LXC[]> =>
     fetchAPI(`/servers/${id}/lxcs`),
-  createLXC: (id: number, data: { vmid: number; ostemplate: string; storage: string; params?: Record<string, string> }): Promise<{ message: string }> =>
+  getGuests: (id: number, filters?: { type?: 'qemu' | 'lxc'; node?: string }): Promise<ClusterGuest[]> => {
+    const params = new URLSearchParams();
+    if (filters?.type) params.append('type', filters.type);
+    if (filters?.node) params.append('node', filters.node);
+    return fetchAPI(`/servers/${id}/guests${params.toString() ? `?${params}` : ''}`);
+  },
+  createLXC: (id: number, data: { vmid: number; ostemplate: string; storage: string; node?: string; params?: Record<string, string> }): Promise<TaskStarted & { node: string }> =>
     fetchAPI(`/servers/${id}/lxcs`, { method: 'POST', body: JSON.stringify(data) }),
-  manageLXC: (id: number, vmid: number, action: 'start' | 'stop' | 'remove'): Promise<{ message: string }> =>
+  manageLXC: (id: number, vmid: number, action: 'start' | 'stop' | 'remove'): Promise<TaskStarted> =>
     fetchAPI(`/servers/${id}/lxcs/${vmid}/${action}`, { method: 'POST' }),
+  getVMs: (id: number): Promise<VM[]> =>
+    fetchAPI(`/servers/${id}/vms`),
+  manageVM: (id: number, vmid: number, action: VMAction): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/vms/${vmid}/${action}`, { method: 'POST' }),
+  deleteVM: (id: number, vmid: number, purge = false): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/vms/${vmid}${purge ? '?purge=true' : ''}`, { method: 'DELETE' }),
+  getVMConfig: (id: number, vmid: number): Promise<VMConfig> =>
+    fetchAPI(`/servers/${id}/vms/${vmid}/config`),
+  updateVMConfig: (id: number, vmid: number, data: VMConfigUpdate): Promise<{ message: string; upids: string[] }> =>
+    fetchAPI(`/servers/${id}/vms/${vmid}/config`, { method: 'PUT', body: JSON.stringify(data) }),
+  getSnapshots: (id: number, guest: GuestType, vmid: number): Promise<Snapshot[]> =>
+    fetchAPI(`/servers/${id}/${guest}/${vmid}/snapshots`),
+  createSnapshot: (id: number, guest: GuestType, vmid: number, data: { name: string; description?: string; vmstate?: boolean }): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/${guest}/${vmid}/snapshots`, { method: 'POST', body: JSON.stringify(data) }),
+  updateSnapshot: (id: number, guest: GuestType, vmid: number, name: string, description: string): Promise<{ message: string }> =>
+    fetchAPI(`/servers/${id}/${guest}/${vmid}/snapshots/${name}`, { method: 'PUT', body: JSON.stringify({ description }) }),
+  rollbackSnapshot: (id: number, guest: GuestType, vmid: number, name: string): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/${guest}/${vmid}/snapshots/${name}/rollback`, { method: 'POST' }),
+  deleteSnapshot: (id: number, guest: GuestType, vmid: number, name: string): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/${guest}/${vmid}/snapshots/${name}`, { method: 'DELETE' }),
+  getBackups: (id: number, filters?: { vmid?: number; storage?: string }): Promise<BackupVolume[]> => {
+    const params = new URLSearchParams();
+    if (filters?.vmid) params.append('vmid', filters.vmid.toString());
+    if (filters?.storage) params.append('storage', filters.storage);
+    return fetchAPI(`/servers/${id}/backups${params.toString() ? `?${params}` : ''}`);
+  },
+  getBackupStorages: (id: number, node?: string): Promise<BackupStorage[]> =>
+    fetchAPI(`/servers/${id}/backups/storages${node ? `?node=${encodeURIComponent(node)}` : ''}`),
+  createBackup: (id: number, data: { vmid: number; storage: string; mode?: 'snapshot' | 'suspend' | 'stop'; notes?: string }): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/backups`, { method: 'POST', body: JSON.stringify(data) }),
+  restoreBackup: (id: number, data: { volid: string; vmid: number; type?: 'qemu' | 'lxc'; storage?: string; node?: string; force?: boolean }): Promise<TaskStarted> =>
+    fetchAPI(`/servers/${id}/backups/restore`, { method: 'POST', body: JSON.stringify(data) }),
+  getBackupJobs: (id: number): Promise<BackupJob[]> =>
+    fetchAPI(`/servers/${id}/backup-jobs`),
+};
+
+export const backupsAPI = {
+  getReport: (days?: number): Promise<BackupCoverageReport> =>
+    fetchAPI(`/backups/report${days ? `?days=${days}` : ''}`),
+  attachEvidence: (data: { compliance_id: number; days?: number; set_status?: boolean }): Promise<BackupCoverageReport> =>
+    fetchAPI('/backups/report/evidence', { method: 'POST', body: JSON.stringify(data) }),
+};
+
+export const tasksAPI = {
+  getAll: (filters?: { server_id?: number; vmid?: number; status?: TaskStatus; mine?: boolean; limit?: number }): Promise<ProxmoxTask[]> => {
+    const params = new URLSearchParams();
+    if (filters?.server_id) params.append('server_id', filters.server_id.toString());
+    if (filters?.vmid) params.append('vmid', filters.vmid.toString());
+    if (filters?.status) params.append('status', filters.status);
+    if (filters?.mine) params.append('mine', 'true');
+    if (filters?.limit) params.append('limit', filters.limit.toString());
+    return fetchAPI(`/tasks${params.toString() ? `?${params}` : ''}`);
+  },
+  getById: (id: number): Promise<ProxmoxTask> => fetchAPI(`/tasks/${id}`),
+  getLog: (id: number, start = 0): Promise<TaskLogLine[]> =>
+    fetchAPI(`/tasks/${id}/log${start ? `?start=${start}` : ''}`),
+};
+
+export const terminalSessionsAPI = {
+  getAll: (filters?: { server_id?: number; user_id?: number; vmid?: number; limit?: number }): Promise<TerminalSession[]> => {
+    const params = new URLSearchParams();
+    if (filters?.server_id) params.append('server_id', filters.server_id.toString());
+    if (filters?.user_id) params.append('user_id', filters.user_id.toString());
+    if (filters?.vmid) params.append('vmid', filters.vmid.toString());
+    if (filters?.limit) params.append('limit', filters.limit.toString());
+    return fetchAPI(`/terminal-sessions${params.toString() ? `?${params}` : ''}`);
+  },
+  getById: (id: number): Promise<TerminalSession> => fetchAPI(`/terminal-sessions/${id}`),
+  // Fetches the asciicast v2 recording as text.
+  getRecording: async (id: number): Promise<string> => {
+    const token = getToken();
+    const response = await fetch(`${API_BASE_URL}/terminal-sessions/${id}/recording`,
+      token ? { headers: { 'Authorization': `Bearer ${token}` } } : {});
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ error: 'Failed to fetch recording' }));
+      throw new Error(error.error || 'Failed to fetch recording');
+    }
+    return response.text();
+  },
+  downloadRecording: (id: number): void => {
+    terminalSessionsAPI.getRecording(id).then(text => {
+      const a = document.createElement('a');
+      a.href = URL.createObjectURL(new Blob([text], { type: 'application/x-asciicast' }));
+      a.download = `terminal-session-${id}.cast`;
+      a.click();
+    });
+  },
 };
 
 export const licensesAPI = {
@@ -621,6 +1054,62 @@ export const monitorsAPI = {
 
 export const infrastructureAPI = {
   getNodes: (): Promise<InfrastructureNode[]> => fetchAPI('/infrastructure/nodes'),
+  getStorage: (serverId?: number, days?: number): Promise<StorageOverview> => {
+    const params = new URLSearchParams();
+    if (serverId) params.append('server_id', String(serverId));
+    if (days) params.append('days', String(days));
+    return fetchAPI(`/infrastructure/storage${params.toString() ? `?${params}` : ''}`);
+  },
+  getStorageHistory: (serverId: number, storage: string, node = '', days?: number): Promise<StorageHistory> => {
+    const params = new URLSearchParams({ server_id: String(serverId), storage, node });
+    if (days) params.append('days', String(days));
+    return fetchAPI(`/infrastructure/storage/history?${params}`);
+  },
+  // Returns the history of a node, or of the guest vmid, between from and to
+  // (default the last 24 hours).
+  getMetrics: (
+    serverId: number,
+    target: { node?: string; vmid?: number },
+    range?: { from?: string; to?: string; timeframe?: MetricTimeframe }
+  ): Promise<MetricSeries> => {
+    const params = new URLSearchParams({ server_id: String(serverId) });
+    if (target.vmid) params.append('vmid', String(target.vmid));
+    else if (target.node) params.append('node', target.node);
+    if (range?.from) params.append('from', range.from);
+    if (range?.to) params.append('to', range.to);
+    if (range?.timeframe) params.append('timeframe', range.timeframe);
+    return fetchAPI(`/infrastructure/metrics?${params}`);
+  },
+  getInventoryNodes: (filters?: { server_id?: number; include_gone?: boolean }): Promise<InventoryNode[]> => {
+    const params = new URLSearchParams();
+    if (filters?.server_id) params.append('server_id', String(filters.server_id));
+    if (filters?.include_gone) params.append('include_gone', 'true');
+    return fetchAPI(`/infrastructure/inventory/nodes${params.toString() ? `?${params}` : ''}`);
+  },
+  getInventoryGuests: (filters?: { server_id?: number; node?: string; include_gone?: boolean }): Promise<InventoryGuest[]> => {
+    const params = new URLSearchParams();
+    if (filters?.server_id) params.append('server_id', String(filters.server_id));
+    if (filters?.node) params.append('node', filters.node);
+    if (filters?.include_gone) params.append('include_gone', 'true');
+    return fetchAPI(`/infrastructure/inventory/guests${params.toString() ? `?${params}` : ''}`);
+  },
+  getInventoryEvents: (filters?: {
+    server_id?: number;
+    node?: string;
+    vmid?: number;
+    event?: InventoryEventType;
+    since?: string;
+    limit?: number;
+  }): Promise<InventoryEvent[]> => {
+    const params = new URLSearchParams();
+    if (filters?.server_id) params.append('server_id', String(filters.server_id));
+    if (filters?.node) params.append('node', filters.node);
+    if (filters?.vmid) params.append('vmid', String(filters.vmid));
+    if (filters?.event) params.append('event', filters.event);
+    if (filters?.since) params.append('since', filters.since);
+    if (filters?.limit) params.append('limit', String(filters.limit));
+    return fetchAPI(`/infrastructure/inventory/events${params.toString() ? `?${params}` : ''}`);
+  },
 };
 
 export const usersAPI = {
@@ -683,3 +1172,117 @@ export const licenseUsersAPI = {
 export const auditLogsAPI = {
   getAll: (): Promise<AuditLog[]> => fetchAPI('/audit-logs'),
 };
+
+export type StreamEventType =
+  | 'alert.created'
+  | 'alert.acknowledged'
+  | 'alert.resolved'
+  | 'monitor.status_changed'
+  | 'server.status_changed'
+  | 'task.started'
+  | 'task.finished'
+  | 'inventory.changed';
+
+export interface StreamEvent<T = unknown> {
+  type: StreamEventType;
+  data: T;
+  time: string;
+}
+
+// How long subscribeEvents waits before reconnecting a dropped stream.
+const EVENT_STREAM_RETRY_MS = 5000;
+
+// Opens the server-sent event stream and calls onEvent for each event of the
+// given types. EventSource cannot send headers, so each connection is opened
+// with a fresh single-use stream ticket, and a dropped stream is reopened with
+// a new one. Returns a function that closes the stream.
+export function subscribeEvents(types: StreamEventType[], onEvent: (event: StreamEvent) => void): () => void {
+  if (typeof window === 'undefined' || !getToken()) return () => {};
+
+  let source: EventSource | null = null;
+  let retry: ReturnType<typeof setTimeout> | undefined;
+  let closed = false;
+
+  const listener = (e: MessageEvent) => {
+    try {
+      onEvent(JSON.parse(e.data));
+    } catch (error) {
+      console.error('Failed to parse event:', error);
+    }
+  };
+
+  const reconnect = () => {
+    if (!closed) retry = setTimeout(connect, EVENT_STREAM_RETRY_MS);
+  };
+
+  const connect = async () => {
+    let ticket: StreamTicket;
+    try {
+      ticket = await authAPI.createStreamTicket();
+    } catch (error) {
+      console.error('Failed to open event stream:', error);
+      reconnect();
+      return;
+    }
+    if (closed) return;
+
+    const params = new URLSearchParams({ ticket: ticket.ticket, types: types.join(',') });
+    source = new EventSource(`${API_BASE_URL}/events?${params}`);
+    types.forEach(type => source?.addEventListener(type, listener as EventListener));
+    // The browser would retry with the same, already used ticket.
+    source.onerror = () => {
+      source?.close();
+      source = null;
+      reconnect();
+    };
+  };
+
+  connect();
+  return () => {
+    closed = true;
+    clearTimeout(retry);
+    source?.close();
+  };
+}
+
+// Follows a task's log as it is written, calling onLine for each line and
+// onEnd with the finished task. The stream is opened with a single-use stream
+// ticket, as for subscribeEvents. Returns a function that stops following.
+export function followTaskLog(id: number, onLine: (line: TaskLogLine) => void, onEnd: (task: ProxmoxTask) => void): () => void {
+  if (typeof window === 'undefined' || !getToken()) return () => {};
+
+  let source: EventSource | null = null;
+  let closed = false;
+
+  authAPI.createStreamTicket().then(ticket => {
+    if (closed) return;
+
+    const params = new URLSearchParams({ ticket: ticket.ticket });
+    source = new EventSource(`${API_BASE_URL}/tasks/${id}/log?${params}`);
+    source.addEventListener('line', (e: MessageEvent) => onLine(JSON.parse(e.data)));
+    source.addEventListener('end', (e: MessageEvent) => {
+      source?.close();
+      onEnd(JSON.parse(e.data));
+    });
+    // The stream restarts from the first line on reconnect, and the ticket is
+    // already used, so it is not reopened after an error.
+    source.onerror = () => source?.close();
+  }).catch(error => console.error('Failed to follow task log:', error));
+
+  return () => {
+    closed = true;
+    source?.close();
+  };
+}
+
+// Opens the WebSocket of a server's node shell, or of a guest's console when
+// vmid is given. The socket relays the Proxmox xterm.js protocol: "OK" once
+// connected, then terminal output.
+export function openTerminal(serverId: number, ticket: TerminalTicket, vmid?: number): WebSocket {
+  const base = API_BASE_URL.replace(/^http/, 'ws');
+  const params = new URLSearchParams({ ticket: ticket.ticket });
+  const path = vmid ? `guests/${vmid}/console` : 'shell';
+  const socket = new WebSocket(`${base}/servers/${serverId}/${path}?${params}`);
+  socket.binaryType = 'arraybuffer';
+  return socket;
+}